@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"fmt"
+	"log"
 	"net/http"
 )
 
@@ -12,6 +13,7 @@ func New(deps Dependencies) *App {
 	return &App{
 		Router: router,
 		Server: newServer(router),
+		deps:   deps,
 	}
 }
 
@@ -19,6 +21,7 @@ func New(deps Dependencies) *App {
 type App struct {
 	Router http.Handler
 	Server *http.Server
+	deps   Dependencies
 }
 
 // Start launches the http web application on the provided port
@@ -31,7 +34,10 @@ func (a *App) Start(port int) error {
 	return http.Serve(listener, a.Router)
 }
 
-// Stop stops the application
+// Stop stops the application and releases the resources held by its dependencies.
 func (a *App) Stop(ctx context.Context) {
 	a.Server.Shutdown(ctx)
+	if err := a.deps.Close(); err != nil {
+		log.Printf("error releasing dependencies: %v\n", err)
+	}
 }
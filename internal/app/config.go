@@ -0,0 +1,290 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"transaction-service/internal/forex"
+)
+
+const (
+	// MemoryStorage selects the transaction.InMemoryRepository.
+	MemoryStorage = "memory"
+
+	// FileStorage selects the transaction.FileRepository.
+	FileStorage = "file"
+
+	// SQLStorage selects the transaction.SQLRepository.
+	SQLStorage = "sql"
+
+	// UUIDIDFormat selects the transaction.UUIDGenerator.
+	UUIDIDFormat = "uuid"
+
+	// UUIDv7IDFormat selects the transaction.UUIDv7Generator.
+	UUIDv7IDFormat = "uuidv7"
+
+	// ULIDIDFormat selects the transaction.ULIDGenerator.
+	ULIDIDFormat = "ulid"
+
+	// APISource selects exchange rates looked up through the Treasury API, by way of the forex.CachingRepository.
+	APISource = "api"
+
+	// TableSource selects exchange rates looked up in the forex.TableRepository.
+	TableSource = "table"
+
+	// FileSource selects exchange rates looked up in a forex.TableRepository loaded from a forex.FileDataset.
+	FileSource = "file"
+)
+
+// NewConfig returns a Config populated with the default settings.  These are suitable for local development and
+// integration testing.
+func NewConfig() Config {
+	return Config{
+		Port:                          8080,
+		TxnStorage:                    MemoryStorage,
+		TxnFileDir:                    "data",
+		TxnSnapshotInterval:           1000,
+		TxnSQLDriver:                  "sqlite",
+		TxnSQLDSN:                     "transactions.db",
+		TxnIDFormat:                   UUIDIDFormat,
+		IdempotencyWindow:             24 * time.Hour,
+		ForExCacheTTL:                 time.Hour,
+		ForExCacheNegativeTTL:         5 * time.Minute,
+		ForExCacheSize:                1000,
+		ForExSource:                   APISource,
+		ForExTableRefreshInterval:     6 * time.Hour,
+		ForExFilePath:                 "rates_of_exchange.csv",
+		ForExFilePollInterval:         time.Minute,
+		ForExRetryMaxAttempts:         3,
+		ForExRetryBaseDelay:           200 * time.Millisecond,
+		ForExRetryMaxDelay:            2 * time.Second,
+		ForExRetryMaxRetryAfter:       5 * time.Second,
+		ForExBreakerThreshold:         5,
+		ForExBreakerOpenDuration:      30 * time.Second,
+		ForExStaleIfError:             true,
+		ForExCountriesRefreshInterval: 24 * time.Hour,
+		ForExRatePolicy:               string(forex.DefaultRateRules.Policy),
+		ForExRateWindowMonths:         forex.DefaultRateRules.WindowMonths,
+		ForExRoundingMode:             string(forex.DefaultRoundingMode),
+	}
+}
+
+// LoadConfig returns the default Config overridden by any settings supplied through environment variables, or an
+// error if one of the supplied settings is not valid.
+func LoadConfig() (Config, error) {
+	config := NewConfig()
+	var err error
+	if config.Port, err = envInt("PORT", config.Port); err != nil {
+		return Config{}, err
+	}
+	config.TxnStorage = envString("TXN_STORAGE", config.TxnStorage)
+	config.TxnFileDir = envString("TXN_FILE_DIR", config.TxnFileDir)
+	if config.TxnSnapshotInterval, err = envInt("TXN_SNAPSHOT_INTERVAL", config.TxnSnapshotInterval); err != nil {
+		return Config{}, err
+	}
+	config.TxnSQLDriver = envString("TXN_SQL_DRIVER", config.TxnSQLDriver)
+	config.TxnSQLDSN = envString("TXN_SQL_DSN", config.TxnSQLDSN)
+	config.TxnIDFormat = envString("TXN_ID_FORMAT", config.TxnIDFormat)
+	config.TxnIDPrefix = envString("TXN_ID_PREFIX", config.TxnIDPrefix)
+	if config.IdempotencyWindow, err = envDuration("IDEMPOTENCY_WINDOW", config.IdempotencyWindow); err != nil {
+		return Config{}, err
+	}
+	if config.ForExCacheTTL, err = envDuration("FOREX_CACHE_TTL", config.ForExCacheTTL); err != nil {
+		return Config{}, err
+	}
+	if config.ForExCacheNegativeTTL, err = envDuration("FOREX_CACHE_NEGATIVE_TTL", config.ForExCacheNegativeTTL); err != nil {
+		return Config{}, err
+	}
+	if config.ForExCacheSize, err = envInt("FOREX_CACHE_SIZE", config.ForExCacheSize); err != nil {
+		return Config{}, err
+	}
+	config.ForExSource = envString("FOREX_SOURCE", config.ForExSource)
+	config.ForExTableRefreshInterval, err = envDuration("FOREX_TABLE_REFRESH_INTERVAL", config.ForExTableRefreshInterval)
+	if err != nil {
+		return Config{}, err
+	}
+	config.ForExFilePath = envString("FOREX_FILE_PATH", config.ForExFilePath)
+	if config.ForExFilePollInterval, err = envDuration("FOREX_FILE_POLL_INTERVAL", config.ForExFilePollInterval); err != nil {
+		return Config{}, err
+	}
+	if config.ForExRetryMaxAttempts, err = envInt("FOREX_RETRY_MAX_ATTEMPTS", config.ForExRetryMaxAttempts); err != nil {
+		return Config{}, err
+	}
+	if config.ForExRetryBaseDelay, err = envDuration("FOREX_RETRY_BASE_DELAY", config.ForExRetryBaseDelay); err != nil {
+		return Config{}, err
+	}
+	if config.ForExRetryMaxDelay, err = envDuration("FOREX_RETRY_MAX_DELAY", config.ForExRetryMaxDelay); err != nil {
+		return Config{}, err
+	}
+	config.ForExRetryMaxRetryAfter, err = envDuration("FOREX_RETRY_MAX_RETRY_AFTER", config.ForExRetryMaxRetryAfter)
+	if err != nil {
+		return Config{}, err
+	}
+	if config.ForExBreakerThreshold, err = envInt("FOREX_BREAKER_FAILURE_THRESHOLD", config.ForExBreakerThreshold); err != nil {
+		return Config{}, err
+	}
+	config.ForExBreakerOpenDuration, err = envDuration("FOREX_BREAKER_OPEN_DURATION", config.ForExBreakerOpenDuration)
+	if err != nil {
+		return Config{}, err
+	}
+	if config.ForExStaleIfError, err = envBool("FOREX_STALE_IF_ERROR", config.ForExStaleIfError); err != nil {
+		return Config{}, err
+	}
+	config.ForExCountriesRefreshInterval, err = envDuration("FOREX_COUNTRIES_REFRESH_INTERVAL",
+		config.ForExCountriesRefreshInterval)
+	if err != nil {
+		return Config{}, err
+	}
+	config.ForExRatePolicy = envString("FOREX_RATE_POLICY", config.ForExRatePolicy)
+	if config.ForExRateWindowMonths, err = envInt("FOREX_RATE_WINDOW_MONTHS", config.ForExRateWindowMonths); err != nil {
+		return Config{}, err
+	}
+	config.ForExRoundingMode = envString("FOREX_ROUNDING_MODE", config.ForExRoundingMode)
+	return config, nil
+}
+
+// Config holds the settings used to wire up the application.
+type Config struct {
+	// Port is the port on which the http server listens.
+	Port int
+
+	// TxnStorage selects the transaction.Repository implementation.  One of MemoryStorage, FileStorage or SQLStorage.
+	TxnStorage string
+
+	// TxnFileDir is the directory in which the transaction.FileRepository and transaction.FileIdempotencyStore keep
+	// their logs and snapshots.
+	TxnFileDir string
+
+	// TxnSnapshotInterval is the number of writes to the log of the transaction.FileRepository, and of the
+	// transaction.FileIdempotencyStore, between snapshots.
+	TxnSnapshotInterval int
+
+	// TxnSQLDriver is the name of the database/sql driver used by the transaction.SQLRepository.
+	TxnSQLDriver string
+
+	// TxnSQLDSN is the data source name used by the transaction.SQLRepository to connect to its database.
+	TxnSQLDSN string
+
+	// TxnIDFormat selects the transaction.IDGenerator used to generate the ids of stored transactions.  One of
+	// UUIDIDFormat, UUIDv7IDFormat or ULIDIDFormat.
+	TxnIDFormat string
+
+	// TxnIDPrefix, when not empty, is prefixed to the id of every stored transaction, e.g. 'txn_'.
+	TxnIDPrefix string
+
+	// IdempotencyWindow is how long an idempotency key supplied when storing a transaction is remembered for.
+	IdempotencyWindow time.Duration
+
+	// ForExCacheTTL is how long an exchange rate record found through the Treasury API is cached for.
+	ForExCacheTTL time.Duration
+
+	// ForExCacheNegativeTTL is how long the absence of an exchange rate record is cached for.
+	ForExCacheNegativeTTL time.Duration
+
+	// ForExCacheSize is the number of exchange rate lookups whose results are cached, counting the lookups of the newest
+	// record and of the records of a period separately.  Zero disables the cache.
+	ForExCacheSize int
+
+	// ForExSource selects where exchange rates are looked up.  One of APISource, TableSource or FileSource.
+	ForExSource string
+
+	// ForExTableRefreshInterval is how often the forex.TableRepository is refreshed from the Treasury API.
+	ForExTableRefreshInterval time.Duration
+
+	// ForExFilePath is the path of the downloaded dataset (.csv or .json) from which exchange rates are looked up when
+	// the ForExSource is FileSource.
+	ForExFilePath string
+
+	// ForExFilePollInterval is how often the file at ForExFilePath is checked for changes.
+	ForExFilePollInterval time.Duration
+
+	// ForExRetryMaxAttempts is the number of times a call to the Treasury API is attempted, including the first.
+	ForExRetryMaxAttempts int
+
+	// ForExRetryBaseDelay is the delay before retrying a failed call to the Treasury API, doubling for each retry.
+	ForExRetryBaseDelay time.Duration
+
+	// ForExRetryMaxDelay is the longest delay between retries of a call to the Treasury API.
+	ForExRetryMaxDelay time.Duration
+
+	// ForExRetryMaxRetryAfter is the longest delay asked for by the Treasury API in a Retry-After header that is
+	// honoured.
+	ForExRetryMaxRetryAfter time.Duration
+
+	// ForExBreakerThreshold is the number of consecutive failed calls to the Treasury API after which the circuit
+	// breaker opens.
+	ForExBreakerThreshold int
+
+	// ForExBreakerOpenDuration is how long the circuit breaker stays open before a trial call to the Treasury API is
+	// made.
+	ForExBreakerOpenDuration time.Duration
+
+	// ForExStaleIfError, when true, has the last exchange rate known for a country used should looking it up through
+	// the Treasury API fail, as long as it is recent enough for the transaction.
+	ForExStaleIfError bool
+
+	// ForExCountriesRefreshInterval is how often the list of countries known to the Treasury dataset is refreshed.
+	ForExCountriesRefreshInterval time.Duration
+
+	// ForExRatePolicy selects the forex.RatePolicy by which the exchange rate used for a conversion is selected, unless
+	// a request overrides it.
+	ForExRatePolicy string
+
+	// ForExRateWindowMonths is how many months from the date of a conversion its exchange rate may be dated, unless a
+	// request overrides it.
+	ForExRateWindowMonths int
+
+	// ForExRoundingMode selects the forex.RoundingMode by which converted amounts are rounded to whole cents.
+	ForExRoundingMode string
+}
+
+// envString returns the value of the named environment variable, or the fallback if it is not set.
+func envString(name, fallback string) string {
+	if value, ok := os.LookupEnv(name); ok {
+		return value
+	}
+	return fallback
+}
+
+// envInt returns the value of the named environment variable as an int, or the fallback if it is not set.
+func envInt(name string, fallback int) (int, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return fallback, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("environment variable %s must be an integer: %w", name, err)
+	}
+	return parsed, nil
+}
+
+// envBool returns the value of the named environment variable as a bool (e.g. "true" or "false"), or the fallback if it
+// is not set.
+func envBool(name string, fallback bool) (bool, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return fallback, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("environment variable %s must be a bool: %w", name, err)
+	}
+	return parsed, nil
+}
+
+// envDuration returns the value of the named environment variable as a time.Duration (e.g. "90s" or "24h"), or the
+// fallback if it is not set.
+func envDuration(name string, fallback time.Duration) (time.Duration, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return fallback, nil
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("environment variable %s must be a duration: %w", name, err)
+	}
+	return parsed, nil
+}
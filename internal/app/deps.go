@@ -1,22 +1,266 @@
 package app
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+
+	_ "modernc.org/sqlite"
+
 	"transaction-service/internal/forex"
+	"transaction-service/internal/health"
 	"transaction-service/internal/transaction"
 )
 
 // NewDependencies wires up the application's dependencies using the dependency injection pattern
-func NewDependencies(txnIDGenerator transaction.IDGenerator, httpClient forex.HttpClient) Dependencies {
-	txnRepository := transaction.NewInMemoryRepository(txnIDGenerator)
-	forExRepository := forex.NewTreasuryRepository(httpClient)
-	forExService := forex.NewRepositoryService(forExRepository)
-	txnService := transaction.NewRepositoryService(txnRepository, forExService)
-	return Dependencies{
-		TxnService: txnService,
+func NewDependencies(config Config, txnIDGenerator transaction.IDGenerator, httpClient forex.HttpClient) (Dependencies, error) {
+	txnRepository, idempotencyStore, closers, err := newTxnRepository(config, txnIDGenerator)
+	if err != nil {
+		return Dependencies{}, err
+	}
+	if config.ForExRetryMaxAttempts < 1 {
+		Dependencies{closers: closers}.Close()
+		return Dependencies{}, fmt.Errorf("treasury api retry attempts must be at least 1, got %d", config.ForExRetryMaxAttempts)
+	}
+	if config.ForExBreakerThreshold < 1 {
+		Dependencies{closers: closers}.Close()
+		return Dependencies{}, fmt.Errorf("treasury api circuit breaker failure threshold must be at least 1, got %d",
+			config.ForExBreakerThreshold)
+	}
+	if config.ForExBreakerOpenDuration <= 0 {
+		Dependencies{closers: closers}.Close()
+		return Dependencies{}, fmt.Errorf("treasury api circuit breaker open duration must be positive, got %s",
+			config.ForExBreakerOpenDuration)
+	}
+	treasuryClient := forex.NewResilientHttpClient(httpClient, forex.RetryPolicy{
+		MaxAttempts:   config.ForExRetryMaxAttempts,
+		BaseDelay:     config.ForExRetryBaseDelay,
+		MaxDelay:      config.ForExRetryMaxDelay,
+		MaxRetryAfter: config.ForExRetryMaxRetryAfter,
+	}, forex.BreakerConfig{
+		FailureThreshold: config.ForExBreakerThreshold,
+		OpenDuration:     config.ForExBreakerOpenDuration,
+	})
+	forExRepos, closer, err := newForExRepositories(config, treasuryClient)
+	if err != nil {
+		Dependencies{closers: closers}.Close()
+		return Dependencies{}, err
+	}
+	if closer != nil {
+		closers = append(closers, closer)
+	}
+	if config.ForExCountriesRefreshInterval <= 0 {
+		Dependencies{closers: closers}.Close()
+		return Dependencies{}, fmt.Errorf("country directory refresh interval must be positive, got %s",
+			config.ForExCountriesRefreshInterval)
+	}
+	countries := forex.NewCountryDirectory(forExRepos.dataset)
+	countries.Start(config.ForExCountriesRefreshInterval)
+	closers = append(closers, countries)
+	rateRules, err := newRateRules(config)
+	if err != nil {
+		Dependencies{closers: closers}.Close()
+		return Dependencies{}, err
+	}
+	roundingMode, err := newRoundingMode(config)
+	if err != nil {
+		Dependencies{closers: closers}.Close()
+		return Dependencies{}, err
 	}
+	forExService := forex.NewRepositoryService(forExRepos.lookup, forExRepos.history, countries, rateRules, roundingMode)
+	txnService := transaction.NewRepositoryService(txnRepository, idempotencyStore, forExService)
+	return Dependencies{
+		TxnService:          txnService,
+		ForExHistoryService: forex.NewHistoryService(forExRepos.history),
+		ForExQuoteService:   forex.NewQuoteService(forExService),
+		ForExCountries:      countries,
+		ForExCache:          forExRepos.cache,
+		HealthCheckers: map[string]health.Checker{
+			"treasuryApi": treasuryClient,
+		},
+		closers: closers,
+	}, nil
 }
 
 // Dependencies holds the top level dependencies required for wiring to handlers.
 type Dependencies struct {
 	TxnService *transaction.RepositoryService
+
+	// ForExHistoryService looks up the exchange rates of a period.
+	ForExHistoryService *forex.HistoryService
+
+	// ForExQuoteService converts amounts without storing a transaction.
+	ForExQuoteService *forex.QuoteService
+
+	// ForExCountries lists the countries known to the Treasury dataset.
+	ForExCountries *forex.CountryDirectory
+
+	// ForExCache is the cache of exchange rate records, or nil if caching is disabled.
+	ForExCache *forex.CachingRepository
+
+	// HealthCheckers report the health of each component on which the service depends, by name.
+	HealthCheckers map[string]health.Checker
+	closers        []io.Closer
+}
+
+// Close releases any resources held by the dependencies, such as open files.
+func (d Dependencies) Close() error {
+	var errs []error
+	for _, closer := range d.closers {
+		if err := closer.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NewTxnIDGenerator creates the transaction.IDGenerator selected by the supplied Config, or returns an error if the
+// Config does not select a known one.
+func NewTxnIDGenerator(config Config) (transaction.IDGenerator, error) {
+	var generator transaction.IDGenerator
+	switch config.TxnIDFormat {
+	case UUIDIDFormat:
+		generator = transaction.NewUUIDGenerator()
+	case UUIDv7IDFormat:
+		generator = transaction.NewUUIDv7Generator()
+	case ULIDIDFormat:
+		generator = transaction.NewULIDGenerator()
+	default:
+		return nil, fmt.Errorf("unknown transaction id format: %q", config.TxnIDFormat)
+	}
+	if config.TxnIDPrefix != "" {
+		generator = transaction.NewPrefixedGenerator(config.TxnIDPrefix, generator)
+	}
+	return generator, nil
+}
+
+// newRateRules returns the default forex.RateRules set by the supplied Config, or returns an error if they are not
+// valid.
+func newRateRules(config Config) (forex.RateRules, error) {
+	policy := forex.RatePolicy(config.ForExRatePolicy)
+	known := false
+	for _, supported := range forex.RatePolicies() {
+		known = known || string(policy) == supported
+	}
+	if !known {
+		return forex.RateRules{}, fmt.Errorf("unknown exchange rate policy: %q", config.ForExRatePolicy)
+	}
+	if config.ForExRateWindowMonths < forex.MinRateWindowMonths || config.ForExRateWindowMonths > forex.MaxRateWindowMonths {
+		return forex.RateRules{}, fmt.Errorf("exchange rate window must be between %d and %d months, got %d",
+			forex.MinRateWindowMonths, forex.MaxRateWindowMonths, config.ForExRateWindowMonths)
+	}
+	return forex.RateRules{Policy: policy, WindowMonths: config.ForExRateWindowMonths}, nil
+}
+
+// newRoundingMode returns the forex.RoundingMode set by the supplied Config, or returns an error if it is not
+// supported.
+func newRoundingMode(config Config) (forex.RoundingMode, error) {
+	for _, supported := range forex.RoundingModes() {
+		if config.ForExRoundingMode == supported {
+			return forex.RoundingMode(supported), nil
+		}
+	}
+	return "", fmt.Errorf("unknown rounding mode: %q", config.ForExRoundingMode)
+}
+
+// forExRepositories holds the repositories through which exchange rates are looked up.
+type forExRepositories struct {
+	// lookup finds the exchange rate used to convert a transaction.
+	lookup forex.Repository
+
+	// history finds the exchange rates of a period.
+	history forex.HistoryRepository
+
+	// cache is the cache through which lookups are made, or nil if there is none.
+	cache *forex.CachingRepository
+
+	// dataset is the source of every exchange rate record.
+	dataset forex.Dataset
+}
+
+// newForExRepositories creates the forex repositories selected by the supplied Config, along with the io.Closer (if
+// any) that stops their background work.
+func newForExRepositories(config Config, httpClient forex.HttpClient) (forExRepositories, io.Closer, error) {
+	treasuryRepository := forex.NewTreasuryRepository(httpClient)
+	switch config.ForExSource {
+	case APISource:
+		var repository forex.RateRepository = forex.NewCoalescingRepository(treasuryRepository)
+		if config.ForExStaleIfError {
+			repository = forex.NewStaleIfErrorRepository(repository)
+		}
+		if config.ForExCacheSize <= 0 {
+			return forExRepositories{lookup: repository, history: repository, dataset: treasuryRepository}, nil, nil
+		}
+		cache := forex.NewCachingRepository(repository, forex.CacheConfig{
+			TTL:         config.ForExCacheTTL,
+			NegativeTTL: config.ForExCacheNegativeTTL,
+			MaxEntries:  config.ForExCacheSize,
+		})
+		return forExRepositories{
+			lookup:  cache,
+			history: cache,
+			cache:   cache,
+			dataset: treasuryRepository,
+		}, nil, nil
+	case TableSource:
+		if config.ForExTableRefreshInterval <= 0 {
+			return forExRepositories{}, nil, fmt.Errorf("exchange rate table refresh interval must be positive, got %s",
+				config.ForExTableRefreshInterval)
+		}
+		table := forex.NewTableRepositoryWithFallback(treasuryRepository, treasuryRepository)
+		table.Start(config.ForExTableRefreshInterval)
+		return forExRepositories{lookup: table, history: table, dataset: treasuryRepository}, table, nil
+	case FileSource:
+		if config.ForExFilePollInterval <= 0 {
+			return forExRepositories{}, nil, fmt.Errorf("exchange rate file poll interval must be positive, got %s",
+				config.ForExFilePollInterval)
+		}
+		dataset := forex.NewFileDataset(config.ForExFilePath)
+		table := forex.NewTableRepository(dataset)
+		if err := table.Refresh(context.Background()); err != nil {
+			return forExRepositories{}, nil, fmt.Errorf("loading exchange rate file: %w", err)
+		}
+		table.Start(config.ForExFilePollInterval)
+		return forExRepositories{lookup: table, history: table, dataset: dataset}, table, nil
+	default:
+		return forExRepositories{}, nil, fmt.Errorf("unknown exchange rate source: %q", config.ForExSource)
+	}
+}
+
+// newTxnRepository creates the transaction.Repository selected by the supplied Config, along with the
+// transaction.IdempotencyStore kept beside it, and the io.Closers (if any) that release the resources they hold.
+func newTxnRepository(config Config, txnIDGenerator transaction.IDGenerator) (transaction.Repository,
+	transaction.IdempotencyStore, []io.Closer, error) {
+	switch config.TxnStorage {
+	case MemoryStorage:
+		return transaction.NewInMemoryRepository(txnIDGenerator),
+			transaction.NewInMemoryIdempotencyStore(config.IdempotencyWindow), nil, nil
+	case FileStorage:
+		repository, err := transaction.NewFileRepository(config.TxnFileDir, config.TxnSnapshotInterval, txnIDGenerator)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		store, err := transaction.NewFileIdempotencyStore(config.TxnFileDir, config.IdempotencyWindow,
+			config.TxnSnapshotInterval)
+		if err != nil {
+			repository.Close()
+			return nil, nil, nil, err
+		}
+		return repository, store, []io.Closer{repository, store}, nil
+	case SQLStorage:
+		db, err := sql.Open(config.TxnSQLDriver, config.TxnSQLDSN)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		repository, err := transaction.NewSQLRepository(db, txnIDGenerator)
+		if err != nil {
+			db.Close()
+			return nil, nil, nil, err
+		}
+		return repository, transaction.NewSQLIdempotencyStore(repository, config.IdempotencyWindow), []io.Closer{db}, nil
+	default:
+		return nil, nil, nil, fmt.Errorf("unknown transaction storage: %q", config.TxnStorage)
+	}
 }
@@ -4,6 +4,8 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"transaction-service/internal/errorhandling"
+	"transaction-service/internal/forex"
+	"transaction-service/internal/health"
 	"transaction-service/internal/transaction"
 )
 
@@ -13,6 +15,18 @@ func newRouter(deps Dependencies) *gin.Engine {
 	router := gin.Default()
 	router.Use(errorhandling.NewMiddleware)
 	transaction.ConfigureStoreHandler(router, deps.TxnService)
+	transaction.ConfigureBatchStoreHandler(router, deps.TxnService)
 	transaction.ConfigureFetchHandler(router, deps.TxnService)
+	transaction.ConfigureListHandler(router, deps.TxnService)
+	transaction.ConfigureExportHandler(router, deps.TxnService)
+	transaction.ConfigureUpdateHandlers(router, deps.TxnService)
+	transaction.ConfigureVoidHandlers(router, deps.TxnService)
+	forex.ConfigureHistoryHandler(router, deps.ForExHistoryService)
+	forex.ConfigureCountriesHandler(router, deps.ForExCountries)
+	forex.ConfigureQuoteHandler(router, deps.ForExQuoteService)
+	if deps.ForExCache != nil {
+		forex.ConfigureCacheHandlers(router, deps.ForExCache)
+	}
+	health.ConfigureHandler(router, deps.HealthCheckers)
 	return router
 }
@@ -26,10 +26,34 @@ func (e *FieldError) Error() string {
 	return fmt.Sprintf("[field '%s', Reason: %s]", e.FieldName, e.Reason)
 }
 
+// Kind classifies a business error so that it can be reported to the caller appropriately.
+type Kind int
+
+const (
+	// Unprocessable is the default Kind, used when the request cannot be processed as supplied.
+	Unprocessable Kind = iota
+
+	// Conflict is used when the request conflicts with the current state of the system.
+	Conflict
+
+	// PreconditionFailed is used when a condition supplied with the request, such as an expected version, does not hold.
+	PreconditionFailed
+
+	// PreconditionRequired is used when the request must be made conditional, but no condition was supplied.
+	PreconditionRequired
+
+	// NotAcceptable is used when the response cannot be produced in any of the representations the caller accepts.
+	NotAcceptable
+)
+
 // Error represents a top level business error, with a collection of field errors and a message.
 type Error struct {
 	Fields  []FieldError `json:"fields,omitempty"`
 	Message string       `json:"message"`
+	Kind    Kind         `json:"-"`
+
+	// Suggestions holds values the user may have meant in place of one that could not be matched, closest first.
+	Suggestions []string `json:"suggestions,omitempty"`
 }
 
 // Error implements the error interface on business.Error
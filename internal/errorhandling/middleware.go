@@ -24,10 +24,10 @@ type ErrorResponse struct {
 
 // NewMiddleware is middleware for gin that provides top level error handling.  It is responsible for making sure the
 // http response and status are appropriate for the error(s) that occurred.  Principally it distinguishes between
-// business and system errors, with business errors resulting in a 422 http status and system errors resulting in a 500
-// http status.  System errors return a static error message, with details logged on the server side so that internal
-// details are not exposed to the caller.  Additionally, a request payload that is not well-formed will result in a 400
-// http status.
+// business and system errors, with business errors resulting in a 422 http status (or 406, 409, 412 or 428 depending
+// on their business.Kind) and system errors resulting in a 500 http status.  System errors return a static error
+// message, with details logged on the server side so that internal details are not exposed to the caller.
+// Additionally, a request payload that is not well-formed will result in a 400 http status.
 func NewMiddleware(ctx *gin.Context) {
 	ctx.Next()
 	for _, err := range ctx.Errors {
@@ -54,7 +54,23 @@ func handleBadRequest(ctx *gin.Context) {
 }
 
 func handleBusinessError(ctx *gin.Context, businessError *business.Error) {
-	ctx.JSON(http.StatusUnprocessableEntity, businessError)
+	ctx.JSON(businessStatus(businessError.Kind), businessError)
+}
+
+// businessStatus returns the http status used to report a business error of the supplied kind.
+func businessStatus(kind business.Kind) int {
+	switch kind {
+	case business.Conflict:
+		return http.StatusConflict
+	case business.PreconditionFailed:
+		return http.StatusPreconditionFailed
+	case business.PreconditionRequired:
+		return http.StatusPreconditionRequired
+	case business.NotAcceptable:
+		return http.StatusNotAcceptable
+	default:
+		return http.StatusUnprocessableEntity
+	}
 }
 
 func handleSystemError(ctx *gin.Context) {
@@ -0,0 +1,157 @@
+package forex
+
+import (
+	"context"
+	"time"
+)
+
+// CacheConfig holds the settings of a CachingRepository.
+type CacheConfig struct {
+	// TTL is how long a found exchange rate record is cached for.
+	TTL time.Duration
+
+	// NegativeTTL is how long the absence of an exchange rate record is cached for.
+	NegativeTTL time.Duration
+
+	// MaxEntries is the number of entries beyond which the least recently used entry is evicted.
+	MaxEntries int
+}
+
+// CacheStats holds counts of the lookups made through a CachingRepository since it was created.
+type CacheStats struct {
+	// Hits is the number of lookups answered from the cache, including NegativeHits.
+	Hits uint64 `json:"hits"`
+
+	// NegativeHits is the number of lookups answered from the cache with the absence of a record.
+	NegativeHits uint64 `json:"negativeHits"`
+
+	// Misses is the number of lookups passed on to the wrapped Repository.
+	Misses uint64 `json:"misses"`
+
+	// Evictions is the number of entries evicted to keep within the size bound.
+	Evictions uint64 `json:"evictions"`
+
+	// Entries is the number of entries currently cached, some of which may have expired.
+	Entries int `json:"entries"`
+}
+
+// add returns the sum of the counts of the supplied CacheStats and other.
+func (s CacheStats) add(other CacheStats) CacheStats {
+	return CacheStats{
+		Hits:         s.Hits + other.Hits,
+		NegativeHits: s.NegativeHits + other.NegativeHits,
+		Misses:       s.Misses + other.Misses,
+		Evictions:    s.Evictions + other.Evictions,
+		Entries:      s.Entries + other.Entries,
+	}
+}
+
+// NewCachingRepository creates a CachingRepository that caches the records found by the supplied repository according
+// to the supplied CacheConfig.
+func NewCachingRepository(repository RateRepository, config CacheConfig) *CachingRepository {
+	return &CachingRepository{
+		repository: repository,
+		config:     config,
+		now:        time.Now,
+		records:    newLRUCache[lookupKey](config.MaxEntries, func(record Record) bool { return record == (Record{}) }),
+		periods:    newLRUCache[periodKey](config.MaxEntries, func(records []Record) bool { return len(records) == 0 }),
+	}
+}
+
+// CachingRepository is a RateRepository that caches the exchange rate records found by another RateRepository, such as
+// the TreasuryRepository, so that repeated lookups for the same country and date window do not each result in a call
+// to the Treasury API.  The newest record of a country and the records of a period are cached separately, each up to
+// the configured number of entries.  The absence of a record is cached as well, for a separately configured time,
+// while errors are never cached.  Once the cache is full, the least recently used entry is evicted to make room for a
+// new one.
+type CachingRepository struct {
+	repository RateRepository
+	config     CacheConfig
+	now        func() time.Time
+	records    *lruCache[lookupKey, Record]
+	periods    *lruCache[periodKey, []Record]
+}
+
+// lookupKey identifies a lookup of the newest exchange rate record of a country that is not older than a date.
+type lookupKey struct {
+	country string
+	oldest  string
+}
+
+// newLookupKey returns the lookupKey of the lookup for the supplied country and date of oldest record.
+func newLookupKey(country string, dateOfOldestRecord time.Time) lookupKey {
+	return lookupKey{country: country, oldest: dateOfOldestRecord.Format(dateFormat)}
+}
+
+// periodKey identifies a lookup of the exchange rate records of a country recorded over a period.
+type periodKey struct {
+	country string
+	from    string
+	to      string
+}
+
+// newPeriodKey returns the periodKey of the lookup for the supplied country and period.
+func newPeriodKey(country string, from, to time.Time) periodKey {
+	return periodKey{country: country, from: from.Format(dateFormat), to: to.Format(dateFormat)}
+}
+
+// FindByCountry returns the cached record for the specified country and dateOfOldestRecord if there is one that has
+// not expired, otherwise it finds the record using the wrapped RateRepository and caches it.  A record returned in
+// place of a failed lookup (see StaleIfErrorRepository) is not cached, so that the lookup is tried again next time.
+func (r *CachingRepository) FindByCountry(ctx context.Context, country string, dateOfOldestRecord time.Time) (Record, error) {
+	key := newLookupKey(country, dateOfOldestRecord)
+	if record, _, ok := r.records.get(key, r.now()); ok {
+		return record, nil
+	}
+	record, err := r.repository.FindByCountry(ctx, country, dateOfOldestRecord)
+	if err != nil {
+		return Record{}, err
+	}
+	if record.Fallback == nil {
+		r.records.put(key, record, r.now(), r.ttl(record == (Record{})))
+	}
+	return record, nil
+}
+
+// FindByCountryBetween returns the cached records of the specified country recorded on or between the specified dates
+// if they have not expired, otherwise it finds them using the wrapped RateRepository and caches them.  As with
+// FindByCountry, records returned in place of a failed lookup are not cached.
+func (r *CachingRepository) FindByCountryBetween(ctx context.Context, country string, from, to time.Time) ([]Record, error) {
+	key := newPeriodKey(country, from, to)
+	if records, _, ok := r.periods.get(key, r.now()); ok {
+		return records, nil
+	}
+	records, err := r.repository.FindByCountryBetween(ctx, country, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 || records[0].Fallback == nil {
+		r.periods.put(key, records, r.now(), r.ttl(len(records) == 0))
+	}
+	return records, nil
+}
+
+// ttl returns how long a result is cached for, depending on whether it records the absence of a record.
+func (r *CachingRepository) ttl(empty bool) time.Duration {
+	if empty {
+		return r.config.NegativeTTL
+	}
+	return r.config.TTL
+}
+
+// Invalidate discards every cached entry for the supplied country, so that the next lookup for it is passed on to the
+// wrapped RateRepository, and returns the number of entries discarded.
+func (r *CachingRepository) Invalidate(country string) int {
+	return r.records.removeWhere(func(key lookupKey) bool { return key.country == country }) +
+		r.periods.removeWhere(func(key periodKey) bool { return key.country == country })
+}
+
+// InvalidateAll discards every cached entry and returns the number of entries discarded.
+func (r *CachingRepository) InvalidateAll() int {
+	return r.records.clear() + r.periods.clear()
+}
+
+// Stats returns counts of the lookups made since the CachingRepository was created.
+func (r *CachingRepository) Stats() CacheStats {
+	return r.records.snapshot().add(r.periods.snapshot())
+}
@@ -0,0 +1,236 @@
+package forex
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"transaction-service/internal/date"
+)
+
+func TestCachingRepository(t *testing.T) {
+	ctx := context.Background()
+	oldest := date.NewInUTC(2023, time.February, 10)
+	ukRecord := Record{
+		RecordDate:   RecordDate{Time: date.NewInUTC(2023, time.March, 31)},
+		ExchangeRate: ExchangeRate{Value: "0.812"},
+	}
+	now := date.NewInUTC(2023, time.May, 1)
+	setUp := func(maxEntries int) (*CachingRepository, *countingRepository) {
+		upstream := &countingRepository{records: map[string]Record{"United Kingdom": ukRecord}}
+		cache := NewCachingRepository(upstream, CacheConfig{
+			TTL:         time.Hour,
+			NegativeTTL: time.Minute,
+			MaxEntries:  maxEntries,
+		})
+		cache.now = func() time.Time { return now }
+		return cache, upstream
+	}
+
+	t.Run("should find a record once and then answer from the cache", func(t *testing.T) {
+		cache, upstream := setUp(10)
+
+		for n := 0; n < 3; n++ {
+			record, err := cache.FindByCountry(ctx, "United Kingdom", oldest)
+			assert.Nil(t, err)
+			assert.Equal(t, ukRecord, record)
+		}
+		assert.Equal(t, 1, upstream.calls)
+		assert.Equal(t, CacheStats{Hits: 2, Misses: 1, Entries: 1}, cache.Stats())
+	})
+	t.Run("should cache records separately for each date window", func(t *testing.T) {
+		cache, upstream := setUp(10)
+
+		cache.FindByCountry(ctx, "United Kingdom", oldest)
+		cache.FindByCountry(ctx, "United Kingdom", oldest.AddDate(0, 0, 1))
+		assert.Equal(t, 2, upstream.calls)
+	})
+	t.Run("should find the record again once it has expired", func(t *testing.T) {
+		cache, upstream := setUp(10)
+		start := now
+		defer func() { now = start }()
+
+		cache.FindByCountry(ctx, "United Kingdom", oldest)
+		now = now.Add(time.Hour)
+		cache.FindByCountry(ctx, "United Kingdom", oldest)
+		assert.Equal(t, 2, upstream.calls)
+	})
+	t.Run("should cache the absence of a record for the negative ttl", func(t *testing.T) {
+		cache, upstream := setUp(10)
+		start := now
+		defer func() { now = start }()
+
+		record, err := cache.FindByCountry(ctx, "Atlantis", oldest)
+		assert.Nil(t, err)
+		assert.Equal(t, Record{}, record)
+		cache.FindByCountry(ctx, "Atlantis", oldest)
+		assert.Equal(t, 1, upstream.calls)
+		assert.Equal(t, CacheStats{Hits: 1, NegativeHits: 1, Misses: 1, Entries: 1}, cache.Stats())
+
+		now = now.Add(time.Minute)
+		cache.FindByCountry(ctx, "Atlantis", oldest)
+		assert.Equal(t, 2, upstream.calls)
+	})
+	t.Run("should not cache errors", func(t *testing.T) {
+		cache, upstream := setUp(10)
+		upstream.err = errors.New("problem")
+
+		_, err := cache.FindByCountry(ctx, "United Kingdom", oldest)
+		assert.EqualError(t, err, "problem")
+		upstream.err = nil
+		record, err := cache.FindByCountry(ctx, "United Kingdom", oldest)
+		assert.Nil(t, err)
+		assert.Equal(t, ukRecord, record)
+		assert.Equal(t, 2, upstream.calls)
+	})
+	t.Run("should not cache a record returned in place of a failed lookup", func(t *testing.T) {
+		cache, upstream := setUp(10)
+		fallback := ukRecord
+		fallback.Fallback = &Fallback{FetchedAt: now.Add(-time.Hour), Age: time.Hour}
+		upstream.records["United Kingdom"] = fallback
+
+		record, err := cache.FindByCountry(ctx, "United Kingdom", oldest)
+		assert.Nil(t, err)
+		assert.Equal(t, fallback, record)
+		cache.FindByCountry(ctx, "United Kingdom", oldest)
+		assert.Equal(t, 2, upstream.calls)
+		assert.Equal(t, 0, cache.Stats().Entries)
+	})
+	t.Run("should evict the least recently used entry once full", func(t *testing.T) {
+		cache, upstream := setUp(2)
+
+		cache.FindByCountry(ctx, "United Kingdom", oldest)
+		cache.FindByCountry(ctx, "Atlantis", oldest)
+		cache.FindByCountry(ctx, "United Kingdom", oldest)
+		cache.FindByCountry(ctx, "Narnia", oldest)
+		assert.Equal(t, 3, upstream.calls)
+
+		cache.FindByCountry(ctx, "United Kingdom", oldest)
+		assert.Equal(t, 3, upstream.calls)
+		cache.FindByCountry(ctx, "Atlantis", oldest)
+		assert.Equal(t, 4, upstream.calls)
+		assert.Equal(t, uint64(2), cache.Stats().Evictions)
+		assert.Equal(t, 2, cache.Stats().Entries)
+	})
+	t.Run("should find the records of an invalidated country again", func(t *testing.T) {
+		cache, upstream := setUp(10)
+		cache.FindByCountry(ctx, "United Kingdom", oldest)
+		cache.FindByCountry(ctx, "United Kingdom", oldest.AddDate(0, 0, 1))
+		cache.FindByCountry(ctx, "Atlantis", oldest)
+
+		assert.Equal(t, 2, cache.Invalidate("United Kingdom"))
+		cache.FindByCountry(ctx, "United Kingdom", oldest)
+		cache.FindByCountry(ctx, "Atlantis", oldest)
+		assert.Equal(t, 4, upstream.calls)
+
+		assert.Equal(t, 2, cache.InvalidateAll())
+		assert.Equal(t, 0, cache.Stats().Entries)
+	})
+	t.Run("should find the records of a period once and then answer from the cache", func(t *testing.T) {
+		cache, upstream := setUp(10)
+		to := oldest.AddDate(0, 6, 0)
+
+		for n := 0; n < 3; n++ {
+			records, err := cache.FindByCountryBetween(ctx, "United Kingdom", oldest, to)
+			assert.Nil(t, err)
+			assert.Equal(t, []Record{ukRecord}, records)
+		}
+		cache.FindByCountryBetween(ctx, "United Kingdom", oldest, to.AddDate(0, 0, 1))
+		assert.Equal(t, 2, upstream.calls)
+		assert.Equal(t, CacheStats{Hits: 2, Misses: 2, Entries: 2}, cache.Stats())
+	})
+	t.Run("should cache the absence of records of a period for the negative ttl", func(t *testing.T) {
+		cache, upstream := setUp(10)
+		to := oldest.AddDate(0, 6, 0)
+		start := now
+		defer func() { now = start }()
+
+		records, err := cache.FindByCountryBetween(ctx, "Atlantis", oldest, to)
+		assert.Nil(t, err)
+		assert.Empty(t, records)
+		cache.FindByCountryBetween(ctx, "Atlantis", oldest, to)
+		assert.Equal(t, 1, upstream.calls)
+		assert.Equal(t, CacheStats{Hits: 1, NegativeHits: 1, Misses: 1, Entries: 1}, cache.Stats())
+
+		now = now.Add(time.Minute)
+		cache.FindByCountryBetween(ctx, "Atlantis", oldest, to)
+		assert.Equal(t, 2, upstream.calls)
+	})
+	t.Run("should not cache the records of a period returned in place of a failed lookup", func(t *testing.T) {
+		cache, upstream := setUp(10)
+		fallback := ukRecord
+		fallback.Fallback = &Fallback{FetchedAt: now.Add(-time.Hour), Age: time.Hour}
+		upstream.records["United Kingdom"] = fallback
+		to := oldest.AddDate(0, 6, 0)
+
+		records, err := cache.FindByCountryBetween(ctx, "United Kingdom", oldest, to)
+		assert.Nil(t, err)
+		assert.Equal(t, []Record{fallback}, records)
+		cache.FindByCountryBetween(ctx, "United Kingdom", oldest, to)
+		assert.Equal(t, 2, upstream.calls)
+		assert.Equal(t, 0, cache.Stats().Entries)
+	})
+	t.Run("should invalidate both the records and the periods of a country", func(t *testing.T) {
+		cache, upstream := setUp(10)
+		cache.FindByCountry(ctx, "United Kingdom", oldest)
+		cache.FindByCountryBetween(ctx, "United Kingdom", oldest, oldest.AddDate(0, 6, 0))
+		cache.FindByCountryBetween(ctx, "Atlantis", oldest, oldest.AddDate(0, 6, 0))
+
+		assert.Equal(t, 2, cache.Invalidate("United Kingdom"))
+		cache.FindByCountryBetween(ctx, "United Kingdom", oldest, oldest.AddDate(0, 6, 0))
+		assert.Equal(t, 4, upstream.calls)
+		assert.Equal(t, 2, cache.InvalidateAll())
+	})
+	t.Run("should look up the window of a policy once for repeated conversions", func(t *testing.T) {
+		cache, upstream := setUp(10)
+		service := NewRepositoryService(nil, cache, unloadedCountries{}, RateRules{}, "")
+		selection := RateSelection{
+			Date:  date.NewInUTC(2023, time.April, 15),
+			Rules: RateRules{Policy: LatestOnOrBeforePolicy, WindowMonths: 6},
+		}
+
+		for n := 0; n < 2; n++ {
+			result, err := service.Convert(ctx, "United Kingdom", selection, 1000)
+			assert.Nil(t, err)
+			assert.Equal(t, 812, result.Amount)
+		}
+		assert.Equal(t, 1, upstream.calls)
+	})
+}
+
+// unloadedCountries is a CountryMatcher whose countries have not been loaded, so that countries are looked up as given.
+type unloadedCountries struct{}
+
+func (unloadedCountries) Match(string) (CountryMatch, bool) {
+	return CountryMatch{}, false
+}
+
+// countingRepository is a Repository and HistoryRepository that finds records in a map, counting the number of times
+// it is called.
+type countingRepository struct {
+	records map[string]Record
+	err     error
+	calls   int
+}
+
+func (r *countingRepository) FindByCountry(_ context.Context, country string, _ time.Time) (Record, error) {
+	r.calls++
+	if r.err != nil {
+		return Record{}, r.err
+	}
+	return r.records[country], nil
+}
+
+func (r *countingRepository) FindByCountryBetween(_ context.Context, country string, _, _ time.Time) ([]Record, error) {
+	r.calls++
+	if r.err != nil {
+		return nil, r.err
+	}
+	if record, ok := r.records[country]; ok {
+		return []Record{record}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,139 @@
+package forex
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// NewCoalescingRepository creates a CoalescingRepository that coalesces concurrent lookups made through the supplied
+// repository.
+func NewCoalescingRepository(repository RateRepository) *CoalescingRepository {
+	return &CoalescingRepository{repository: repository}
+}
+
+// CoalescingRepository is a RateRepository that shares a single in-flight lookup of another RateRepository, such as
+// the TreasuryRepository, between every concurrent caller looking up the same country and date of oldest record, or
+// the same country and period.  Each caller still stops waiting as soon as its own context is done, and the shared
+// lookup is only cancelled once every caller waiting on it has stopped waiting.
+type CoalescingRepository struct {
+	repository RateRepository
+	records    singleFlight[lookupKey, Record]
+	periods    singleFlight[periodKey, []Record]
+}
+
+// FindByCountry returns the most recent foreign exchange record for the specified country that is not older than the
+// specified dateOfOldestRecord, joining an identical lookup already in progress if there is one.
+func (r *CoalescingRepository) FindByCountry(ctx context.Context, country string, dateOfOldestRecord time.Time) (Record, error) {
+	return r.records.do(ctx, newLookupKey(country, dateOfOldestRecord), func(ctx context.Context) (Record, error) {
+		return r.repository.FindByCountry(ctx, country, dateOfOldestRecord)
+	})
+}
+
+// FindByCountryBetween returns the foreign exchange records of the specified country recorded on or between the
+// specified dates, joining an identical lookup already in progress if there is one.
+func (r *CoalescingRepository) FindByCountryBetween(ctx context.Context, country string, from, to time.Time) ([]Record, error) {
+	return r.periods.do(ctx, newPeriodKey(country, from, to), func(ctx context.Context) ([]Record, error) {
+		return r.repository.FindByCountryBetween(ctx, country, from, to)
+	})
+}
+
+// singleFlight shares a single in-flight call between every concurrent caller making a call with the same key.  Its
+// zero value is ready to use.
+type singleFlight[K comparable, V any] struct {
+	mu    sync.Mutex
+	calls map[K]*inflightCall[V]
+}
+
+// inflightCall is a call that is in progress, shared by each of its waiters.  Its result is only read once done has
+// been closed.
+type inflightCall[V any] struct {
+	done    chan struct{}
+	value   V
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// do returns the result of the supplied call, joining a call with the same key already in progress if there is one.
+// The call is made with a context detached from that of the caller, which is only cancelled once every caller has
+// stopped waiting.
+func (g *singleFlight[K, V]) do(ctx context.Context, key K, call func(context.Context) (V, error)) (V, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[K]*inflightCall[V])
+	}
+	inflight, ok := g.calls[key]
+	if !ok {
+		callCtx, cancel := context.WithCancel(detach(ctx))
+		inflight = &inflightCall[V]{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = inflight
+		go g.run(callCtx, key, inflight, call)
+	}
+	inflight.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-inflight.done:
+		return inflight.value, inflight.err
+	case <-ctx.Done():
+		g.leave(key, inflight)
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// run makes the shared call, publishes its result to the waiters and forgets the call, so that a later call starts
+// afresh.
+func (g *singleFlight[K, V]) run(ctx context.Context, key K, inflight *inflightCall[V], call func(context.Context) (V, error)) {
+	defer inflight.cancel()
+	value, err := call(ctx)
+	g.mu.Lock()
+	if g.calls[key] == inflight {
+		delete(g.calls, key)
+	}
+	g.mu.Unlock()
+	inflight.value, inflight.err = value, err
+	close(inflight.done)
+}
+
+// leave records that a waiter has stopped waiting on the supplied call, cancelling the call once no waiters remain.
+func (g *singleFlight[K, V]) leave(key K, inflight *inflightCall[V]) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	inflight.waiters--
+	if inflight.waiters == 0 {
+		inflight.cancel()
+		if g.calls[key] == inflight {
+			delete(g.calls, key)
+		}
+	}
+}
+
+// detach returns a context holding the values of the supplied context, but which is neither cancelled nor has a
+// deadline when it does.  This lets a lookup shared by several callers outlive the context of the caller that started
+// it.
+func detach(ctx context.Context) context.Context {
+	return detachedContext{parent: ctx}
+}
+
+// detachedContext is a context.Context that only passes on the values of its parent.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (c detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (c detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (c detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key any) any {
+	return c.parent.Value(key)
+}
@@ -0,0 +1,180 @@
+package forex
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"transaction-service/internal/date"
+)
+
+func TestCoalescingRepository(t *testing.T) {
+	oldest := date.NewInUTC(2023, time.February, 10)
+	ukRecord := Record{
+		RecordDate:   RecordDate{Time: date.NewInUTC(2023, time.March, 31)},
+		ExchangeRate: ExchangeRate{Value: "0.812"},
+	}
+	type result struct {
+		record Record
+		err    error
+	}
+	find := func(ctx context.Context, repo *CoalescingRepository, country string) chan result {
+		results := make(chan result, 1)
+		go func() {
+			record, err := repo.FindByCountry(ctx, country, oldest)
+			results <- result{record: record, err: err}
+		}()
+		return results
+	}
+	waitForWaiters := func(t *testing.T, repo *CoalescingRepository, country string, waiters int) {
+		assert.Eventually(t, func() bool {
+			repo.records.mu.Lock()
+			defer repo.records.mu.Unlock()
+			call, ok := repo.records.calls[newLookupKey(country, oldest)]
+			return ok && call.waiters == waiters
+		}, time.Second, time.Millisecond)
+	}
+
+	t.Run("should share a single lookup between concurrent callers looking up the same country and date", func(t *testing.T) {
+		upstream := newBlockingRepository(ukRecord, nil)
+		repo := NewCoalescingRepository(upstream)
+
+		var results []chan result
+		for n := 0; n < 5; n++ {
+			results = append(results, find(context.Background(), repo, "United Kingdom"))
+		}
+		waitForWaiters(t, repo, "United Kingdom", 5)
+		close(upstream.release)
+
+		for _, r := range results {
+			assert.Equal(t, result{record: ukRecord}, <-r)
+		}
+		assert.Equal(t, 1, upstream.callCount())
+	})
+	t.Run("should share a single lookup between concurrent callers looking up the same period", func(t *testing.T) {
+		upstream := newBlockingRepository(ukRecord, nil)
+		repo := NewCoalescingRepository(upstream)
+		to := oldest.AddDate(0, 6, 0)
+
+		results := make(chan []Record, 3)
+		for n := 0; n < 3; n++ {
+			go func() {
+				records, _ := repo.FindByCountryBetween(context.Background(), "United Kingdom", oldest, to)
+				results <- records
+			}()
+		}
+		assert.Eventually(t, func() bool {
+			repo.periods.mu.Lock()
+			defer repo.periods.mu.Unlock()
+			call, ok := repo.periods.calls[newPeriodKey("United Kingdom", oldest, to)]
+			return ok && call.waiters == 3
+		}, time.Second, time.Millisecond)
+		close(upstream.release)
+
+		for n := 0; n < 3; n++ {
+			assert.Equal(t, []Record{ukRecord}, <-results)
+		}
+		assert.Equal(t, 1, upstream.callCount())
+	})
+	t.Run("should not share lookups of different countries", func(t *testing.T) {
+		upstream := newBlockingRepository(ukRecord, nil)
+		repo := NewCoalescingRepository(upstream)
+
+		first := find(context.Background(), repo, "United Kingdom")
+		second := find(context.Background(), repo, "Australia")
+		waitForWaiters(t, repo, "United Kingdom", 1)
+		waitForWaiters(t, repo, "Australia", 1)
+		close(upstream.release)
+
+		<-first
+		<-second
+		assert.Equal(t, 2, upstream.callCount())
+	})
+	t.Run("should start a new lookup once the previous one has finished", func(t *testing.T) {
+		upstream := newBlockingRepository(Record{}, errors.New("problem"))
+		repo := NewCoalescingRepository(upstream)
+		close(upstream.release)
+
+		assert.Equal(t, result{err: errors.New("problem")}, <-find(context.Background(), repo, "United Kingdom"))
+		assert.Equal(t, result{err: errors.New("problem")}, <-find(context.Background(), repo, "United Kingdom"))
+		assert.Equal(t, 2, upstream.callCount())
+	})
+	t.Run("should stop waiting when the context of a caller is cancelled, leaving the others waiting", func(t *testing.T) {
+		upstream := newBlockingRepository(ukRecord, nil)
+		repo := NewCoalescingRepository(upstream)
+		ctx, cancel := context.WithCancel(context.Background())
+
+		cancelled := find(ctx, repo, "United Kingdom")
+		waiting := find(context.Background(), repo, "United Kingdom")
+		waitForWaiters(t, repo, "United Kingdom", 2)
+		cancel()
+		assert.Equal(t, result{err: context.Canceled}, <-cancelled)
+		close(upstream.release)
+
+		assert.Equal(t, result{record: ukRecord}, <-waiting)
+	})
+	t.Run("should cancel the shared lookup once every caller has stopped waiting", func(t *testing.T) {
+		upstream := newBlockingRepository(ukRecord, nil)
+		repo := NewCoalescingRepository(upstream)
+		ctx, cancel := context.WithCancel(context.Background())
+
+		cancelled := find(ctx, repo, "United Kingdom")
+		waitForWaiters(t, repo, "United Kingdom", 1)
+		assert.Eventually(t, func() bool { return upstream.callCount() == 1 }, time.Second, time.Millisecond)
+		cancel()
+
+		assert.Equal(t, result{err: context.Canceled}, <-cancelled)
+		<-upstream.lastContext().Done()
+	})
+}
+
+// blockingRepository is a Repository and HistoryRepository that waits until it is released, or its context is done, before returning a
+// canned result.
+type blockingRepository struct {
+	record  Record
+	err     error
+	release chan struct{}
+
+	mu       sync.Mutex
+	contexts []context.Context
+}
+
+func newBlockingRepository(record Record, err error) *blockingRepository {
+	return &blockingRepository{record: record, err: err, release: make(chan struct{})}
+}
+
+func (r *blockingRepository) FindByCountry(ctx context.Context, _ string, _ time.Time) (Record, error) {
+	r.mu.Lock()
+	r.contexts = append(r.contexts, ctx)
+	r.mu.Unlock()
+	select {
+	case <-r.release:
+		return r.record, r.err
+	case <-ctx.Done():
+		return Record{}, ctx.Err()
+	}
+}
+
+func (r *blockingRepository) FindByCountryBetween(ctx context.Context, country string, from, _ time.Time) ([]Record, error) {
+	record, err := r.FindByCountry(ctx, country, from)
+	if err != nil || record == (Record{}) {
+		return nil, err
+	}
+	return []Record{record}, nil
+}
+
+func (r *blockingRepository) callCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.contexts)
+}
+
+func (r *blockingRepository) lastContext() context.Context {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.contexts[len(r.contexts)-1]
+}
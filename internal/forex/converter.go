@@ -1,31 +1,102 @@
 package forex
 
-import "math/big"
+import (
+	"errors"
+	"math/big"
+)
+
+// ErrAmountOutOfRange is returned when a converted amount is too large to be held in cents.
+var ErrAmountOutOfRange = errors.New("converted amount is out of range")
+
+// RoundingMode determines how a converted amount that falls between two whole cents is rounded.
+type RoundingMode string
+
+const (
+	// HalfAwayFromZeroRounding rounds to the nearest cent, rounding halves away from zero.
+	HalfAwayFromZeroRounding RoundingMode = "HALF_AWAY_FROM_ZERO"
+
+	// HalfEvenRounding rounds to the nearest cent, rounding halves to the even cent, i.e. banker's rounding.
+	HalfEvenRounding RoundingMode = "HALF_EVEN"
+
+	// TruncateRounding rounds towards zero, dropping any fraction of a cent.
+	TruncateRounding RoundingMode = "TRUNCATE"
+
+	// CeilingRounding rounds towards positive infinity.
+	CeilingRounding RoundingMode = "CEILING"
+)
+
+// DefaultRoundingMode is the RoundingMode by which converted amounts have always been rounded.
+const DefaultRoundingMode = HalfAwayFromZeroRounding
+
+// RoundingModes returns every supported RoundingMode, for validating configuration.
+func RoundingModes() []string {
+	return []string{
+		string(HalfAwayFromZeroRounding),
+		string(HalfEvenRounding),
+		string(TruncateRounding),
+		string(CeilingRounding),
+	}
+}
 
 // Converter is responsible for currency conversion given an amount and an exchange rate
-type Converter struct{}
+type Converter struct {
+	// Rounding is the RoundingMode by which converted amounts are rounded to whole cents, or is empty to use the
+	// DefaultRoundingMode.
+	Rounding RoundingMode
+}
 
-// Convert performs the exchange rate calculation as accurately as possible and rounds to the nearest cent.
-func (c *Converter) Convert(amount int, exchangeRate float64) int {
-	rate := big.NewFloat(exchangeRate)
-	originalAmount := big.NewFloat(float64(amount))
+// Mode returns the RoundingMode by which the Converter rounds converted amounts.
+func (c *Converter) Mode() RoundingMode {
+	if c.Rounding == "" {
+		return DefaultRoundingMode
+	}
+	return c.Rounding
+}
 
-	targetCurrencyAmount := &big.Float{}
-	targetCurrencyAmount.Mul(originalAmount, rate)
+// Convert performs the exchange rate calculation exactly and rounds to a whole cent by the Converter's RoundingMode.
+// ErrAmountOutOfRange is returned if the rounded amount does not fit in an int64.
+func (c *Converter) Convert(amount int, exchangeRate *big.Rat) (int, error) {
+	targetCurrencyAmount := new(big.Rat).SetInt64(int64(amount))
+	targetCurrencyAmount.Mul(targetCurrencyAmount, exchangeRate)
 
-	rounded := roundToNearestBigInt(targetCurrencyAmount)
-	return int(rounded.Int64())
+	rounded := c.Mode().round(targetCurrencyAmount)
+	if !rounded.IsInt64() {
+		return 0, ErrAmountOutOfRange
+	}
+	return int(rounded.Int64()), nil
 }
 
-// roundToNearestBigInt rounds the supplied big.Float to the nearest big.Int
-func roundToNearestBigInt(value *big.Float) *big.Int {
-	newValue := &big.Float{}
-	newValue.Copy(value)
-	delta := 0.5
-	if newValue.Sign() < 0 {
-		delta = -0.5
+// round rounds the supplied big.Rat to a big.Int by the RoundingMode.  An unknown mode rounds as the
+// DefaultRoundingMode.
+func (m RoundingMode) round(value *big.Rat) *big.Int {
+	// value = quotient + remainder/denominator, where the quotient is truncated towards zero and the remainder has
+	// the sign of the value
+	quotient, remainder := new(big.Int).QuoRem(value.Num(), value.Denom(), new(big.Int))
+	if remainder.Sign() == 0 {
+		return quotient
+	}
+	awayFromZero := func() *big.Int {
+		return quotient.Add(quotient, big.NewInt(int64(value.Sign())))
+	}
+	// compare |remainder| with half the denominator
+	half := new(big.Int).Lsh(new(big.Int).Abs(remainder), 1).Cmp(value.Denom())
+	switch m {
+	case TruncateRounding:
+		return quotient
+	case CeilingRounding:
+		if value.Sign() > 0 {
+			return awayFromZero()
+		}
+		return quotient
+	case HalfEvenRounding:
+		if half > 0 || (half == 0 && quotient.Bit(0) == 1) {
+			return awayFromZero()
+		}
+		return quotient
+	default:
+		if half >= 0 {
+			return awayFromZero()
+		}
+		return quotient
 	}
-	newValue.Add(newValue, big.NewFloat(delta))
-	rounded, _ := newValue.Int(nil)
-	return rounded
 }
@@ -2,6 +2,8 @@ package forex_test
 
 import (
 	"fmt"
+	"math"
+	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -15,88 +17,205 @@ func TestConverter(t *testing.T) {
 	tcs := []struct {
 		name         string
 		amount       int
-		exchangeRate float64
+		exchangeRate string
 		wantAmount   int
 	}{
 		{
 			name:         "zero",
 			amount:       0,
-			exchangeRate: 0,
+			exchangeRate: "0",
 			wantAmount:   0,
 		},
 		{
 			name:         "small amount",
 			amount:       1,
-			exchangeRate: 0,
+			exchangeRate: "0",
 			wantAmount:   0,
 		},
 		{
 			name:         "small exchange rate",
 			amount:       0,
-			exchangeRate: 1,
+			exchangeRate: "1",
 			wantAmount:   0,
 		},
 		{
 			name:         "negative amount",
 			amount:       -1,
-			exchangeRate: 1,
+			exchangeRate: "1",
 			wantAmount:   -1,
 		},
 		{
 			name:         "big - convert a billion dollars with an exchange rate way higher than that of iranian rial",
 			amount:       1000000000,
-			exchangeRate: 1000000,
+			exchangeRate: "1000000",
 			wantAmount:   1000000000000000,
 		},
 		{
 			name:         "big negative - convert a billion dollars with an exchange rate way higher than that of iranian rial",
 			amount:       -1000000000,
-			exchangeRate: 1000000,
+			exchangeRate: "1000000",
 			wantAmount:   -1000000000000000,
 		},
 		{
 			name:         "rounding - up",
 			amount:       25,
-			exchangeRate: 0.75,
+			exchangeRate: "0.75",
 			wantAmount:   19,
 		},
 		{
 			name:         "rounding - up",
 			amount:       25,
-			exchangeRate: 0.74,
+			exchangeRate: "0.74",
 			wantAmount:   19,
 		},
 		{
 			name:         "rounding - up",
 			amount:       25,
-			exchangeRate: 0.745,
+			exchangeRate: "0.745",
 			wantAmount:   19,
 		},
 		{
 			name:         "rounding - down",
 			amount:       25,
-			exchangeRate: 0.73,
+			exchangeRate: "0.73",
 			wantAmount:   18,
 		},
 		{
 			name:         "rounding - down",
 			amount:       25,
-			exchangeRate: 0.725,
+			exchangeRate: "0.725",
 			wantAmount:   18,
 		},
 		{
 			name:         "rounding - lots of decimal places",
 			amount:       25,
-			exchangeRate: 0.77777777777777777777777,
+			exchangeRate: "0.77777777777777777777777",
 			wantAmount:   19,
 		},
+		{
+			name:         "exact - a half cent that a float64 rate would fall short of",
+			amount:       100,
+			exchangeRate: "1.005",
+			wantAmount:   101,
+		},
+		{
+			name:         "exact - a negative half cent that a float64 rate would fall short of",
+			amount:       -100,
+			exchangeRate: "1.005",
+			wantAmount:   -101,
+		},
+		{
+			name:         "exact - a large amount",
+			amount:       123456789012,
+			exchangeRate: "1.542",
+			wantAmount:   190370368657,
+		},
 	}
 	for _, tc := range tcs {
-		t.Run(fmt.Sprintf("name: %s, amount: %d, exchangeRate: %f", tc.name, tc.amount, tc.exchangeRate),
+		t.Run(fmt.Sprintf("name: %s, amount: %d, exchangeRate: %s", tc.name, tc.amount, tc.exchangeRate),
 			func(t *testing.T) {
-				result := converter.Convert(tc.amount, tc.exchangeRate)
+				result, err := converter.Convert(tc.amount, rat(tc.exchangeRate))
+				assert.Nil(t, err)
 				assert.Equal(t, tc.wantAmount, result)
 			},
 		)
 	}
+	t.Run("should return an error rather than wrapping when the converted amount does not fit in an int64", func(t *testing.T) {
+		for _, amount := range []int{math.MaxInt64, math.MinInt64} {
+			_, err := converter.Convert(amount, rat("1.5"))
+			assert.ErrorIs(t, err, forex.ErrAmountOutOfRange)
+		}
+	})
+}
+
+func TestConverterRoundingModes(t *testing.T) {
+	tcs := []struct {
+		exchangeRate string
+		amount       int
+		wantAmounts  map[forex.RoundingMode]int
+	}{
+		{
+			exchangeRate: "0.25",
+			amount:       10,
+			wantAmounts: map[forex.RoundingMode]int{
+				forex.HalfAwayFromZeroRounding: 3,
+				forex.HalfEvenRounding:         2,
+				forex.TruncateRounding:         2,
+				forex.CeilingRounding:          3,
+			},
+		},
+		{
+			exchangeRate: "0.35",
+			amount:       10,
+			wantAmounts: map[forex.RoundingMode]int{
+				forex.HalfAwayFromZeroRounding: 4,
+				forex.HalfEvenRounding:         4,
+				forex.TruncateRounding:         3,
+				forex.CeilingRounding:          4,
+			},
+		},
+		{
+			exchangeRate: "0.25",
+			amount:       -10,
+			wantAmounts: map[forex.RoundingMode]int{
+				forex.HalfAwayFromZeroRounding: -3,
+				forex.HalfEvenRounding:         -2,
+				forex.TruncateRounding:         -2,
+				forex.CeilingRounding:          -2,
+			},
+		},
+		{
+			exchangeRate: "0.35",
+			amount:       -10,
+			wantAmounts: map[forex.RoundingMode]int{
+				forex.HalfAwayFromZeroRounding: -4,
+				forex.HalfEvenRounding:         -4,
+				forex.TruncateRounding:         -3,
+				forex.CeilingRounding:          -3,
+			},
+		},
+		{
+			exchangeRate: "0.126",
+			amount:       10,
+			wantAmounts: map[forex.RoundingMode]int{
+				forex.HalfAwayFromZeroRounding: 1,
+				forex.HalfEvenRounding:         1,
+				forex.TruncateRounding:         1,
+				forex.CeilingRounding:          2,
+			},
+		},
+		{
+			exchangeRate: "1.5",
+			amount:       10,
+			wantAmounts: map[forex.RoundingMode]int{
+				forex.HalfAwayFromZeroRounding: 15,
+				forex.HalfEvenRounding:         15,
+				forex.TruncateRounding:         15,
+				forex.CeilingRounding:          15,
+			},
+		},
+	}
+	for _, tc := range tcs {
+		for mode, wantAmount := range tc.wantAmounts {
+			t.Run(fmt.Sprintf("mode: %s, amount: %d, exchangeRate: %s", mode, tc.amount, tc.exchangeRate), func(t *testing.T) {
+				converter := &forex.Converter{Rounding: mode}
+				result, err := converter.Convert(tc.amount, rat(tc.exchangeRate))
+				assert.Nil(t, err)
+				assert.Equal(t, wantAmount, result)
+			})
+		}
+	}
+	t.Run("should round half away from zero when no mode is set", func(t *testing.T) {
+		converter := &forex.Converter{}
+		assert.Equal(t, forex.HalfAwayFromZeroRounding, converter.Mode())
+		result, err := converter.Convert(10, rat("0.25"))
+		assert.Nil(t, err)
+		assert.Equal(t, 3, result)
+	})
+}
+
+// rat returns the exact value of the supplied decimal.
+func rat(value string) *big.Rat {
+	r, _ := new(big.Rat).SetString(value)
+	return r
 }
@@ -0,0 +1,128 @@
+package forex
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrCountriesNotLoaded is returned when listing the known countries before the CountryDirectory has been loaded.
+var ErrCountriesNotLoaded = errors.New("country directory has not been loaded")
+
+// Country represents a country and currency pair known to the Treasury dataset.
+type Country struct {
+	Country  string `json:"country"`
+	Currency string `json:"currency"`
+
+	// LatestRateDate is the record date of the newest exchange rate of the pair.
+	LatestRateDate string `json:"latestRateDate"`
+}
+
+// CountriesResponse represents the response for a 'list countries' operation.
+type CountriesResponse struct {
+	// Countries holds every known country and currency pair, ordered by country and then by currency.
+	Countries []Country `json:"countries"`
+
+	// RefreshedAt is when the list of countries was last refreshed from the Treasury dataset.
+	RefreshedAt time.Time `json:"refreshedAt"`
+}
+
+// NewCountryDirectory creates a CountryDirectory that finds the known countries in the records of the supplied Dataset.
+// It is empty until it has been refreshed.
+func NewCountryDirectory(dataset Dataset) *CountryDirectory {
+	return &CountryDirectory{
+		dataset:   dataset,
+		refresher: newRefresher(),
+	}
+}
+
+// CountryDirectory holds every country and currency pair known to a Dataset, along with the date of the newest exchange
+// rate of each, so that clients can discover the country names that exchange rates are looked up by.  It is refreshed
+// on a schedule in the background once started.  Should a refresh fail, the previous list continues to be used.
+type CountryDirectory struct {
+	dataset Dataset
+
+	mu          sync.RWMutex
+	countries   []Country
+	known       map[string]string
+	refreshedAt time.Time
+
+	refresher *refresher
+}
+
+// Countries returns every known country and currency pair, or ErrCountriesNotLoaded if the directory has not yet been
+// loaded.
+func (d *CountryDirectory) Countries(_ context.Context) (CountriesResponse, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.known == nil {
+		return CountriesResponse{}, ErrCountriesNotLoaded
+	}
+	return CountriesResponse{Countries: d.countries, RefreshedAt: d.refreshedAt}, nil
+}
+
+// Match matches the supplied name of a country against the known countries, ignoring case and punctuation and
+// accepting common aliases such as 'UK', or suggests the closest known countries if there is no match (see
+// matchCountry).  The second result reports false if the directory has not yet been loaded, in which case nothing is
+// matched.
+func (d *CountryDirectory) Match(country string) (match CountryMatch, loaded bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.known == nil {
+		return CountryMatch{}, false
+	}
+	return matchCountry(country, d.known), true
+}
+
+// Refresh replaces the known countries with those of every record currently in the Dataset, or returns an error (if
+// one occurred) leaving them as they were.
+func (d *CountryDirectory) Refresh(ctx context.Context) error {
+	records, err := d.dataset.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	type pair struct{ country, currency string }
+	latest := make(map[pair]time.Time)
+	for _, record := range records {
+		key := pair{country: record.Country, currency: record.Currency}
+		if record.RecordDate.After(latest[key]) {
+			latest[key] = record.RecordDate.Time
+		}
+	}
+	countries := make([]Country, 0, len(latest))
+	known := make(map[string]string)
+	for key, recordDate := range latest {
+		countries = append(countries, Country{
+			Country:        key.country,
+			Currency:       key.currency,
+			LatestRateDate: recordDate.Format(dateFormat),
+		})
+		known[normaliseCountry(key.country)] = key.country
+	}
+	sort.Slice(countries, func(i, j int) bool {
+		if countries[i].Country != countries[j].Country {
+			return countries[i].Country < countries[j].Country
+		}
+		return countries[i].Currency < countries[j].Currency
+	})
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.countries = countries
+	d.known = known
+	d.refreshedAt = time.Now()
+	return nil
+}
+
+// Start refreshes the directory straight away and then every interval in the background, until the CountryDirectory
+// is closed.  Failed refreshes are logged and retried at the next interval.
+func (d *CountryDirectory) Start(interval time.Duration) {
+	d.refresher.start(interval, "country directory", d.Refresh)
+}
+
+// Close stops refreshing the directory in the background, waiting for a refresh in progress to stop.
+func (d *CountryDirectory) Close() error {
+	d.refresher.close()
+	return nil
+}
@@ -0,0 +1,142 @@
+package forex_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"transaction-service/internal/date"
+	"transaction-service/internal/forex"
+)
+
+func TestCountryDirectory(t *testing.T) {
+	ctx := context.Background()
+	newRecord := func(country, currency string, recordDate time.Time) forex.Record {
+		return forex.Record{
+			Country:      country,
+			Currency:     currency,
+			RecordDate:   forex.RecordDate{Time: recordDate},
+			ExchangeRate: forex.ExchangeRate{Value: "1"},
+		}
+	}
+
+	t.Run("should list each country and currency pair with the date of its newest exchange rate", func(t *testing.T) {
+		dataset := &MockDataset{}
+		dataset.On("FindAll", ctx).Return([]forex.Record{
+			newRecord("Venezuela", "Fuerte", date.NewInUTC(2018, time.June, 30)),
+			newRecord("United Kingdom", "Pound", date.NewInUTC(2023, time.March, 31)),
+			newRecord("Venezuela", "Soberano", date.NewInUTC(2023, time.March, 31)),
+			newRecord("United Kingdom", "Pound", date.NewInUTC(2022, time.December, 31)),
+		}, nil)
+		directory := forex.NewCountryDirectory(dataset)
+		assert.Nil(t, directory.Refresh(ctx))
+
+		response, err := directory.Countries(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, []forex.Country{
+			{Country: "United Kingdom", Currency: "Pound", LatestRateDate: "2023-03-31"},
+			{Country: "Venezuela", Currency: "Fuerte", LatestRateDate: "2018-06-30"},
+			{Country: "Venezuela", Currency: "Soberano", LatestRateDate: "2023-03-31"},
+		}, response.Countries)
+		assert.False(t, response.RefreshedAt.IsZero())
+	})
+	t.Run("should only match countries once loaded", func(t *testing.T) {
+		dataset := &MockDataset{}
+		dataset.On("FindAll", ctx).Return([]forex.Record{
+			newRecord("United Kingdom", "Pound", date.NewInUTC(2023, time.March, 31)),
+		}, nil)
+		directory := forex.NewCountryDirectory(dataset)
+
+		_, loaded := directory.Match("United Kingdom")
+		assert.False(t, loaded)
+		_, err := directory.Countries(ctx)
+		assert.Equal(t, forex.ErrCountriesNotLoaded, err)
+
+		directory.Refresh(ctx)
+		match, loaded := directory.Match("United Kingdom")
+		assert.Equal(t, forex.CountryMatch{Country: "United Kingdom"}, match)
+		assert.True(t, loaded)
+	})
+	t.Run("should match countries by name or alias, ignoring case and punctuation", func(t *testing.T) {
+		dataset := &MockDataset{}
+		dataset.On("FindAll", ctx).Return([]forex.Record{
+			newRecord("Australia", "Dollar", date.NewInUTC(2023, time.March, 31)),
+			newRecord("Austria", "Euro", date.NewInUTC(2023, time.March, 31)),
+			newRecord("Korea", "Won", date.NewInUTC(2023, time.March, 31)),
+			newRecord("United Kingdom", "Pound", date.NewInUTC(2023, time.March, 31)),
+			newRecord("United States", "Dollar", date.NewInUTC(2023, time.March, 31)),
+		}, nil)
+		directory := forex.NewCountryDirectory(dataset)
+		directory.Refresh(ctx)
+
+		testCases := []struct {
+			name     string
+			country  string
+			expected forex.CountryMatch
+		}{
+			{
+				name:     "differently cased",
+				country:  "united KINGDOM",
+				expected: forex.CountryMatch{Country: "United Kingdom"},
+			},
+			{
+				name:     "extra whitespace",
+				country:  "  United   Kingdom ",
+				expected: forex.CountryMatch{Country: "United Kingdom"},
+			},
+			{
+				name:     "alias",
+				country:  "UK",
+				expected: forex.CountryMatch{Country: "United Kingdom"},
+			},
+			{
+				name:     "alias with punctuation",
+				country:  "Korea, South",
+				expected: forex.CountryMatch{Country: "Korea"},
+			},
+			{
+				name:     "alias of an unknown country",
+				country:  "Eurozone",
+				expected: forex.CountryMatch{},
+			},
+			{
+				name:     "misspelling",
+				country:  "Untied Kingdom",
+				expected: forex.CountryMatch{Suggestions: []string{"United Kingdom"}},
+			},
+			{
+				name:     "misspelling equally close to several countries",
+				country:  "Austrai",
+				expected: forex.CountryMatch{Suggestions: []string{"Australia", "Austria"}},
+			},
+			{
+				name:     "nothing close",
+				country:  "Atlantis",
+				expected: forex.CountryMatch{},
+			},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				match, loaded := directory.Match(tc.country)
+				assert.True(t, loaded)
+				assert.Equal(t, tc.expected, match)
+			})
+		}
+	})
+	t.Run("should keep the previous countries when a refresh fails", func(t *testing.T) {
+		dataset := &MockDataset{}
+		dataset.On("FindAll", ctx).Return([]forex.Record{
+			newRecord("United Kingdom", "Pound", date.NewInUTC(2023, time.March, 31)),
+		}, nil).Once()
+		dataset.On("FindAll", ctx).Return([]forex.Record(nil), errors.New("problem"))
+		directory := forex.NewCountryDirectory(dataset)
+		directory.Refresh(ctx)
+
+		assert.EqualError(t, directory.Refresh(ctx), "problem")
+		match, _ := directory.Match("United Kingdom")
+		assert.Equal(t, "United Kingdom", match.Country)
+	})
+}
@@ -0,0 +1,84 @@
+package forex
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// currenciesCSV maps ISO 4217 currency codes to the 'country_currency_desc' values of the Treasury dataset, e.g.
+// 'GBP,United Kingdom-Pound'.  A currency used by several countries, such as the euro, has a row for each of them, and
+// the first of its rows is the one used to look up its exchange rate, so that it always resolves the same way.  The
+// file is maintained by hand as the dataset changes.
+//
+//go:embed currencies.csv
+var currenciesCSV string
+
+// currencies holds the mapping read from currenciesCSV.
+var currencies = mustParseCurrencies(currenciesCSV)
+
+// countryCurrency identifies a currency of a country in the Treasury dataset.
+type countryCurrency struct {
+	country  string
+	currency string
+}
+
+// currencyMapping holds the mapping between ISO 4217 currency codes and the currencies of the Treasury dataset.
+type currencyMapping struct {
+	// byCode holds the countryCurrency used to look up each currency code.
+	byCode map[string]countryCurrency
+
+	// byCountryCurrency holds the currency code of each countryCurrency.
+	byCountryCurrency map[countryCurrency]string
+}
+
+// CurrencyCodes returns every supported ISO 4217 currency code, in alphabetical order.
+func CurrencyCodes() []string {
+	codes := make([]string, 0, len(currencies.byCode))
+	for code := range currencies.byCode {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// resolveCurrency returns the country and currency of the Treasury dataset by which the exchange rate of the supplied
+// ISO 4217 currency code is looked up, reporting false if the code is not supported.
+func resolveCurrency(code string) (countryCurrency, bool) {
+	resolved, ok := currencies.byCode[code]
+	return resolved, ok
+}
+
+// currencyCode returns the ISO 4217 code of the supplied currency of the supplied country, or an empty string if it is
+// not known.
+func currencyCode(country, currency string) string {
+	return currencies.byCountryCurrency[countryCurrency{country: country, currency: currency}]
+}
+
+// mustParseCurrencies reads the supplied mapping of currency codes, panicking should it be malformed, since it is
+// embedded in the binary.
+func mustParseCurrencies(content string) currencyMapping {
+	rows, err := csv.NewReader(strings.NewReader(content)).ReadAll()
+	if err != nil {
+		panic(fmt.Sprintf("reading currency codes: %v", err))
+	}
+	mapping := currencyMapping{
+		byCode:            make(map[string]countryCurrency),
+		byCountryCurrency: make(map[countryCurrency]string),
+	}
+	for _, row := range rows[1:] {
+		code, description := row[0], row[1]
+		separator := strings.LastIndex(description, "-")
+		if separator <= 0 || separator == len(description)-1 {
+			panic(fmt.Sprintf("reading currency codes: malformed country_currency_desc %q", description))
+		}
+		resolved := countryCurrency{country: description[:separator], currency: description[separator+1:]}
+		if _, ok := mapping.byCode[code]; !ok {
+			mapping.byCode[code] = resolved
+		}
+		mapping.byCountryCurrency[resolved] = code
+	}
+	return mapping
+}
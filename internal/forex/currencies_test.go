@@ -0,0 +1,45 @@
+package forex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCurrencies(t *testing.T) {
+	t.Run("should resolve a currency code to the country by which its exchange rate is looked up", func(t *testing.T) {
+		resolved, ok := resolveCurrency("GBP")
+		assert.True(t, ok)
+		assert.Equal(t, countryCurrency{country: "United Kingdom", currency: "Pound"}, resolved)
+	})
+	t.Run("should resolve a currency used by several countries to the first of them listed", func(t *testing.T) {
+		resolved, ok := resolveCurrency("EUR")
+		assert.True(t, ok)
+		assert.Equal(t, countryCurrency{country: "Euro Zone", currency: "Euro"}, resolved)
+	})
+	t.Run("should not resolve an unsupported currency code", func(t *testing.T) {
+		_, ok := resolveCurrency("XXX")
+		assert.False(t, ok)
+	})
+	t.Run("should return the code of the currency of any country using it", func(t *testing.T) {
+		assert.Equal(t, "EUR", currencyCode("Germany", "Euro"))
+		assert.Equal(t, "JPY", currencyCode("Japan", "Yen"))
+		assert.Equal(t, "", currencyCode("Atlantis", "Drachma"))
+	})
+	t.Run("should list each supported currency code once, in order", func(t *testing.T) {
+		codes := CurrencyCodes()
+		assert.Contains(t, codes, "EUR")
+		for n := 1; n < len(codes); n++ {
+			assert.Less(t, codes[n-1], codes[n])
+		}
+	})
+	t.Run("should split a country_currency_desc on its last hyphen", func(t *testing.T) {
+		mapping := mustParseCurrencies("code,country_currency_desc\nBAM,Bosnia-Hercegovina-Marka\n")
+		assert.Equal(t, countryCurrency{country: "Bosnia-Hercegovina", currency: "Marka"}, mapping.byCode["BAM"])
+	})
+	t.Run("should panic when a country_currency_desc is malformed", func(t *testing.T) {
+		assert.Panics(t, func() {
+			mustParseCurrencies("code,country_currency_desc\nGBP,United Kingdom\n")
+		})
+	})
+}
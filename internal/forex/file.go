@@ -0,0 +1,115 @@
+package forex
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// csvColumns holds the names of the columns of a CSV export of the dataset that are read, as normalised by
+// normaliseColumn.  Both the column titles of the official export (e.g. 'Record Date') and the field names of the API
+// (e.g. 'record_date') normalise to these.
+var csvColumns = []string{"record_date", "country", "exchange_rate"}
+
+// csvCurrencyColumn is the name of the column holding the currency, which is read if present.
+const csvCurrencyColumn = "currency"
+
+// NewFileDataset creates a FileDataset that reads the dataset from the file at the supplied path.
+func NewFileDataset(path string) *FileDataset {
+	return &FileDataset{path: path}
+}
+
+// FileDataset is a Dataset read from a file downloaded from the Treasury Reporting Rates of Exchange dataset, for use
+// where the Treasury API cannot be reached.  A file whose name ends in '.csv' is read as the official CSV export, while
+// one ending in '.json' is read as a response of the Treasury API (see APIResponse).  Used with a TableRepository,
+// lookups follow the same rules as they do against the Treasury API.
+type FileDataset struct {
+	path string
+}
+
+// FindAll returns every record in the file.
+func (d *FileDataset) FindAll(_ context.Context) ([]Record, error) {
+	file, err := os.Open(d.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	switch strings.ToLower(filepath.Ext(d.path)) {
+	case ".csv":
+		return readCSV(file)
+	case ".json":
+		return readJSON(file)
+	default:
+		return nil, fmt.Errorf("unsupported exchange rate file %q: must be .csv or .json", d.path)
+	}
+}
+
+// Version returns a value that changes whenever the file is changed, so that a TableRepository only reloads the file
+// when it has changed.
+func (d *FileDataset) Version() (string, error) {
+	info, err := os.Stat(d.path)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d-%d", info.ModTime().UnixNano(), info.Size()), nil
+}
+
+// readJSON reads the records of a response of the Treasury API.
+func readJSON(reader io.Reader) ([]Record, error) {
+	var response APIResponse
+	if err := json.NewDecoder(reader).Decode(&response); err != nil {
+		return nil, fmt.Errorf("reading exchange rate file: %w", err)
+	}
+	return response.Data, nil
+}
+
+// readCSV reads the records of a CSV export of the dataset, locating the columns by the titles in its header row.
+func readCSV(reader io.Reader) ([]Record, error) {
+	csvReader := csv.NewReader(reader)
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading exchange rate file header: %w", err)
+	}
+	columns := make(map[string]int)
+	for n, title := range header {
+		columns[normaliseColumn(title)] = n
+	}
+	for _, column := range csvColumns {
+		if _, ok := columns[column]; !ok {
+			return nil, fmt.Errorf("exchange rate file has no %s column", column)
+		}
+	}
+	var records []Record
+	for {
+		row, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading exchange rate file: %w", err)
+		}
+		line, _ := csvReader.FieldPos(0)
+		record := Record{Country: row[columns["country"]]}
+		if n, ok := columns[csvCurrencyColumn]; ok {
+			record.Currency = row[n]
+		}
+		if err := record.RecordDate.parse(row[columns["record_date"]]); err != nil {
+			return nil, fmt.Errorf("reading exchange rate file at line %d: %w", line, err)
+		}
+		record.ExchangeRate = ExchangeRate{Value: row[columns["exchange_rate"]]}
+		records = append(records, record)
+	}
+}
+
+// normaliseColumn returns the title of a CSV column in lower case, with spaces replaced by underscores.  The byte order
+// mark with which some tools begin a CSV file is ignored.
+func normaliseColumn(title string) string {
+	title = strings.TrimPrefix(title, "\ufeff")
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(title)), " ", "_")
+}
@@ -0,0 +1,171 @@
+package forex_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"transaction-service/internal/date"
+	"transaction-service/internal/forex"
+)
+
+func TestFileDataset(t *testing.T) {
+	ctx := context.Background()
+	wantRecords := []forex.Record{
+		{
+			Country:      "Australia",
+			Currency:     "Dollar",
+			RecordDate:   forex.RecordDate{Time: date.NewInUTC(2023, time.March, 31)},
+			ExchangeRate: forex.ExchangeRate{Value: "1.495"},
+		},
+		{
+			Country:      "United Kingdom",
+			Currency:     "Pound",
+			RecordDate:   forex.RecordDate{Time: date.NewInUTC(2023, time.March, 31)},
+			ExchangeRate: forex.ExchangeRate{Value: "0.812"},
+		},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		tcs := []struct {
+			name     string
+			fileName string
+			content  string
+		}{
+			{
+				name:     "should read the official CSV export",
+				fileName: "RprtRateXchg_20230331.csv",
+				content: "\ufeffRecord Date,Country,Currency,Country - Currency Description,Exchange Rate,Effective Date\n" +
+					"2023-03-31,Australia,Dollar,Australia-Dollar,1.495,2023-03-31\n" +
+					"2023-03-31,United Kingdom,Pound,United Kingdom-Pound,0.812,2023-03-31\n",
+			},
+			{
+				name:     "should read a CSV file whose columns are titled with the field names of the API",
+				fileName: "rates.CSV",
+				content: "country,currency,exchange_rate,record_date\n" +
+					"Australia,Dollar,1.495,2023-03-31\n" +
+					"United Kingdom,Pound,0.812,2023-03-31\n",
+			},
+			{
+				name:     "should read a response of the Treasury API",
+				fileName: "rates.json",
+				content: `{"data": [
+					{"record_date": "2023-03-31", "country": "Australia", "currency": "Dollar", "exchange_rate": "1.495"},
+					{"record_date": "2023-03-31", "country": "United Kingdom", "currency": "Pound", "exchange_rate": "0.812"}
+				]}`,
+			},
+		}
+		for _, tc := range tcs {
+			t.Run(tc.name, func(t *testing.T) {
+				dataset := forex.NewFileDataset(writeFile(t, t.TempDir(), tc.fileName, tc.content))
+
+				records, err := dataset.FindAll(ctx)
+				assert.Nil(t, err)
+				assert.Equal(t, wantRecords, records)
+			})
+		}
+	})
+
+	t.Run("should read records whose exchange rates are not valid, rejecting the rates only once they are used", func(t *testing.T) {
+		content := "Record Date,Country,Exchange Rate\n" +
+			"2023-03-31,Australia,1.495\n" +
+			"2023-03-31,Canada,n/a\n" +
+			"2023-03-31,Fiji,1.495e0\n" +
+			"2023-03-31,Ghana,-1.495\n" +
+			"2023-03-31,Haiti,0.000\n"
+		dataset := forex.NewFileDataset(writeFile(t, t.TempDir(), "rates.csv", content))
+
+		records, err := dataset.FindAll(ctx)
+
+		assert.Nil(t, err)
+		assert.Len(t, records, 5)
+		_, err = records[0].ExchangeRate.Rat()
+		assert.Nil(t, err)
+		_, err = records[1].ExchangeRate.Rat()
+		assert.EqualError(t, err, `invalid exchange rate "n/a"`)
+		_, err = records[2].ExchangeRate.Rat()
+		assert.EqualError(t, err, `invalid exchange rate "1.495e0"`)
+		_, err = records[3].ExchangeRate.Rat()
+		assert.EqualError(t, err, `invalid exchange rate "-1.495"`)
+		_, err = records[4].ExchangeRate.Rat()
+		assert.EqualError(t, err, `invalid exchange rate "0.000": must be greater than zero`)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		tcs := []struct {
+			name     string
+			fileName string
+			content  string
+			wantErr  string
+		}{
+			{
+				name:     "should return an error when the file is neither CSV nor JSON",
+				fileName: "rates.txt",
+				content:  "",
+				wantErr:  "must be .csv or .json",
+			},
+			{
+				name:     "should return an error when a column is missing",
+				fileName: "rates.csv",
+				content:  "Record Date,Country\n2023-03-31,Australia\n",
+				wantErr:  "exchange rate file has no exchange_rate column",
+			},
+			{
+				name:     "should return an error identifying the line of an unreadable record",
+				fileName: "rates.csv",
+				content:  "Record Date,Country,Exchange Rate\n2023-03-31,Australia,1.495\n31/03/2023,United Kingdom,0.812\n",
+				wantErr:  "reading exchange rate file at line 3",
+			},
+			{
+				name:     "should return an error when the JSON is malformed",
+				fileName: "rates.json",
+				content:  `{"data": [`,
+				wantErr:  "reading exchange rate file",
+			},
+		}
+		for _, tc := range tcs {
+			t.Run(tc.name, func(t *testing.T) {
+				dataset := forex.NewFileDataset(writeFile(t, t.TempDir(), tc.fileName, tc.content))
+
+				records, err := dataset.FindAll(ctx)
+				assert.ErrorContains(t, err, tc.wantErr)
+				assert.Nil(t, records)
+			})
+		}
+		t.Run("should return an error when the file does not exist", func(t *testing.T) {
+			dataset := forex.NewFileDataset(filepath.Join(t.TempDir(), "rates.csv"))
+
+			_, err := dataset.FindAll(ctx)
+			assert.ErrorIs(t, err, os.ErrNotExist)
+		})
+	})
+
+	t.Run("should be reloaded by a table once the file changes", func(t *testing.T) {
+		dir := t.TempDir()
+		path := writeFile(t, dir, "rates.csv", "Record Date,Country,Exchange Rate\n2023-03-31,Australia,1.495\n")
+		table := forex.NewTableRepository(forex.NewFileDataset(path))
+		assert.Nil(t, table.Refresh(ctx))
+
+		writeFile(t, dir, "rates.csv", "Record Date,Country,Exchange Rate\n2023-03-31,Australia,1.495\n2023-06-30,Australia,1.502\n")
+		os.Chtimes(path, time.Now().Add(time.Minute), time.Now().Add(time.Minute))
+		assert.Nil(t, table.Refresh(ctx))
+
+		record, err := table.FindByCountry(ctx, "Australia", date.NewInUTC(2023, time.January, 1))
+		assert.Nil(t, err)
+		assert.Equal(t, "1.502", record.ExchangeRate.Value)
+	})
+}
+
+// writeFile writes the supplied content to the named file in the supplied directory, returning its path.  Should an
+// error occur, the current test will be failed.
+func writeFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
@@ -7,23 +7,34 @@ import (
 )
 
 // MockHttpClient enables stubbing of http.Client's interface.  This allows us to test details of a call that would
-// be made to an api via http.  It stores the request made, so that it can be asserted on later.  It also returns a
-// canned http.Response.
+// be made to an api via http.  It stores the requests made, so that they can be asserted on later.  It also returns
+// canned http.Responses.
 type MockHttpClient struct {
-	Request        *http.Request
-	cannedResponse *http.Response
+	Request         *http.Request
+	Requests        []*http.Request
+	cannedResponses []*http.Response
 }
 
 // SetCannedResponse sets the response to return when a request is received.
 func (c *MockHttpClient) SetCannedResponse(status int, body string) {
-	c.cannedResponse = newResponse(status, body)
+	c.cannedResponses = []*http.Response{newResponse(status, body)}
+}
+
+// AddCannedResponse adds a response to return, in turn, after those already added.
+func (c *MockHttpClient) AddCannedResponse(status int, body string) {
+	c.cannedResponses = append(c.cannedResponses, newResponse(status, body))
 }
 
 // Do implements the http.Client's Do operation.  This stub implementation simply stores the request and returns the
-// canned response.
+// next canned response, returning the last one again once the others have been returned.
 func (c *MockHttpClient) Do(req *http.Request) (*http.Response, error) {
 	c.Request = req
-	return c.cannedResponse, nil
+	c.Requests = append(c.Requests, req)
+	response := c.cannedResponses[0]
+	if len(c.cannedResponses) > 1 {
+		c.cannedResponses = c.cannedResponses[1:]
+	}
+	return response, nil
 }
 
 // newResponse is a convenience function for setting up a new http.Response.
@@ -0,0 +1,149 @@
+package forex
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"transaction-service/internal/errorhandling"
+)
+
+// CacheAdministrator is the interface of the exchange rate cache expected by the handlers that report on and
+// invalidate it.
+type CacheAdministrator interface {
+	Stats() CacheStats
+	Invalidate(country string) int
+	InvalidateAll() int
+}
+
+// InvalidateResponse represents the http response body of the 'invalidate exchange rate cache' operation.
+type InvalidateResponse struct {
+	Invalidated int `json:"invalidated"`
+}
+
+// ConfigureCacheHandlers configures the supplied router with handlers that report the statistics of the supplied
+// cache and invalidate its entries.
+func ConfigureCacheHandlers(router *gin.Engine, cache CacheAdministrator) {
+	router.GET("/exchange-rate-cache", NewCacheStatsHandler(cache))
+	router.DELETE("/exchange-rate-cache", NewCacheInvalidateHandler(cache))
+}
+
+// NewCacheStatsHandler is responsible for responding to the 'exchange rate cache statistics' http request with the
+// counts of hits and misses, from which the number of calls saved to the Treasury API can be seen.
+func NewCacheStatsHandler(cache CacheAdministrator) func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, cache.Stats())
+	}
+}
+
+// NewCacheInvalidateHandler is responsible for mapping the incoming 'invalidate exchange rate cache' http request into
+// the invalidation of the cached entries of the country given by the 'country' query parameter, or of every entry if
+// no country is given.
+func NewCacheInvalidateHandler(cache CacheAdministrator) func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		var invalidated int
+		if country, ok := ctx.GetQuery("country"); ok {
+			invalidated = cache.Invalidate(country)
+		} else {
+			invalidated = cache.InvalidateAll()
+		}
+		ctx.JSON(http.StatusOK, InvalidateResponse{Invalidated: invalidated})
+	}
+}
+
+// HistoryFinder is the interface of the exchange rate history business service expected by the handler that deals with
+// looking up the exchange rates of a period.
+type HistoryFinder interface {
+	History(ctx context.Context, request HistoryRequest) (HistoryResponse, error)
+}
+
+// ConfigureHistoryHandler configures the supplied router with a handler that uses the supplied service to look up the
+// exchange rates of a country over a period.
+func ConfigureHistoryHandler(router *gin.Engine, service HistoryFinder) {
+	router.GET("/exchange-rates", NewHistoryHandler(service))
+}
+
+// NewHistoryHandler is responsible for mapping the incoming 'exchange rate history' http request into the call to the
+// business service and mapping the result back to a http response.  The country and the period are taken from the
+// 'country', 'from' and 'to' query parameters.
+func NewHistoryHandler(service HistoryFinder) func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		request := HistoryRequest{
+			Country: queryParam(ctx, "country"),
+			From:    queryParam(ctx, "from"),
+			To:      queryParam(ctx, "to"),
+		}
+		response, err := service.History(ctx, request)
+		if err != nil {
+			ctx.Error(err)
+			return
+		}
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+// CountryLister is the interface of the country directory expected by the handler that deals with listing the known
+// countries.
+type CountryLister interface {
+	Countries(ctx context.Context) (CountriesResponse, error)
+}
+
+// ConfigureCountriesHandler configures the supplied router with a handler that uses the supplied directory to list the
+// countries known to the Treasury dataset.
+func ConfigureCountriesHandler(router *gin.Engine, directory CountryLister) {
+	router.GET("/countries", NewCountriesHandler(directory))
+}
+
+// NewCountriesHandler is responsible for responding to the 'list countries' http request with every known country and
+// currency pair, so that clients can discover the country names by which exchange rates are looked up.
+func NewCountriesHandler(directory CountryLister) func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		response, err := directory.Countries(ctx)
+		if err != nil {
+			ctx.Error(err)
+			return
+		}
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+// Quoter is the interface of the quote business service expected by the handler that deals with converting amounts
+// without storing a transaction.
+type Quoter interface {
+	Quote(ctx context.Context, request QuoteRequest) (QuoteResponse, error)
+}
+
+// ConfigureQuoteHandler configures the supplied router with a handler that uses the supplied service to convert
+// amounts without storing a transaction.
+func ConfigureQuoteHandler(router *gin.Engine, service Quoter) {
+	router.POST("/conversions", NewQuoteHandler(service))
+}
+
+// NewQuoteHandler is responsible for mapping the incoming 'convert amount' http request into the call to the business
+// service and mapping the result back to a http response.
+func NewQuoteHandler(service Quoter) func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		var request QuoteRequest
+		if err := ctx.Bind(&request); err != nil {
+			ctx.Error(errors.New(errorhandling.BadRequest))
+			return
+		}
+		response, err := service.Quote(ctx, request)
+		if err != nil {
+			ctx.Error(err)
+			return
+		}
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+// queryParam returns the value of the supplied query parameter, or nil if it is not supplied.
+func queryParam(ctx *gin.Context, key string) *string {
+	value, ok := ctx.GetQuery(key)
+	if !ok {
+		return nil
+	}
+	return &value
+}
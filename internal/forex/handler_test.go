@@ -0,0 +1,240 @@
+package forex_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"transaction-service/internal/business"
+	"transaction-service/internal/errorhandling"
+	"transaction-service/internal/forex"
+)
+
+func TestCacheHandlers(t *testing.T) {
+	setUp := func() (*gin.Engine, *httptest.ResponseRecorder, *MockCacheAdministrator) {
+		router := gin.Default()
+		mockCache := &MockCacheAdministrator{}
+		forex.ConfigureCacheHandlers(router, mockCache)
+		return router, httptest.NewRecorder(), mockCache
+	}
+
+	t.Run("should report the cache statistics", func(t *testing.T) {
+		router, rr, mockCache := setUp()
+		mockCache.On("Stats").Return(forex.CacheStats{Hits: 7, NegativeHits: 2, Misses: 3, Evictions: 1, Entries: 2})
+
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/exchange-rate-cache", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"hits": 7, "negativeHits": 2, "misses": 3, "evictions": 1, "entries": 2}`, rr.Body.String())
+		mockCache.AssertExpectations(t)
+	})
+	t.Run("should invalidate the entries of the supplied country", func(t *testing.T) {
+		router, rr, mockCache := setUp()
+		mockCache.On("Invalidate", "United Kingdom").Return(2)
+
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/exchange-rate-cache?country=United%20Kingdom", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"invalidated": 2}`, rr.Body.String())
+		mockCache.AssertExpectations(t)
+	})
+	t.Run("should invalidate every entry when no country is supplied", func(t *testing.T) {
+		router, rr, mockCache := setUp()
+		mockCache.On("InvalidateAll").Return(5)
+
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/exchange-rate-cache", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"invalidated": 5}`, rr.Body.String())
+		mockCache.AssertExpectations(t)
+	})
+}
+
+func TestHistoryHandler(t *testing.T) {
+	setUp := func() (*gin.Engine, *httptest.ResponseRecorder, *MockHistoryFinder) {
+		router := gin.Default()
+		router.Use(errorhandling.NewMiddleware)
+		mockService := &MockHistoryFinder{}
+		forex.ConfigureHistoryHandler(router, mockService)
+		return router, httptest.NewRecorder(), mockService
+	}
+	str := func(value string) *string { return &value }
+
+	t.Run("should map the query parameters to the request and respond with the exchange rates", func(t *testing.T) {
+		router, rr, mockService := setUp()
+		mockService.On("History", mock.Anything, forex.HistoryRequest{
+			Country: str("United Kingdom"),
+			From:    str("2022-10-01"),
+			To:      str("2023-04-30"),
+		}).Return(forex.HistoryResponse{
+			Country: "United Kingdom",
+			From:    "2022-10-01",
+			To:      "2023-04-30",
+			Rates:   []forex.Rate{{RecordDate: "2022-12-31", ExchangeRate: "0.826"}},
+		}, nil)
+
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet,
+			"/exchange-rates?country=United%20Kingdom&from=2022-10-01&to=2023-04-30", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{
+			"country": "United Kingdom",
+			"from": "2022-10-01",
+			"to": "2023-04-30",
+			"rates": [{"recordDate": "2022-12-31", "exchangeRate": 0.826}]
+		}`, rr.Body.String())
+		mockService.AssertExpectations(t)
+	})
+	t.Run("should respond with the validation error", func(t *testing.T) {
+		router, rr, mockService := setUp()
+		mockService.On("History", mock.Anything, forex.HistoryRequest{}).Return(forex.HistoryResponse{}, &business.Error{
+			Message: "VALIDATION_ERROR",
+			Fields:  []business.FieldError{{FieldName: "country", Reason: "REQUIRED"}},
+		})
+
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/exchange-rates", nil))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.JSONEq(t, `{"message": "VALIDATION_ERROR", "fields": [{"fieldName": "country", "reason": "REQUIRED"}]}`,
+			rr.Body.String())
+	})
+}
+
+func TestCountriesHandler(t *testing.T) {
+	setUp := func() (*gin.Engine, *httptest.ResponseRecorder, *MockCountryLister) {
+		router := gin.Default()
+		router.Use(errorhandling.NewMiddleware)
+		mockDirectory := &MockCountryLister{}
+		forex.ConfigureCountriesHandler(router, mockDirectory)
+		return router, httptest.NewRecorder(), mockDirectory
+	}
+
+	t.Run("should respond with the known countries", func(t *testing.T) {
+		router, rr, mockDirectory := setUp()
+		mockDirectory.On("Countries", mock.Anything).Return(forex.CountriesResponse{
+			Countries:   []forex.Country{{Country: "United Kingdom", Currency: "Pound", LatestRateDate: "2023-03-31"}},
+			RefreshedAt: time.Date(2023, time.May, 1, 9, 0, 0, 0, time.UTC),
+		}, nil)
+
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/countries", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{
+			"countries": [{"country": "United Kingdom", "currency": "Pound", "latestRateDate": "2023-03-31"}],
+			"refreshedAt": "2023-05-01T09:00:00Z"
+		}`, rr.Body.String())
+	})
+	t.Run("should respond with a system error when the countries have not been loaded", func(t *testing.T) {
+		router, rr, mockDirectory := setUp()
+		mockDirectory.On("Countries", mock.Anything).Return(forex.CountriesResponse{}, forex.ErrCountriesNotLoaded)
+
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/countries", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
+
+func TestQuoteHandler(t *testing.T) {
+	setUp := func() (*gin.Engine, *httptest.ResponseRecorder, *MockQuoter) {
+		router := gin.Default()
+		router.Use(errorhandling.NewMiddleware)
+		mockService := &MockQuoter{}
+		forex.ConfigureQuoteHandler(router, mockService)
+		return router, httptest.NewRecorder(), mockService
+	}
+	str := func(value string) *string { return &value }
+	amount := 12345
+	newPostRequest := func(body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/conversions", strings.NewReader(body))
+		req.Header.Add("Content-Type", "application/json")
+		return req
+	}
+
+	t.Run("should map the request body to the request and respond with the converted amount", func(t *testing.T) {
+		router, rr, mockService := setUp()
+		mockService.On("Quote", mock.Anything, forex.QuoteRequest{
+			AmountInCents: &amount,
+			Date:          str("2023-05-01"),
+			Country:       str("United Kingdom"),
+		}).Return(forex.QuoteResponse{
+			AmountInCents:          12345,
+			ConvertedAmountInCents: 10024,
+			ExchangeRate:           "0.812",
+			RateDate:               "2023-03-31",
+			CurrencyCode:           "GBP",
+		}, nil)
+
+		router.ServeHTTP(rr, newPostRequest(`{"amountInCents": 12345, "date": "2023-05-01", "country": "United Kingdom"}`))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{
+			"amountInCents": 12345,
+			"convertedAmountInCents": 10024,
+			"exchangeRate": 0.812,
+			"rateDate": "2023-03-31",
+			"currencyCode": "GBP"
+		}`, rr.Body.String())
+		mockService.AssertExpectations(t)
+	})
+	t.Run("should respond with a bad request error when the request body is malformed", func(t *testing.T) {
+		router, rr, mockService := setUp()
+
+		router.ServeHTTP(rr, newPostRequest(`{"amountInCents": "`))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+type MockQuoter struct {
+	mock.Mock
+}
+
+func (m *MockQuoter) Quote(ctx context.Context, request forex.QuoteRequest) (forex.QuoteResponse, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(forex.QuoteResponse), args.Error(1)
+}
+
+type MockCountryLister struct {
+	mock.Mock
+}
+
+func (m *MockCountryLister) Countries(ctx context.Context) (forex.CountriesResponse, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(forex.CountriesResponse), args.Error(1)
+}
+
+type MockHistoryFinder struct {
+	mock.Mock
+}
+
+func (m *MockHistoryFinder) History(ctx context.Context, request forex.HistoryRequest) (forex.HistoryResponse, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(forex.HistoryResponse), args.Error(1)
+}
+
+type MockCacheAdministrator struct {
+	mock.Mock
+}
+
+func (m *MockCacheAdministrator) Stats() forex.CacheStats {
+	args := m.Called()
+	return args.Get(0).(forex.CacheStats)
+}
+
+func (m *MockCacheAdministrator) Invalidate(country string) int {
+	args := m.Called(country)
+	return args.Int(0)
+}
+
+func (m *MockCacheAdministrator) InvalidateAll() int {
+	args := m.Called()
+	return args.Int(0)
+}
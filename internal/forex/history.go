@@ -0,0 +1,148 @@
+package forex
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"transaction-service/internal/business"
+	"transaction-service/internal/validation"
+)
+
+const (
+	validationErrorMessage = "VALIDATION_ERROR"
+
+	countryFieldName = "country"
+	countryMinLength = 2
+	fromFieldName    = "from"
+	toFieldName      = "to"
+)
+
+// HistoryRequest represents the user's request for the exchange rates of a country over a period.
+type HistoryRequest struct {
+	// Country is the country whose exchange rates are requested.
+	Country *string
+
+	// From and To are the earliest and latest record dates of the requested exchange rates.
+	From *string
+	To   *string
+}
+
+// HistoryResponse represents the response for an 'exchange rate history' operation.
+type HistoryResponse struct {
+	Country string `json:"country"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+
+	// Rates holds every exchange rate of the country recorded in the period, ordered by record date.
+	Rates []Rate `json:"rates"`
+}
+
+// Rate represents a single exchange rate record, the exchange rate being written exactly as recorded.
+type Rate struct {
+	RecordDate   string      `json:"recordDate"`
+	ExchangeRate json.Number `json:"exchangeRate"`
+}
+
+// HistoryRepository defines the interface expected of the Repository for finding the exchange rate records of a
+// period.
+type HistoryRepository interface {
+	FindByCountryBetween(ctx context.Context, country string, from, to time.Time) ([]Record, error)
+}
+
+// RateRepository is the interface of a repository that finds both the newest exchange rate record of a country and
+// the records of a period, such as the TreasuryRepository and the decorators that cache, coalesce and fall back on its
+// lookups.
+type RateRepository interface {
+	Repository
+	HistoryRepository
+}
+
+// NewHistoryService creates a HistoryService that finds exchange rate records using the supplied repository.
+func NewHistoryService(repository HistoryRepository) *HistoryService {
+	return &HistoryService{
+		repository: repository,
+		validator:  historyValidator{},
+	}
+}
+
+// HistoryService is the business service for looking up the series of exchange rates of a country.
+type HistoryService struct {
+	repository HistoryRepository
+	validator  historyValidator
+}
+
+// History returns every exchange rate of the requested country recorded on or between the requested dates.  An empty
+// list of rates is returned if there are none.  Rates that are not valid, such as a malformed rate in the dataset, are
+// left out.  If the input does not satisfy the business rules, an error will be returned.
+func (s *HistoryService) History(ctx context.Context, request HistoryRequest) (HistoryResponse, error) {
+	if err := s.validator.validate(request); err != nil {
+		return HistoryResponse{}, err
+	}
+	from, _ := time.Parse(validation.DateFormat, *request.From)
+	to, _ := time.Parse(validation.DateFormat, *request.To)
+	records, err := s.repository.FindByCountryBetween(ctx, *request.Country, from, to)
+	if err != nil {
+		return HistoryResponse{}, err
+	}
+	rates := make([]Rate, 0, len(records))
+	for _, record := range records {
+		if _, err := record.ExchangeRate.Rat(); err != nil {
+			log.Printf("leaving exchange rate of %s recorded on %s out of its history: %v\n",
+				record.Country, record.RecordDate.Format(dateFormat), err)
+			continue
+		}
+		rates = append(rates, Rate{
+			RecordDate:   record.RecordDate.Format(dateFormat),
+			ExchangeRate: json.Number(record.ExchangeRate.Value),
+		})
+	}
+	return HistoryResponse{
+		Country: *request.Country,
+		From:    *request.From,
+		To:      *request.To,
+		Rates:   rates,
+	}, nil
+}
+
+// historyValidator is responsible for validating input of the 'exchange rate history' operation.
+type historyValidator struct{}
+
+// validate performs business validation on the supplied HistoryRequest.  The period is only checked to end no earlier
+// than it starts once both of its dates are known to be valid.
+func (v historyValidator) validate(request HistoryRequest) error {
+	var fieldErrors []business.FieldError
+	if err := validation.IsRequiredString(countryFieldName, request.Country); err != nil {
+		fieldErrors = append(fieldErrors, *err)
+	}
+	if err := validation.IsMinLength(countryFieldName, request.Country, countryMinLength); err != nil {
+		fieldErrors = append(fieldErrors, *err)
+	}
+	if err := validation.IsRequiredString(fromFieldName, request.From); err != nil {
+		fieldErrors = append(fieldErrors, *err)
+	}
+	from, fromErr := validation.IsDate(fromFieldName, request.From)
+	if fromErr != nil {
+		fieldErrors = append(fieldErrors, *fromErr)
+	}
+	if err := validation.IsRequiredString(toFieldName, request.To); err != nil {
+		fieldErrors = append(fieldErrors, *err)
+	}
+	to, toErr := validation.IsDate(toFieldName, request.To)
+	if toErr != nil {
+		fieldErrors = append(fieldErrors, *toErr)
+	}
+	if request.From != nil && request.To != nil && fromErr == nil && toErr == nil {
+		if err := validation.IsDateNotBefore(toFieldName, to, from); err != nil {
+			fieldErrors = append(fieldErrors, *err)
+		}
+	}
+	if len(fieldErrors) > 0 {
+		return &business.Error{
+			Message: validationErrorMessage,
+			Fields:  fieldErrors,
+		}
+	}
+	return nil
+}
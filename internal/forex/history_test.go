@@ -0,0 +1,158 @@
+package forex_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"transaction-service/internal/business"
+	"transaction-service/internal/date"
+	"transaction-service/internal/forex"
+)
+
+func TestHistoryService(t *testing.T) {
+	ctx := context.Background()
+	str := func(value string) *string { return &value }
+	setUp := func() (*forex.HistoryService, *MockHistoryRepository) {
+		mockRepo := &MockHistoryRepository{}
+		return forex.NewHistoryService(mockRepo), mockRepo
+	}
+
+	t.Run("success", func(t *testing.T) {
+		t.Run("should return every exchange rate of the country recorded in the period", func(t *testing.T) {
+			service, mockRepo := setUp()
+			mockRepo.On("FindByCountryBetween", ctx, "United Kingdom",
+				date.NewInUTC(2022, time.October, 1), date.NewInUTC(2023, time.April, 30)).
+				Return([]forex.Record{
+					{RecordDate: forex.RecordDate{Time: date.NewInUTC(2022, time.December, 31)}, ExchangeRate: forex.ExchangeRate{Value: "0.826"}},
+					{RecordDate: forex.RecordDate{Time: date.NewInUTC(2023, time.March, 31)}, ExchangeRate: forex.ExchangeRate{Value: "0.812"}},
+				}, nil)
+
+			response, err := service.History(ctx, forex.HistoryRequest{
+				Country: str("United Kingdom"),
+				From:    str("2022-10-01"),
+				To:      str("2023-04-30"),
+			})
+
+			assert.Nil(t, err)
+			assert.Equal(t, forex.HistoryResponse{
+				Country: "United Kingdom",
+				From:    "2022-10-01",
+				To:      "2023-04-30",
+				Rates: []forex.Rate{
+					{RecordDate: "2022-12-31", ExchangeRate: "0.826"},
+					{RecordDate: "2023-03-31", ExchangeRate: "0.812"},
+				},
+			}, response)
+			mockRepo.AssertExpectations(t)
+		})
+		t.Run("should leave out exchange rates that are not valid", func(t *testing.T) {
+			service, mockRepo := setUp()
+			mockRepo.On("FindByCountryBetween", ctx, "United Kingdom", mock.Anything, mock.Anything).
+				Return([]forex.Record{
+					{RecordDate: forex.RecordDate{Time: date.NewInUTC(2022, time.December, 31)}, ExchangeRate: forex.ExchangeRate{Value: "n/a"}},
+					{RecordDate: forex.RecordDate{Time: date.NewInUTC(2023, time.March, 31)}, ExchangeRate: forex.ExchangeRate{Value: "0.812"}},
+				}, nil)
+
+			response, err := service.History(ctx, forex.HistoryRequest{
+				Country: str("United Kingdom"),
+				From:    str("2022-10-01"),
+				To:      str("2023-04-30"),
+			})
+
+			assert.Nil(t, err)
+			assert.Equal(t, []forex.Rate{{RecordDate: "2023-03-31", ExchangeRate: "0.812"}}, response.Rates)
+		})
+		t.Run("should return an empty list of rates when there are none in the period", func(t *testing.T) {
+			service, mockRepo := setUp()
+			mockRepo.On("FindByCountryBetween", ctx, "Atlantis", mock.Anything, mock.Anything).Return([]forex.Record(nil), nil)
+
+			response, err := service.History(ctx, forex.HistoryRequest{
+				Country: str("Atlantis"),
+				From:    str("2023-01-01"),
+				To:      str("2023-01-01"),
+			})
+
+			assert.Nil(t, err)
+			assert.Equal(t, []forex.Rate{}, response.Rates)
+		})
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		tcs := []struct {
+			name    string
+			request forex.HistoryRequest
+			wantErr error
+		}{
+			{
+				name:    "should return a validation error when no input is supplied",
+				request: forex.HistoryRequest{},
+				wantErr: &business.Error{
+					Message: "VALIDATION_ERROR",
+					Fields: []business.FieldError{
+						{FieldName: "country", Reason: "REQUIRED"},
+						{FieldName: "from", Reason: "REQUIRED"},
+						{FieldName: "to", Reason: "REQUIRED"},
+					},
+				},
+			},
+			{
+				name:    "should return a validation error when the input is badly formatted",
+				request: forex.HistoryRequest{Country: str("A"), From: str("01/10/2022"), To: str("2023-04-31")},
+				wantErr: &business.Error{
+					Message: "VALIDATION_ERROR",
+					Fields: []business.FieldError{
+						{FieldName: "country", Reason: "MIN_LENGTH"},
+						{FieldName: "from", Reason: "DATE_BAD_FORMAT"},
+						{FieldName: "to", Reason: "DATE_BAD_FORMAT"},
+					},
+				},
+			},
+			{
+				name:    "should return a validation error when the period ends before it starts",
+				request: forex.HistoryRequest{Country: str("United Kingdom"), From: str("2023-04-30"), To: str("2022-10-01")},
+				wantErr: &business.Error{
+					Message: "VALIDATION_ERROR",
+					Fields:  []business.FieldError{{FieldName: "to", Reason: "DATE_TOO_EARLY"}},
+				},
+			},
+		}
+		for _, tc := range tcs {
+			t.Run(tc.name, func(t *testing.T) {
+				service, mockRepo := setUp()
+
+				response, err := service.History(ctx, tc.request)
+
+				assert.Equal(t, tc.wantErr, err)
+				assert.Equal(t, forex.HistoryResponse{}, response)
+				mockRepo.AssertExpectations(t)
+			})
+		}
+		t.Run("should return an error when there is a system problem finding the records", func(t *testing.T) {
+			service, mockRepo := setUp()
+			mockRepo.On("FindByCountryBetween", ctx, mock.Anything, mock.Anything, mock.Anything).
+				Return([]forex.Record(nil), errors.New("problem"))
+
+			_, err := service.History(ctx, forex.HistoryRequest{
+				Country: str("United Kingdom"),
+				From:    str("2022-10-01"),
+				To:      str("2023-04-30"),
+			})
+
+			assert.EqualError(t, err, "problem")
+		})
+	})
+}
+
+type MockHistoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockHistoryRepository) FindByCountryBetween(ctx context.Context, country string, from, to time.Time) ([]forex.Record, error) {
+	args := m.Called(ctx, country, from, to)
+	return args.Get(0).([]forex.Record), args.Error(1)
+}
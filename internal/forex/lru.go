@@ -0,0 +1,123 @@
+package forex
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// newLRUCache creates an lruCache holding at most maxEntries entries.  The supplied empty function reports whether a
+// value records the absence of a result, so that hits on it are counted as NegativeHits.
+func newLRUCache[K comparable, V any](maxEntries int, empty func(V) bool) *lruCache[K, V] {
+	return &lruCache[K, V]{
+		maxEntries: maxEntries,
+		empty:      empty,
+		entries:    make(map[K]*list.Element),
+		recency:    list.New(),
+	}
+}
+
+// lruCache is a cache of values by key, each of which expires after its own time to live.  Once it holds more than
+// its maximum number of entries, the least recently used entry is evicted.  It is safe for concurrent use.
+type lruCache[K comparable, V any] struct {
+	maxEntries int
+	empty      func(V) bool
+
+	mu      sync.Mutex
+	entries map[K]*list.Element
+	recency *list.List
+	stats   CacheStats
+}
+
+// lruEntry is a cached value, held in the recency list of an lruCache with the most recently used entry at the front.
+type lruEntry[K comparable, V any] struct {
+	key       K
+	value     V
+	storedAt  time.Time
+	expiresAt time.Time
+}
+
+// get returns the value cached under the supplied key, along with when it was stored, reporting false if there is
+// none that has not expired by the supplied time.  An expired entry is discarded.
+func (c *lruCache[K, V]) get(key K, now time.Time) (V, time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if ok {
+		entry := element.Value.(*lruEntry[K, V])
+		if now.Before(entry.expiresAt) {
+			c.recency.MoveToFront(element)
+			c.stats.Hits++
+			if c.empty(entry.value) {
+				c.stats.NegativeHits++
+			}
+			return entry.value, entry.storedAt, true
+		}
+		c.remove(element)
+	}
+	c.stats.Misses++
+	var zero V
+	return zero, time.Time{}, false
+}
+
+// put caches the supplied value under the supplied key until the supplied time to live has passed, evicting the least
+// recently used entries should the cache be full.  Nothing is cached if the time to live is not positive.
+func (c *lruCache[K, V]) put(key K, value V, now time.Time, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+	c.entries[key] = c.recency.PushFront(&lruEntry[K, V]{
+		key:       key,
+		value:     value,
+		storedAt:  now,
+		expiresAt: now.Add(ttl),
+	})
+	for c.recency.Len() > c.maxEntries {
+		c.remove(c.recency.Back())
+		c.stats.Evictions++
+	}
+}
+
+// removeWhere discards every entry whose key matches, and returns the number of entries discarded.
+func (c *lruCache[K, V]) removeWhere(match func(K) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	discarded := 0
+	for key, element := range c.entries {
+		if match(key) {
+			c.remove(element)
+			discarded++
+		}
+	}
+	return discarded
+}
+
+// clear discards every entry and returns the number of entries discarded.
+func (c *lruCache[K, V]) clear() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	discarded := len(c.entries)
+	c.entries = make(map[K]*list.Element)
+	c.recency.Init()
+	return discarded
+}
+
+// snapshot returns counts of the lookups made since the lruCache was created.
+func (c *lruCache[K, V]) snapshot() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Entries = len(c.entries)
+	return stats
+}
+
+// remove discards the supplied entry.  The caller must hold the lock.
+func (c *lruCache[K, V]) remove(element *list.Element) {
+	delete(c.entries, element.Value.(*lruEntry[K, V]).key)
+	c.recency.Remove(element)
+}
@@ -0,0 +1,140 @@
+package forex
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// maxSuggestions is the number of closest known countries suggested when a country cannot be matched.
+const maxSuggestions = 3
+
+// countryAliases maps other names by which countries are commonly known, in normalised form (see normaliseCountry),
+// to the names used by the Treasury dataset.  An alias is only used if the country it maps to is known.
+var countryAliases = map[string]string{
+	"uk":                         "United Kingdom",
+	"u k":                        "United Kingdom",
+	"gb":                         "United Kingdom",
+	"great britain":              "United Kingdom",
+	"britain":                    "United Kingdom",
+	"england":                    "United Kingdom",
+	"south korea":                "Korea",
+	"korea south":                "Korea",
+	"korea republic of":          "Korea",
+	"republic of korea":          "Korea",
+	"eurozone":                   "Euro Zone",
+	"euro area":                  "Euro Zone",
+	"czechia":                    "Czech Republic",
+	"holland":                    "Netherlands",
+	"the netherlands":            "Netherlands",
+	"uae":                        "United Arab Emirates",
+	"russian federation":         "Russia",
+	"prc":                        "China",
+	"peoples republic of china":  "China",
+	"people s republic of china": "China",
+	"mainland china":             "China",
+	"hong kong sar":              "Hong Kong",
+	"viet nam":                   "Vietnam",
+	"turkiye":                    "Turkey",
+	"myanmar":                    "Burma",
+	"nz":                         "New Zealand",
+}
+
+// CountryMatch is the result of matching the name of a country against the known countries.
+type CountryMatch struct {
+	// Country is the name used by the Treasury dataset of the matched country, or is empty if there is no match.
+	Country string
+
+	// Suggestions holds the names of the known countries closest to an unmatched name, closest first.
+	Suggestions []string
+}
+
+// matchCountry matches the supplied name of a country against the supplied known countries, which are keyed by their
+// normalised names.  The name matches a known country if it is the same once normalised, so ignoring case and
+// punctuation, or if it is an alias of one (see countryAliases).  Otherwise the known countries within a small edit
+// distance of the name are suggested, closest first.
+func matchCountry(name string, known map[string]string) CountryMatch {
+	normalised := normaliseCountry(name)
+	if country, ok := known[normalised]; ok {
+		return CountryMatch{Country: country}
+	}
+	if alias, ok := countryAliases[normalised]; ok {
+		if country, ok := known[normaliseCountry(alias)]; ok {
+			return CountryMatch{Country: country}
+		}
+	}
+	return CountryMatch{Suggestions: suggestCountries(normalised, known)}
+}
+
+// suggestCountries returns the known countries closest to the supplied normalised name by edit distance, closest first
+// and then in alphabetical order, leaving out those too far from the name to be a likely misspelling of it.
+func suggestCountries(normalised string, known map[string]string) []string {
+	type candidate struct {
+		country  string
+		distance int
+	}
+	maxDistance := len([]rune(normalised)) / 2
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+	var candidates []candidate
+	for knownNormalised, country := range known {
+		if distance := editDistance(normalised, knownNormalised); distance <= maxDistance {
+			candidates = append(candidates, candidate{country: country, distance: distance})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].country < candidates[j].country
+	})
+	var suggestions []string
+	for n := 0; n < len(candidates) && n < maxSuggestions; n++ {
+		suggestions = append(suggestions, candidates[n].country)
+	}
+	return suggestions
+}
+
+// normaliseCountry returns the supplied name of a country in lower case, with anything other than letters and digits
+// treated as a space and runs of spaces collapsed, e.g. 'Korea, South' becomes 'korea south'.
+func normaliseCountry(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
+
+// editDistance returns the Levenshtein distance between the supplied strings: the least number of single character
+// insertions, deletions and substitutions needed to turn one into the other.
+func editDistance(a, b string) int {
+	source, target := []rune(a), []rune(b)
+	previous := make([]int, len(target)+1)
+	current := make([]int, len(target)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(source); i++ {
+		current[0] = i
+		for j := 1; j <= len(target); j++ {
+			substitution := previous[j-1]
+			if source[i-1] != target[j-1] {
+				substitution++
+			}
+			current[j] = minOf(substitution, previous[j]+1, current[j-1]+1)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(target)]
+}
+
+// minOf returns the least of the supplied ints.
+func minOf(first int, others ...int) int {
+	least := first
+	for _, other := range others {
+		if other < least {
+			least = other
+		}
+	}
+	return least
+}
@@ -0,0 +1,131 @@
+package forex
+
+import (
+	"context"
+	"time"
+)
+
+// RatePolicy determines which of the exchange rate records near the date of a conversion is used for it.
+type RatePolicy string
+
+const (
+	// LatestPolicy selects the newest record no older than the window before the date, even if it is dated after it.
+	LatestPolicy RatePolicy = "LATEST"
+
+	// LatestOnOrBeforePolicy selects the newest record dated on or before the date, within the window before it.
+	LatestOnOrBeforePolicy RatePolicy = "LATEST_ON_OR_BEFORE"
+
+	// NearestPolicy selects the record dated nearest the date, within the window either side of it.  Of two records
+	// equally near, the earlier is selected.
+	NearestPolicy RatePolicy = "NEAREST"
+
+	// WithinQuarterPolicy selects the newest record dated on or before the date and in the same calendar quarter as it,
+	// within the window before it.
+	WithinQuarterPolicy RatePolicy = "WITHIN_QUARTER"
+)
+
+// MinRateWindowMonths and MaxRateWindowMonths bound the window that a request may select exchange rate records from.
+const (
+	MinRateWindowMonths = 1
+	MaxRateWindowMonths = 24
+)
+
+// RatePolicies returns every supported RatePolicy, for validating requests.
+func RatePolicies() []string {
+	return []string{
+		string(LatestPolicy),
+		string(LatestOnOrBeforePolicy),
+		string(NearestPolicy),
+		string(WithinQuarterPolicy),
+	}
+}
+
+// DefaultRateRules are the six month window and LatestPolicy by which exchange rate records have always been selected.
+var DefaultRateRules = RateRules{Policy: LatestPolicy, WindowMonths: 6}
+
+// RateRules are the rules by which the exchange rate record used for a conversion is selected.
+type RateRules struct {
+	// Policy determines which of the records within the window is selected.
+	Policy RatePolicy
+
+	// WindowMonths is how many months from the date of the conversion a record may be dated.
+	WindowMonths int
+}
+
+// overriddenBy returns the rules with each of the non-zero fields of the supplied rules in place of its own.
+func (r RateRules) overriddenBy(overrides RateRules) RateRules {
+	if overrides.Policy != "" {
+		r.Policy = overrides.Policy
+	}
+	if overrides.WindowMonths != 0 {
+		r.WindowMonths = overrides.WindowMonths
+	}
+	return r
+}
+
+// RateSelection describes the exchange rate record to use for a conversion.
+type RateSelection struct {
+	// Date is the date as at which the amount is converted, such as the date of a transaction.
+	Date time.Time
+
+	// Rules overrides the default rules of the RepositoryService.  Zero fields are left as the defaults.
+	Rules RateRules
+}
+
+// findRecord returns the exchange rate record of the supplied country selected by the supplied rules, or an empty
+// Record if there is none.  Records are found through the Repository under the LatestPolicy, whereas the other
+// policies look through the records of the window found by the HistoryRepository.  Either may be cached and fall back
+// to the last records known as configured (see CachingRepository and StaleIfErrorRepository).
+func (s *RepositoryService) findRecord(ctx context.Context, country string, date time.Time, rules RateRules) (Record, error) {
+	oldest := date.AddDate(0, -rules.WindowMonths, 0)
+	switch rules.Policy {
+	case LatestOnOrBeforePolicy:
+		return s.findLatestBetween(ctx, country, oldest, date)
+	case WithinQuarterPolicy:
+		if start := quarterStart(date); start.After(oldest) {
+			oldest = start
+		}
+		return s.findLatestBetween(ctx, country, oldest, date)
+	case NearestPolicy:
+		records, err := s.history.FindByCountryBetween(ctx, country, oldest, date.AddDate(0, rules.WindowMonths, 0))
+		if err != nil {
+			return Record{}, err
+		}
+		return nearestRecord(records, date), nil
+	default:
+		return s.repository.FindByCountry(ctx, country, oldest)
+	}
+}
+
+// findLatestBetween returns the newest exchange rate record of the supplied country dated on or between the supplied
+// dates, or an empty Record if there is none.
+func (s *RepositoryService) findLatestBetween(ctx context.Context, country string, from, to time.Time) (Record, error) {
+	records, err := s.history.FindByCountryBetween(ctx, country, from, to)
+	if err != nil || len(records) == 0 {
+		return Record{}, err
+	}
+	return records[len(records)-1], nil
+}
+
+// nearestRecord returns the record of those supplied, ordered by record date, dated nearest the supplied date,
+// preferring the earlier of two equally near.  An empty Record is returned if none are supplied.
+func nearestRecord(records []Record, date time.Time) Record {
+	var nearest Record
+	var nearestDistance time.Duration
+	for _, record := range records {
+		distance := record.RecordDate.Sub(date)
+		if distance < 0 {
+			distance = -distance
+		}
+		if nearest == (Record{}) || distance < nearestDistance {
+			nearest, nearestDistance = record, distance
+		}
+	}
+	return nearest
+}
+
+// quarterStart returns the first day of the calendar quarter of the supplied date.
+func quarterStart(date time.Time) time.Time {
+	firstMonth := time.Month((int(date.Month())-1)/3*3 + 1)
+	return time.Date(date.Year(), firstMonth, 1, 0, 0, 0, 0, date.Location())
+}
@@ -0,0 +1,148 @@
+package forex
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"transaction-service/internal/business"
+	"transaction-service/internal/validation"
+)
+
+const (
+	amountInCentsFieldName    = "amountInCents"
+	dateFieldName             = "date"
+	ratePolicyFieldName       = "ratePolicy"
+	rateWindowMonthsFieldName = "rateWindowMonths"
+)
+
+// QuoteRequest represents the user's request to convert an amount without storing a transaction.
+type QuoteRequest struct {
+	// AmountInCents is the amount in US dollars to convert.
+	AmountInCents *int `json:"amountInCents"`
+
+	// Date is the date as at which to convert the amount, which may not be in the future.
+	Date *string `json:"date"`
+
+	// Country is the country to whose currency the amount is converted.
+	Country *string `json:"country"`
+
+	// RatePolicy and RateWindowMonths override the configured rules by which the exchange rate is selected, if
+	// supplied.
+	RatePolicy       *string `json:"ratePolicy"`
+	RateWindowMonths *int    `json:"rateWindowMonths"`
+}
+
+// QuoteResponse represents the response for a 'convert amount' operation.
+type QuoteResponse struct {
+	AmountInCents          int `json:"amountInCents"`
+	ConvertedAmountInCents int `json:"convertedAmountInCents"`
+
+	// ExchangeRate is the exchange rate used for the conversion, written exactly as recorded, e.g. 1.495.
+	ExchangeRate json.Number `json:"exchangeRate"`
+
+	// RateDate is the record date of the exchange rate used for the conversion.
+	RateDate string `json:"rateDate"`
+
+	// CurrencyCode is the ISO 4217 code of the currency converted to, or is omitted if it is not known.
+	CurrencyCode string `json:"currencyCode,omitempty"`
+
+	// RoundingMode is how the converted amount was rounded to a whole cent.
+	RoundingMode RoundingMode `json:"roundingMode,omitempty"`
+}
+
+// CountryConverter defines the interface expected of the service that converts amounts to the currency of a country,
+// such as the RepositoryService.
+type CountryConverter interface {
+	Convert(ctx context.Context, country string, selection RateSelection, amountInCents int) (ConversionResult, error)
+}
+
+// NewQuoteService creates a QuoteService that converts amounts using the supplied converter.
+func NewQuoteService(converter CountryConverter) *QuoteService {
+	return &QuoteService{
+		converter: converter,
+		validator: quoteValidator{},
+	}
+}
+
+// QuoteService is the business service for converting amounts before any transaction exists, for instance to quote a
+// price at a checkout.
+type QuoteService struct {
+	converter CountryConverter
+	validator quoteValidator
+}
+
+// Quote converts the requested amount to the currency of the requested country, using an exchange rate selected as at
+// the requested date, as when fetching a transaction.  If the input does not satisfy the business rules, or no suitable
+// exchange rate can be found, an error will be returned.
+func (s *QuoteService) Quote(ctx context.Context, request QuoteRequest) (QuoteResponse, error) {
+	if err := s.validator.validate(request); err != nil {
+		return QuoteResponse{}, err
+	}
+	date, _ := time.Parse(validation.DateFormat, *request.Date)
+	selection := RateSelection{Date: date}
+	if request.RatePolicy != nil {
+		selection.Rules.Policy = RatePolicy(*request.RatePolicy)
+	}
+	if request.RateWindowMonths != nil {
+		selection.Rules.WindowMonths = *request.RateWindowMonths
+	}
+	result, err := s.converter.Convert(ctx, *request.Country, selection, *request.AmountInCents)
+	if err != nil {
+		return QuoteResponse{}, err
+	}
+	return QuoteResponse{
+		AmountInCents:          *request.AmountInCents,
+		ConvertedAmountInCents: result.Amount,
+		ExchangeRate:           json.Number(result.ExchangeRate.Value),
+		RateDate:               result.RateDate.Format(dateFormat),
+		CurrencyCode:           result.CurrencyCode,
+		RoundingMode:           result.RoundingMode,
+	}, nil
+}
+
+// quoteValidator is responsible for validating input of the 'convert amount' operation.
+type quoteValidator struct{}
+
+// validate performs business validation on the supplied QuoteRequest.
+func (v quoteValidator) validate(request QuoteRequest) error {
+	var fieldErrors []business.FieldError
+	if err := validation.IsRequiredInt(amountInCentsFieldName, request.AmountInCents); err != nil {
+		fieldErrors = append(fieldErrors, *err)
+	}
+	if err := validation.IsNotZero(amountInCentsFieldName, request.AmountInCents); err != nil {
+		fieldErrors = append(fieldErrors, *err)
+	}
+	if err := validation.IsRequiredString(dateFieldName, request.Date); err != nil {
+		fieldErrors = append(fieldErrors, *err)
+	}
+	date, dateErr := validation.IsDate(dateFieldName, request.Date)
+	if dateErr != nil {
+		fieldErrors = append(fieldErrors, *dateErr)
+	}
+	if err := validation.IsDateNowOrEarlier(dateFieldName, date); err != nil {
+		fieldErrors = append(fieldErrors, *err)
+	}
+	if err := validation.IsRequiredString(countryFieldName, request.Country); err != nil {
+		fieldErrors = append(fieldErrors, *err)
+	}
+	if err := validation.IsMinLength(countryFieldName, request.Country, countryMinLength); err != nil {
+		fieldErrors = append(fieldErrors, *err)
+	}
+	if err := validation.IsOneOf(ratePolicyFieldName, request.RatePolicy, RatePolicies()); err != nil {
+		fieldErrors = append(fieldErrors, *err)
+	}
+	if err := validation.IsMinValue(rateWindowMonthsFieldName, request.RateWindowMonths, MinRateWindowMonths); err != nil {
+		fieldErrors = append(fieldErrors, *err)
+	}
+	if err := validation.IsMaxValue(rateWindowMonthsFieldName, request.RateWindowMonths, MaxRateWindowMonths); err != nil {
+		fieldErrors = append(fieldErrors, *err)
+	}
+	if len(fieldErrors) > 0 {
+		return &business.Error{
+			Message: validationErrorMessage,
+			Fields:  fieldErrors,
+		}
+	}
+	return nil
+}
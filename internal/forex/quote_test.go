@@ -0,0 +1,186 @@
+package forex_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"transaction-service/internal/business"
+	"transaction-service/internal/date"
+	"transaction-service/internal/forex"
+)
+
+func TestQuoteService(t *testing.T) {
+	ctx := context.Background()
+	str := func(value string) *string { return &value }
+	intPtr := func(value int) *int { return &value }
+	setUp := func() (*forex.QuoteService, *MockCountryConverter) {
+		mockConverter := &MockCountryConverter{}
+		return forex.NewQuoteService(mockConverter), mockConverter
+	}
+
+	t.Run("success", func(t *testing.T) {
+		t.Run("should convert the amount using an exchange rate selected as at the date", func(t *testing.T) {
+			service, mockConverter := setUp()
+			mockConverter.On("Convert", ctx, "United Kingdom", forex.RateSelection{Date: date.NewInUTC(2023, time.May, 1)}, 12345).
+				Return(forex.ConversionResult{
+					Amount:       10024,
+					ExchangeRate: forex.ExchangeRate{Value: "0.812"},
+					RateDate:     date.NewInUTC(2023, time.March, 31),
+					CurrencyCode: "GBP",
+					RoundingMode: forex.HalfEvenRounding,
+				}, nil)
+
+			response, err := service.Quote(ctx, forex.QuoteRequest{
+				AmountInCents: intPtr(12345),
+				Date:          str("2023-05-01"),
+				Country:       str("United Kingdom"),
+			})
+
+			assert.Nil(t, err)
+			assert.Equal(t, forex.QuoteResponse{
+				AmountInCents:          12345,
+				ConvertedAmountInCents: 10024,
+				ExchangeRate:           "0.812",
+				RateDate:               "2023-03-31",
+				CurrencyCode:           "GBP",
+				RoundingMode:           forex.HalfEvenRounding,
+			}, response)
+			mockConverter.AssertExpectations(t)
+		})
+		t.Run("should select the exchange rate by the rules supplied with the request", func(t *testing.T) {
+			service, mockConverter := setUp()
+			mockConverter.On("Convert", ctx, "United Kingdom", forex.RateSelection{
+				Date:  date.NewInUTC(2023, time.May, 1),
+				Rules: forex.RateRules{Policy: forex.NearestPolicy, WindowMonths: 3},
+			}, 12345).Return(forex.ConversionResult{Amount: 10024}, nil)
+
+			_, err := service.Quote(ctx, forex.QuoteRequest{
+				AmountInCents:    intPtr(12345),
+				Date:             str("2023-05-01"),
+				Country:          str("United Kingdom"),
+				RatePolicy:       str("NEAREST"),
+				RateWindowMonths: intPtr(3),
+			})
+
+			assert.Nil(t, err)
+			mockConverter.AssertExpectations(t)
+		})
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		tcs := []struct {
+			name    string
+			request forex.QuoteRequest
+			wantErr error
+		}{
+			{
+				name:    "should return a validation error when required fields are missing",
+				request: forex.QuoteRequest{},
+				wantErr: &business.Error{
+					Message: "VALIDATION_ERROR",
+					Fields: []business.FieldError{
+						{FieldName: "amountInCents", Reason: "REQUIRED"},
+						{FieldName: "date", Reason: "REQUIRED"},
+						{FieldName: "country", Reason: "REQUIRED"},
+					},
+				},
+			},
+			{
+				name: "should return a validation error when fields are invalid",
+				request: forex.QuoteRequest{
+					AmountInCents: intPtr(0),
+					Date:          str("01/05/2023"),
+					Country:       str("a"),
+				},
+				wantErr: &business.Error{
+					Message: "VALIDATION_ERROR",
+					Fields: []business.FieldError{
+						{FieldName: "amountInCents", Reason: "ZERO_VALUE"},
+						{FieldName: "date", Reason: "DATE_BAD_FORMAT"},
+						{FieldName: "country", Reason: "MIN_LENGTH"},
+					},
+				},
+			},
+			{
+				name: "should return a validation error when the exchange rate rules are not supported",
+				request: forex.QuoteRequest{
+					AmountInCents:    intPtr(100),
+					Date:             str("2023-05-01"),
+					Country:          str("United Kingdom"),
+					RatePolicy:       str("OLDEST"),
+					RateWindowMonths: intPtr(25),
+				},
+				wantErr: &business.Error{
+					Message: "VALIDATION_ERROR",
+					Fields: []business.FieldError{
+						{FieldName: "ratePolicy", Reason: "UNSUPPORTED_VALUE"},
+						{FieldName: "rateWindowMonths", Reason: "MAX_VALUE"},
+					},
+				},
+			},
+			{
+				name: "should return a validation error when the date is in the future",
+				request: forex.QuoteRequest{
+					AmountInCents: intPtr(100),
+					Date:          str(time.Now().AddDate(0, 0, 2).Format("2006-01-02")),
+					Country:       str("United Kingdom"),
+				},
+				wantErr: &business.Error{
+					Message: "VALIDATION_ERROR",
+					Fields:  []business.FieldError{{FieldName: "date", Reason: "DATE_IN_FUTURE"}},
+				},
+			},
+		}
+		for _, tc := range tcs {
+			t.Run(tc.name, func(t *testing.T) {
+				service, mockConverter := setUp()
+
+				response, err := service.Quote(ctx, tc.request)
+
+				assert.Equal(t, tc.wantErr, err)
+				assert.Equal(t, forex.QuoteResponse{}, response)
+				mockConverter.AssertExpectations(t)
+			})
+		}
+		t.Run("should return the error when the amount cannot be converted", func(t *testing.T) {
+			service, mockConverter := setUp()
+			mockConverter.On("Convert", ctx, "Atlantis", mock.Anything, 100).
+				Return(forex.ConversionResult{}, &business.Error{Message: "UNKNOWN_COUNTRY"})
+
+			_, err := service.Quote(ctx, forex.QuoteRequest{
+				AmountInCents: intPtr(100),
+				Date:          str("2023-05-01"),
+				Country:       str("Atlantis"),
+			})
+
+			assert.Equal(t, &business.Error{Message: "UNKNOWN_COUNTRY"}, err)
+		})
+		t.Run("should return the error when there is a system problem converting the amount", func(t *testing.T) {
+			service, mockConverter := setUp()
+			mockConverter.On("Convert", ctx, "United Kingdom", mock.Anything, 100).
+				Return(forex.ConversionResult{}, errors.New("problem"))
+
+			_, err := service.Quote(ctx, forex.QuoteRequest{
+				AmountInCents: intPtr(100),
+				Date:          str("2023-05-01"),
+				Country:       str("United Kingdom"),
+			})
+
+			assert.EqualError(t, err, "problem")
+		})
+	})
+}
+
+type MockCountryConverter struct {
+	mock.Mock
+}
+
+func (m *MockCountryConverter) Convert(ctx context.Context, country string, selection forex.RateSelection, amountInCents int) (forex.ConversionResult, error) {
+	args := m.Called(ctx, country, selection, amountInCents)
+	return args.Get(0).(forex.ConversionResult), args.Error(1)
+}
@@ -1,13 +1,25 @@
 package forex
 
 import (
+	"fmt"
+	"math/big"
+	"regexp"
 	"strconv"
 	"time"
 )
 
+// decimalPattern matches the text form of an exchange rate, e.g. '1.495'.  Exchange rates are never negative.
+var decimalPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
 // APIResponse represents the response received from the Treasury Exchange Rate API.
 type APIResponse struct {
 	Data []Record `json:"data"`
+	Meta APIMeta  `json:"meta"`
+}
+
+// APIMeta represents the pagination details of the response received from the Treasury Exchange Rate API.
+type APIMeta struct {
+	TotalPages int `json:"total-pages"`
 }
 
 // RecordDate is used to help parse the record_date field from the APIResponse.
@@ -15,29 +27,62 @@ type RecordDate struct {
 	time.Time
 }
 
-// ExchangeRate is used to help parse the exchange_rate field from the APIResponse.
+// ExchangeRate is used to help parse the exchange_rate field from the APIResponse.  The rate is kept exactly as it was
+// written, rather than as a float64, which cannot represent most decimal rates exactly.  It is only validated once it is
+// used (see Rat), so that a record with a malformed rate does not prevent the other records of a response or file from
+// being read.
 type ExchangeRate struct {
-	Value float64
+	// Value is the text form of the rate, e.g. '1.495'.
+	Value string
+}
+
+// Rat returns the exact value of the exchange rate, or returns an error if it is not a valid exchange rate (see parse),
+// for instance because it has not been set.
+func (r ExchangeRate) Rat() (*big.Rat, error) {
+	return parseRate(r.Value)
 }
 
-// UnmarshalJSON is a custom json deserialization implementation to read a float from the exchange_rate field.
+// UnmarshalJSON is a custom json deserialization implementation to read the text of the exchange_rate field.
 func (r *ExchangeRate) UnmarshalJSON(bytes []byte) error {
 	unquotedValue, err := strconv.Unquote(string(bytes))
 	if err != nil {
 		return err
 	}
-	value, err := strconv.ParseFloat(unquotedValue, 64)
-	if err != nil {
-		return err
-	}
-	r.Value = value
+	r.Value = unquotedValue
 	return nil
 }
 
+// parseRate returns the exact value of the supplied text form of an exchange rate, or returns an error if it is not a
+// plain decimal number greater than zero.
+func parseRate(value string) (*big.Rat, error) {
+	if !decimalPattern.MatchString(value) {
+		return nil, fmt.Errorf("invalid exchange rate %q", value)
+	}
+	rate, _ := new(big.Rat).SetString(value)
+	if rate.Sign() == 0 {
+		return nil, fmt.Errorf("invalid exchange rate %q: must be greater than zero", value)
+	}
+	return rate, nil
+}
+
 // Record represents an exchange rate record received from the Treasury API
 type Record struct {
+	Country      string       `json:"country"`
+	Currency     string       `json:"currency"`
 	RecordDate   RecordDate   `json:"record_date"`
 	ExchangeRate ExchangeRate `json:"exchange_rate"`
+
+	// Fallback is set on a record returned in place of a failed lookup, see StaleIfErrorRepository.
+	Fallback *Fallback `json:"-"`
+}
+
+// Fallback describes a record returned in place of a failed lookup, being the last one known.
+type Fallback struct {
+	// FetchedAt is when the record was last found by a successful lookup.
+	FetchedAt time.Time
+
+	// Age is how long before the failed lookup the record was last found.
+	Age time.Duration
 }
 
 // UnmarshalJSON is a custom json deserialization implementation to read a date from the record_date field.
@@ -46,7 +91,12 @@ func (d *RecordDate) UnmarshalJSON(bytes []byte) error {
 	if err != nil {
 		return err
 	}
-	date, err := time.Parse(dateFormat, unquotedValue)
+	return d.parse(unquotedValue)
+}
+
+// parse reads the record date from its text form, e.g. '2023-03-31'.
+func (d *RecordDate) parse(value string) error {
+	date, err := time.Parse(dateFormat, value)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,65 @@
+package forex
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// newRefresher creates a refresher that has not yet been started.
+func newRefresher() *refresher {
+	return &refresher{stop: make(chan struct{})}
+}
+
+// refresher refreshes something held in memory, such as the TableRepository, on a schedule in the background.
+type refresher struct {
+	stop     chan struct{}
+	stopOnce sync.Once
+	running  sync.WaitGroup
+}
+
+// start calls the supplied refresh straight away and then every interval in the background, until the refresher is
+// closed.  Failed refreshes are logged, using the supplied description of what is refreshed, and retried at the next
+// interval.
+func (r *refresher) start(interval time.Duration, what string, refresh func(ctx context.Context) error) {
+	r.running.Add(1)
+	go func() {
+		defer r.running.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			r.refreshInBackground(interval, what, refresh)
+			select {
+			case <-ticker.C:
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+}
+
+// refreshInBackground calls the supplied refresh, giving up should it take longer than the supplied interval or should
+// the refresher be closed.
+func (r *refresher) refreshInBackground(interval time.Duration, what string, refresh func(ctx context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), interval)
+	defer cancel()
+	go func() {
+		select {
+		case <-r.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	if err := refresh(ctx); err != nil {
+		log.Printf("unable to refresh %s: %v\n", what, err)
+	}
+}
+
+// close stops refreshing in the background, waiting for a refresh in progress to stop.
+func (r *refresher) close() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+	r.running.Wait()
+}
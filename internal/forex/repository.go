@@ -11,10 +11,14 @@ import (
 )
 
 const (
-	baseURL    = "https://api.fiscaldata.treasury.gov/services/api/fiscal_service/v1/accounting/od/rates_of_exchange?sort=-record_date&format=json"
+	datasetURL = "https://api.fiscaldata.treasury.gov/services/api/fiscal_service/v1/accounting/od/rates_of_exchange"
+	baseURL    = datasetURL + "?sort=-record_date&format=json"
 	pageSize   = 1
 	pageNumber = 1
 	dateFormat = "2006-01-02"
+
+	// datasetPageSize is the number of records fetched per page when fetching the whole dataset.
+	datasetPageSize = 5000
 )
 
 type HttpClient interface {
@@ -37,29 +41,65 @@ type TreasuryRepository struct {
 // FindByCountry returns the most recent foreign exchange record for the specified country that is not older than the
 // specified dateOfOldestRecord.
 func (r *TreasuryRepository) FindByCountry(ctx context.Context, country string, dateOfOldestRecord time.Time) (Record, error) {
-	request, err := http.NewRequestWithContext(ctx, http.MethodGet, newURL(country, dateOfOldestRecord), nil)
+	unmarshalled, err := r.get(ctx, newURL(country, dateOfOldestRecord))
 	if err != nil {
 		return Record{}, err
 	}
+	if len(unmarshalled.Data) <= 0 {
+		return Record{}, nil
+	}
+	return unmarshalled.Data[0], nil
+}
+
+// FindAll returns every record in the dataset, ordered by record date and then by country, fetching it a page at a
+// time.
+func (r *TreasuryRepository) FindAll(ctx context.Context) ([]Record, error) {
+	return r.getAllPages(ctx, newDatasetURL)
+}
+
+// FindByCountryBetween returns every foreign exchange record for the specified country recorded on or between the
+// specified from and to dates, ordered by record date, fetching them a page at a time.
+func (r *TreasuryRepository) FindByCountryBetween(ctx context.Context, country string, from, to time.Time) ([]Record, error) {
+	return r.getAllPages(ctx, func(page int) string {
+		return newRangeURL(country, from, to, page)
+	})
+}
+
+// getAllPages calls the Treasury API for each page of a query in turn, using the url returned for each page number by
+// the supplied pageURL, until the last page has been fetched.  The records of every page are returned together.
+func (r *TreasuryRepository) getAllPages(ctx context.Context, pageURL func(page int) string) ([]Record, error) {
+	var records []Record
+	for page := 1; ; page++ {
+		unmarshalled, err := r.get(ctx, pageURL(page))
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, unmarshalled.Data...)
+		if page >= unmarshalled.Meta.TotalPages {
+			return records, nil
+		}
+	}
+}
+
+// get calls the Treasury API with the supplied url, returning the APIResponse or an error if the response does not
+// have a 200 http status.
+func (r *TreasuryRepository) get(ctx context.Context, url string) (APIResponse, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return APIResponse{}, err
+	}
 	response, err := r.httpClient.Do(request)
 	if err != nil {
-		return Record{}, err
+		return APIResponse{}, err
 	}
 	if response.StatusCode != http.StatusOK {
 		body, err := read(response.Body)
 		if err != nil {
-			return Record{}, err
+			return APIResponse{}, err
 		}
-		return Record{}, fmt.Errorf("http status %d received from treasury api. response body: %s", response.StatusCode, string(body))
-	}
-	unmarshalled, err := parseResponse(response, err)
-	if err != nil {
-		return Record{}, err
+		return APIResponse{}, fmt.Errorf("http status %d received from treasury api. response body: %s", response.StatusCode, string(body))
 	}
-	if len(unmarshalled.Data) <= 0 {
-		return Record{}, nil
-	}
-	return unmarshalled.Data[0], nil
+	return parseResponse(response, err)
 }
 
 // parseResponse reads the http.Response into an APIResponse or returns an error.
@@ -81,6 +121,19 @@ func read(closer io.ReadCloser) ([]byte, error) {
 	return io.ReadAll(closer)
 }
 
+// newDatasetURL creates the url to use to call the Treasury API for the supplied page of the whole dataset.
+func newDatasetURL(page int) string {
+	return fmt.Sprintf("%s?sort=record_date,country&format=json&fields=country,currency,exchange_rate,record_date&page[size]=%d&page[number]=%d",
+		datasetURL, datasetPageSize, page)
+}
+
+// newRangeURL creates the url to use to call the Treasury API for the supplied page of the records of the supplied
+// country recorded on or between the supplied dates.
+func newRangeURL(country string, from, to time.Time, page int) string {
+	return fmt.Sprintf("%s?sort=record_date&format=json&filter=record_date:gte:%s,record_date:lte:%s,country:eq:%s&page[size]=%d&page[number]=%d",
+		datasetURL, from.Format(dateFormat), to.Format(dateFormat), url.QueryEscape(country), datasetPageSize, page)
+}
+
 // newURL creates the url to use to call the Treasury API, using the suppliec country and date of oldest record
 func newURL(country string, dateOfOldestRecord time.Time) string {
 	return fmt.Sprintf("%s&filter=record_date:gte:%s,country:eq:%s&page[size]=%d&page[number]=%d",
@@ -44,7 +44,7 @@ func TestRepository(t *testing.T) {
 			assert.Nil(t, err)
 			expected := forex.Record{
 				RecordDate:   forex.RecordDate{Time: date.NewInUTC(2020, time.August, 01)},
-				ExchangeRate: forex.ExchangeRate{Value: 0.345},
+				ExchangeRate: forex.ExchangeRate{Value: "0.345"},
 			}
 			assert.Equal(t, expected, result)
 		})
@@ -60,6 +60,94 @@ func TestRepository(t *testing.T) {
 		})
 	})
 
+	t.Run("find all", func(t *testing.T) {
+		t.Run("should fetch every page of the dataset", func(t *testing.T) {
+			setUpRepository()
+			httpClient.AddCannedResponse(http.StatusOK, `{
+				"data": [{"country": "Australia", "record_date": "2023-03-31", "exchange_rate": "1.495"}],
+				"meta": {"total-pages": 2}
+			}`)
+			httpClient.AddCannedResponse(http.StatusOK, `{
+				"data": [{"country": "United Kingdom", "record_date": "2023-03-31", "exchange_rate": "0.812"}],
+				"meta": {"total-pages": 2}
+			}`)
+
+			records, err := repository.(*forex.TreasuryRepository).FindAll(context.Background())
+			assert.Nil(t, err)
+			assert.Equal(t, []forex.Record{
+				{
+					Country:      "Australia",
+					RecordDate:   forex.RecordDate{Time: date.NewInUTC(2023, time.March, 31)},
+					ExchangeRate: forex.ExchangeRate{Value: "1.495"},
+				},
+				{
+					Country:      "United Kingdom",
+					RecordDate:   forex.RecordDate{Time: date.NewInUTC(2023, time.March, 31)},
+					ExchangeRate: forex.ExchangeRate{Value: "0.812"},
+				},
+			}, records)
+			assert.Len(t, httpClient.Requests, 2)
+			assert.Equal(t, "https://api.fiscaldata.treasury.gov/services/api/fiscal_service/v1/accounting/od/rates_of_exchange?sort=record_date,country&format=json&fields=country,currency,exchange_rate,record_date&page[size]=5000&page[number]=2", httpClient.Request.URL.String())
+		})
+		t.Run("should read a page holding a record whose exchange rate is not valid", func(t *testing.T) {
+			setUpRepository()
+			httpClient.AddCannedResponse(http.StatusOK, `{
+				"data": [
+					{"country": "Australia", "record_date": "2023-03-31", "exchange_rate": "0.000"},
+					{"country": "United Kingdom", "record_date": "2023-03-31", "exchange_rate": "0.812"}
+				],
+				"meta": {"total-pages": 1}
+			}`)
+
+			records, err := repository.(*forex.TreasuryRepository).FindAll(context.Background())
+			assert.Nil(t, err)
+			assert.Len(t, records, 2)
+			assert.Equal(t, forex.ExchangeRate{Value: "0.000"}, records[0].ExchangeRate)
+		})
+		t.Run("should return an error when a page cannot be fetched", func(t *testing.T) {
+			setUpRepository()
+			httpClient.AddCannedResponse(http.StatusOK, `{"data": [], "meta": {"total-pages": 2}}`)
+			httpClient.AddCannedResponse(http.StatusServiceUnavailable, `*error-payload*`)
+
+			records, err := repository.(*forex.TreasuryRepository).FindAll(context.Background())
+			assert.EqualError(t, err, "http status 503 received from treasury api. response body: *error-payload*")
+			assert.Nil(t, records)
+		})
+	})
+
+	t.Run("range", func(t *testing.T) {
+		t.Run("should fetch every page of the records of the country recorded between the supplied dates", func(t *testing.T) {
+			setUpRepository()
+			httpClient.AddCannedResponse(http.StatusOK, `{
+				"data": [{"country": "United Kingdom", "record_date": "2022-12-31", "exchange_rate": "0.826"}],
+				"meta": {"total-pages": 2}
+			}`)
+			httpClient.AddCannedResponse(http.StatusOK, `{
+				"data": [{"country": "United Kingdom", "record_date": "2023-03-31", "exchange_rate": "0.812"}],
+				"meta": {"total-pages": 2}
+			}`)
+
+			records, err := repository.(*forex.TreasuryRepository).FindByCountryBetween(context.Background(),
+				"United Kingdom", date.NewInUTC(2022, time.October, 1), date.NewInUTC(2023, time.April, 30))
+			assert.Nil(t, err)
+			assert.Equal(t, []forex.Record{
+				{
+					Country:      "United Kingdom",
+					RecordDate:   forex.RecordDate{Time: date.NewInUTC(2022, time.December, 31)},
+					ExchangeRate: forex.ExchangeRate{Value: "0.826"},
+				},
+				{
+					Country:      "United Kingdom",
+					RecordDate:   forex.RecordDate{Time: date.NewInUTC(2023, time.March, 31)},
+					ExchangeRate: forex.ExchangeRate{Value: "0.812"},
+				},
+			}, records)
+			assert.Len(t, httpClient.Requests, 2)
+			assert.Equal(t, "https://api.fiscaldata.treasury.gov/services/api/fiscal_service/v1/accounting/od/rates_of_exchange?sort=record_date&format=json&filter=record_date:gte:2022-10-01,record_date:lte:2023-04-30,country:eq:United+Kingdom&page[size]=5000&page[number]=1", httpClient.Requests[0].URL.String())
+			assert.Equal(t, "https://api.fiscaldata.treasury.gov/services/api/fiscal_service/v1/accounting/od/rates_of_exchange?sort=record_date&format=json&filter=record_date:gte:2022-10-01,record_date:lte:2023-04-30,country:eq:United+Kingdom&page[size]=5000&page[number]=2", httpClient.Requests[1].URL.String())
+		})
+	})
+
 	t.Run("failure", func(t *testing.T) {
 		setUpRepository()
 		httpClient.SetCannedResponse(http.StatusInternalServerError, `*error-payload*`)
@@ -0,0 +1,266 @@
+package forex
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"transaction-service/internal/health"
+)
+
+// ErrCircuitOpen is returned, without calling the Treasury API, while the circuit breaker is open.
+var ErrCircuitOpen = errors.New("treasury api circuit breaker is open")
+
+// RetryPolicy describes how failed calls are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times a call is attempted before giving up, including the first attempt.
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry, which doubles for each retry after it.  The delay is jittered by
+	// choosing a random delay up to it.
+	BaseDelay time.Duration
+
+	// MaxDelay is the longest delay between attempts, however many retries have been made.
+	MaxDelay time.Duration
+
+	// MaxRetryAfter is the longest delay requested by a Retry-After header that is honoured.  A response asking for a
+	// longer delay is returned rather than retried.
+	MaxRetryAfter time.Duration
+}
+
+// BreakerConfig describes when the circuit breaker opens and for how long.
+type BreakerConfig struct {
+	// FailureThreshold is the number of consecutive failed calls after which the breaker opens.
+	FailureThreshold int
+
+	// OpenDuration is how long the breaker stays open before letting a trial call through.
+	OpenDuration time.Duration
+}
+
+// Circuit breaker states.
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half-open"
+)
+
+// NewResilientHttpClient creates a ResilientHttpClient that makes calls through the supplied HttpClient, retrying
+// according to the supplied RetryPolicy and failing fast according to the supplied BreakerConfig.
+func NewResilientHttpClient(client HttpClient, retry RetryPolicy, breaker BreakerConfig) *ResilientHttpClient {
+	return &ResilientHttpClient{
+		client:  client,
+		retry:   retry,
+		breaker: breaker,
+		now:     time.Now,
+		random:  rand.Float64,
+		wait:    wait,
+		state:   BreakerClosed,
+	}
+}
+
+// ResilientHttpClient is a HttpClient that protects callers from transient problems with the Treasury API.  Calls
+// failing with a network error or a retryable http status (see isRetryable) are retried with exponential backoff and
+// jitter, honouring any Retry-After header.  Once enough consecutive calls have failed, a circuit breaker opens and
+// calls fail straight away with ErrCircuitOpen, so that callers are not kept waiting while the Treasury API is down.
+// After a while a single trial call is let through, closing the breaker again should it succeed.
+type ResilientHttpClient struct {
+	client  HttpClient
+	retry   RetryPolicy
+	breaker BreakerConfig
+	now     func() time.Time
+	random  func() float64
+	wait    func(ctx context.Context, delay time.Duration) error
+
+	mu                  sync.Mutex
+	state               string
+	consecutiveFailures int
+	openedAt            time.Time
+	trialInProgress     bool
+}
+
+// Do makes the call described by the supplied http.Request, retrying it should it fail transiently.  A response with a
+// retryable http status is returned once the attempts are exhausted, so that the caller can report it.  A call
+// abandoned because its context is done is not counted by the circuit breaker.
+func (c *ResilientHttpClient) Do(req *http.Request) (*http.Response, error) {
+	if !c.allow() {
+		return nil, ErrCircuitOpen
+	}
+	response, err := c.doWithRetries(req)
+	if req.Context().Err() != nil {
+		c.abandon()
+		return response, err
+	}
+	c.record(err == nil && !isRetryable(response.StatusCode))
+	return response, err
+}
+
+// doWithRetries makes the call, retrying until it succeeds, fails in a way that is not retryable or the attempts are
+// exhausted.  Requests having a body that cannot be replayed are not retried.
+func (c *ResilientHttpClient) doWithRetries(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	replayable := req.Body == nil || req.GetBody != nil
+	for attempt := 1; ; attempt++ {
+		attemptReq := req.Clone(ctx)
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq.Body = body
+		}
+		response, err := c.client.Do(attemptReq)
+		if ctx.Err() != nil {
+			if response != nil {
+				response.Body.Close()
+			}
+			return nil, ctx.Err()
+		}
+		if err == nil && !isRetryable(response.StatusCode) {
+			return response, nil
+		}
+		if attempt >= c.retry.MaxAttempts || !replayable {
+			return response, err
+		}
+		delay := c.backoff(attempt)
+		if err == nil {
+			if retryAfter, ok := parseRetryAfter(response.Header.Get("Retry-After"), c.now()); ok {
+				if retryAfter > c.retry.MaxRetryAfter {
+					return response, nil
+				}
+				delay = retryAfter
+			}
+			io.Copy(io.Discard, response.Body)
+			response.Body.Close()
+		}
+		if err := c.wait(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// backoff returns the delay before the retry following the supplied attempt: a random duration up to the base delay
+// doubled for each previous retry, capped at the maximum delay.
+func (c *ResilientHttpClient) backoff(attempt int) time.Duration {
+	ceiling := float64(c.retry.BaseDelay) * math.Pow(2, float64(attempt-1))
+	if ceiling > float64(c.retry.MaxDelay) {
+		ceiling = float64(c.retry.MaxDelay)
+	}
+	return time.Duration(c.random() * ceiling)
+}
+
+// allow reports whether the circuit breaker lets a call through.  Once the breaker has been open for long enough it
+// becomes half-open, letting a single trial call through at a time.
+func (c *ResilientHttpClient) allow() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state == BreakerOpen && c.now().Sub(c.openedAt) >= c.breaker.OpenDuration {
+		c.state = BreakerHalfOpen
+	}
+	switch c.state {
+	case BreakerOpen:
+		return false
+	case BreakerHalfOpen:
+		if c.trialInProgress {
+			return false
+		}
+		c.trialInProgress = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record updates the circuit breaker with the outcome of a call.
+func (c *ResilientHttpClient) record(succeeded bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.trialInProgress = false
+	if succeeded {
+		c.state = BreakerClosed
+		c.consecutiveFailures = 0
+		return
+	}
+	c.consecutiveFailures++
+	if c.state == BreakerHalfOpen || c.consecutiveFailures >= c.breaker.FailureThreshold {
+		c.state = BreakerOpen
+		c.openedAt = c.now()
+	}
+}
+
+// abandon releases a trial call without recording its outcome.
+func (c *ResilientHttpClient) abandon() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.trialInProgress = false
+}
+
+// BreakerDetails describes the state of the circuit breaker.
+type BreakerDetails struct {
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	OpenedAt            *time.Time `json:"openedAt,omitempty"`
+}
+
+// Health reports the Treasury API as down while the circuit breaker is open, along with the details of the breaker.
+func (c *ResilientHttpClient) Health() health.Component {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	details := BreakerDetails{
+		State:               c.state,
+		ConsecutiveFailures: c.consecutiveFailures,
+	}
+	status := health.Up
+	if c.state != BreakerClosed {
+		openedAt := c.openedAt
+		details.OpenedAt = &openedAt
+		status = health.Down
+	}
+	return health.Component{Status: status, Details: details}
+}
+
+// isRetryable reports whether a response with the supplied http status may succeed if retried.
+func isRetryable(status int) bool {
+	switch status {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusInternalServerError,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseRetryAfter returns the delay requested by the supplied Retry-After header, which holds either a number of
+// seconds or a http date.  It reports false if there is no header or it cannot be read.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if delay := at.Sub(now); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// wait waits for the supplied delay, returning early with the error of the supplied context should it be done first.
+func wait(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
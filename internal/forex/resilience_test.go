@@ -0,0 +1,215 @@
+package forex
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"transaction-service/internal/health"
+)
+
+func TestResilientHttpClient(t *testing.T) {
+	retry := RetryPolicy{
+		MaxAttempts:   3,
+		BaseDelay:     100 * time.Millisecond,
+		MaxDelay:      150 * time.Millisecond,
+		MaxRetryAfter: 5 * time.Second,
+	}
+	breaker := BreakerConfig{FailureThreshold: 2, OpenDuration: 30 * time.Second}
+	type setUpResult struct {
+		client  *ResilientHttpClient
+		stub    *scriptedHttpClient
+		delays  *[]time.Duration
+		advance func(time.Duration)
+	}
+	setUp := func(outcomes ...outcome) setUpResult {
+		stub := &scriptedHttpClient{outcomes: outcomes}
+		client := NewResilientHttpClient(stub, retry, breaker)
+		now := time.Date(2023, time.May, 1, 12, 0, 0, 0, time.UTC)
+		client.now = func() time.Time { return now }
+		client.random = func() float64 { return 1 }
+		var delays []time.Duration
+		client.wait = func(_ context.Context, delay time.Duration) error {
+			delays = append(delays, delay)
+			return nil
+		}
+		return setUpResult{
+			client:  client,
+			stub:    stub,
+			delays:  &delays,
+			advance: func(d time.Duration) { now = now.Add(d) },
+		}
+	}
+	newRequest := func(ctx context.Context) *http.Request {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.example.com/rates", nil)
+		return req
+	}
+	networkError := errors.New("connection reset")
+
+	t.Run("retry", func(t *testing.T) {
+		t.Run("should return a successful response without retrying", func(t *testing.T) {
+			s := setUp(outcome{status: http.StatusOK})
+
+			response, err := s.client.Do(newRequest(context.Background()))
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+			assert.Equal(t, 1, s.stub.calls)
+		})
+		t.Run("should retry network errors and retryable statuses with exponential backoff up to the maximum delay", func(t *testing.T) {
+			s := setUp(outcome{err: networkError}, outcome{status: http.StatusServiceUnavailable}, outcome{status: http.StatusOK})
+
+			response, err := s.client.Do(newRequest(context.Background()))
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+			assert.Equal(t, 3, s.stub.calls)
+			assert.Equal(t, []time.Duration{100 * time.Millisecond, 150 * time.Millisecond}, *s.delays)
+		})
+		t.Run("should jitter the delay", func(t *testing.T) {
+			s := setUp(outcome{status: http.StatusBadGateway}, outcome{status: http.StatusOK})
+			s.client.random = func() float64 { return 0.25 }
+
+			s.client.Do(newRequest(context.Background()))
+			assert.Equal(t, []time.Duration{25 * time.Millisecond}, *s.delays)
+		})
+		t.Run("should return the last response once the attempts are exhausted", func(t *testing.T) {
+			s := setUp(outcome{status: http.StatusGatewayTimeout})
+
+			response, err := s.client.Do(newRequest(context.Background()))
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusGatewayTimeout, response.StatusCode)
+			assert.Equal(t, 3, s.stub.calls)
+		})
+		t.Run("should not retry a status that is not retryable", func(t *testing.T) {
+			s := setUp(outcome{status: http.StatusBadRequest})
+
+			response, _ := s.client.Do(newRequest(context.Background()))
+			assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+			assert.Equal(t, 1, s.stub.calls)
+		})
+		t.Run("should wait for the delay given in seconds by a Retry-After header", func(t *testing.T) {
+			s := setUp(outcome{status: http.StatusTooManyRequests, retryAfter: "2"}, outcome{status: http.StatusOK})
+
+			s.client.Do(newRequest(context.Background()))
+			assert.Equal(t, []time.Duration{2 * time.Second}, *s.delays)
+		})
+		t.Run("should wait until the date given by a Retry-After header", func(t *testing.T) {
+			s := setUp(outcome{status: http.StatusServiceUnavailable, retryAfter: "Mon, 01 May 2023 12:00:03 GMT"}, outcome{status: http.StatusOK})
+
+			s.client.Do(newRequest(context.Background()))
+			assert.Equal(t, []time.Duration{3 * time.Second}, *s.delays)
+		})
+		t.Run("should not retry when a Retry-After header asks for too long a delay", func(t *testing.T) {
+			s := setUp(outcome{status: http.StatusServiceUnavailable, retryAfter: "60"})
+
+			response, err := s.client.Do(newRequest(context.Background()))
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+			assert.Equal(t, 1, s.stub.calls)
+		})
+		t.Run("should stop retrying once the context is done", func(t *testing.T) {
+			s := setUp(outcome{err: networkError})
+			ctx, cancel := context.WithCancel(context.Background())
+			s.client.wait = func(ctx context.Context, _ time.Duration) error {
+				cancel()
+				return ctx.Err()
+			}
+
+			_, err := s.client.Do(newRequest(ctx))
+			assert.Equal(t, context.Canceled, err)
+			assert.Equal(t, 1, s.stub.calls)
+			assert.Equal(t, 0, s.client.Health().Details.(BreakerDetails).ConsecutiveFailures)
+		})
+	})
+
+	t.Run("circuit breaker", func(t *testing.T) {
+		t.Run("should open after consecutive failures, failing fast without calling", func(t *testing.T) {
+			s := setUp(outcome{status: http.StatusInternalServerError})
+
+			s.client.Do(newRequest(context.Background()))
+			s.client.Do(newRequest(context.Background()))
+			calls := s.stub.calls
+			_, err := s.client.Do(newRequest(context.Background()))
+			assert.Equal(t, ErrCircuitOpen, err)
+			assert.Equal(t, calls, s.stub.calls)
+			openedAt := time.Date(2023, time.May, 1, 12, 0, 0, 0, time.UTC)
+			assert.Equal(t, health.Component{
+				Status:  health.Down,
+				Details: BreakerDetails{State: BreakerOpen, ConsecutiveFailures: 2, OpenedAt: &openedAt},
+			}, s.client.Health())
+		})
+		t.Run("should reset the count of failures after a success", func(t *testing.T) {
+			s := setUp(outcome{err: networkError}, outcome{err: networkError}, outcome{err: networkError},
+				outcome{status: http.StatusOK}, outcome{err: networkError})
+
+			s.client.Do(newRequest(context.Background()))
+			s.client.Do(newRequest(context.Background()))
+			s.client.Do(newRequest(context.Background()))
+			assert.Equal(t, health.Component{
+				Status:  health.Up,
+				Details: BreakerDetails{State: BreakerClosed, ConsecutiveFailures: 1},
+			}, s.client.Health())
+		})
+		t.Run("should close after a successful trial call once the open duration has passed", func(t *testing.T) {
+			s := setUp(outcome{err: networkError}, outcome{err: networkError}, outcome{err: networkError},
+				outcome{err: networkError}, outcome{err: networkError}, outcome{err: networkError}, outcome{status: http.StatusOK})
+			s.client.Do(newRequest(context.Background()))
+			s.client.Do(newRequest(context.Background()))
+
+			s.advance(30 * time.Second)
+			response, err := s.client.Do(newRequest(context.Background()))
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+			assert.Equal(t, BreakerClosed, s.client.Health().Details.(BreakerDetails).State)
+		})
+		t.Run("should open again after a failed trial call", func(t *testing.T) {
+			s := setUp(outcome{status: http.StatusServiceUnavailable})
+			s.client.Do(newRequest(context.Background()))
+			s.client.Do(newRequest(context.Background()))
+
+			s.advance(30 * time.Second)
+			s.client.Do(newRequest(context.Background()))
+			_, err := s.client.Do(newRequest(context.Background()))
+			assert.Equal(t, ErrCircuitOpen, err)
+		})
+	})
+}
+
+// outcome is the result of a single call made through a scriptedHttpClient.
+type outcome struct {
+	status     int
+	retryAfter string
+	err        error
+}
+
+// scriptedHttpClient is a HttpClient returning each of its outcomes in turn, returning the last one again once the
+// others have been returned.
+type scriptedHttpClient struct {
+	outcomes []outcome
+	calls    int
+}
+
+func (c *scriptedHttpClient) Do(_ *http.Request) (*http.Response, error) {
+	next := c.outcomes[0]
+	if len(c.outcomes) > 1 {
+		c.outcomes = c.outcomes[1:]
+	}
+	c.calls++
+	if next.err != nil {
+		return nil, next.err
+	}
+	header := http.Header{}
+	if next.retryAfter != "" {
+		header.Set("Retry-After", next.retryAfter)
+	}
+	return &http.Response{
+		StatusCode: next.status,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader("")),
+	}, nil
+}
@@ -2,6 +2,8 @@ package forex
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"transaction-service/internal/business"
@@ -9,20 +11,47 @@ import (
 
 const (
 	unableToConvertToTargetCurrency = "UNABLE_TO_CONVERT_TO_TARGET_CURRENCY"
+	unknownCountry                  = "UNKNOWN_COUNTRY"
+	unknownCurrency                 = "UNKNOWN_CURRENCY"
+	convertedAmountOutOfRange       = "CONVERTED_AMOUNT_OUT_OF_RANGE"
 )
 
 // ConversionResult represents the output of a currency conversion operation
 type ConversionResult struct {
-	Amount       int
-	ExchangeRate float64
+	Amount int
+
+	// ExchangeRate is the exchange rate used for the conversion, exactly as recorded.
+	ExchangeRate ExchangeRate
+
+	// RateDate is the date of the exchange rate record used for the conversion.
+	RateDate time.Time
+
+	// CurrencyCode is the ISO 4217 code of the currency converted to, or is empty if it is not known.
+	CurrencyCode string
+
+	// RoundingMode is how the converted Amount was rounded to a whole cent, so that it can be reproduced.
+	RoundingMode RoundingMode
+
+	// Fallback is set when the exchange rate is the last one known, used because its lookup failed.
+	Fallback *Fallback
 }
 
-// NewRepositoryService creates a RepositoryService that uses the supplied repository and default Converter for
-// performing exchange rate calculations.
-func NewRepositoryService(repository Repository) *RepositoryService {
+// NewRepositoryService creates a RepositoryService that uses the supplied repositories, a Converter rounding by the
+// supplied RoundingMode for performing exchange rate calculations, and the supplied countries to match the country
+// names supplied by users.  Exchange rate records are selected by the supplied default rules unless a conversion
+// overrides them.
+func NewRepositoryService(repository Repository,
+	history HistoryRepository,
+	countries CountryMatcher,
+	defaults RateRules,
+	rounding RoundingMode) *RepositoryService {
+
 	return &RepositoryService{
 		repository: repository,
-		converter:  Converter{},
+		history:    history,
+		countries:  countries,
+		defaults:   defaults,
+		converter:  Converter{Rounding: rounding},
 	}
 }
 
@@ -31,30 +60,91 @@ type Repository interface {
 	FindByCountry(ctx context.Context, country string, oldest time.Time) (Record, error)
 }
 
+// CountryMatcher defines the interface expected of the directory of the countries known to the Treasury dataset.
+type CountryMatcher interface {
+	Match(country string) (match CountryMatch, loaded bool)
+}
+
 // RepositoryService is the business service for performing foreign exchange currency conversion calculations.
 type RepositoryService struct {
 	repository Repository
+	history    HistoryRepository
+	countries  CountryMatcher
+	defaults   RateRules
 	converter  Converter
 }
 
 // Convert will convert the provided amount (in cents) to the currency of the specified country, using an exchange
-// rate sourced from the configured data source which is not older than the provided dateOfOldestExchangeRate.  If no
-// suitable exchange rate can be found, an error will be returned.
+// rate sourced from the configured data source which is selected by the rules of the provided RateSelection.  The
+// country is matched against the known countries, so may be given in any case or by a common alias, such as 'UK'.  If
+// it matches none of them an error will be returned, suggesting the closest known countries.  Until the known
+// countries have been loaded, the country is looked up as given.  If no suitable exchange rate can be found, an error
+// will be returned.
 func (s *RepositoryService) Convert(ctx context.Context,
 	country string,
-	dateOfOldestExchangeRate time.Time,
+	selection RateSelection,
 	amountInCents int) (ConversionResult, error) {
 
-	record, err := s.repository.FindByCountry(ctx, country, dateOfOldestExchangeRate)
+	if match, loaded := s.countries.Match(country); loaded {
+		if match.Country == "" {
+			return ConversionResult{}, &business.Error{Message: unknownCountry, Suggestions: match.Suggestions}
+		}
+		country = match.Country
+	}
+	record, err := s.findRecord(ctx, country, selection.Date, s.defaults.overriddenBy(selection.Rules))
 	if err != nil {
 		return ConversionResult{}, err
 	}
 	if record == (Record{}) {
 		return ConversionResult{}, &business.Error{Message: unableToConvertToTargetCurrency}
 	}
-	exchangeRate := record.ExchangeRate.Value
+	return s.convert(record, currencyCode(country, record.Currency), amountInCents)
+}
+
+// ConvertToCurrency will convert the provided amount (in cents) to the currency with the specified ISO 4217 code,
+// using an exchange rate as for Convert.  The exchange rate is looked up for the country to which the code is mapped
+// (see currenciesCSV), and is only used if it is still for the same currency.  If the code is not supported or no
+// suitable exchange rate can be found, an error will be returned.
+func (s *RepositoryService) ConvertToCurrency(ctx context.Context,
+	code string,
+	selection RateSelection,
+	amountInCents int) (ConversionResult, error) {
+
+	resolved, ok := resolveCurrency(code)
+	if !ok {
+		return ConversionResult{}, &business.Error{Message: unknownCurrency}
+	}
+	record, err := s.findRecord(ctx, resolved.country, selection.Date, s.defaults.overriddenBy(selection.Rules))
+	if err != nil {
+		return ConversionResult{}, err
+	}
+	if record == (Record{}) || (record.Currency != "" && record.Currency != resolved.currency) {
+		return ConversionResult{}, &business.Error{Message: unableToConvertToTargetCurrency}
+	}
+	return s.convert(record, code, amountInCents)
+}
+
+// convert converts the provided amount (in cents) using the exchange rate of the supplied record, or returns an error
+// if the record does not hold a valid exchange rate or the converted amount is too large to be held in cents.
+func (s *RepositoryService) convert(record Record, code string, amountInCents int) (ConversionResult, error) {
+	rate, err := record.ExchangeRate.Rat()
+	if err != nil {
+		return ConversionResult{}, fmt.Errorf("exchange rate of %s recorded on %s: %w",
+			record.Country, record.RecordDate.Format(dateFormat), err)
+	}
+	amount, err := s.converter.Convert(amountInCents, rate)
+	if errors.Is(err, ErrAmountOutOfRange) {
+		return ConversionResult{}, &business.Error{Message: convertedAmountOutOfRange}
+	}
+	if err != nil {
+		return ConversionResult{}, err
+	}
 	return ConversionResult{
-		Amount:       s.converter.Convert(amountInCents, exchangeRate),
-		ExchangeRate: exchangeRate,
+		Amount:       amount,
+		ExchangeRate: record.ExchangeRate,
+		RateDate:     record.RecordDate.Time,
+		CurrencyCode: code,
+		RoundingMode: s.converter.Mode(),
+		Fallback:     record.Fallback,
 	}, nil
 }
@@ -3,6 +3,7 @@ package forex_test
 import (
 	"context"
 	"errors"
+	"math"
 	"testing"
 	"time"
 
@@ -15,9 +16,11 @@ import (
 )
 
 var (
-	ctx      context.Context
-	mockRepo MockRepository
-	service  *forex.RepositoryService
+	ctx           context.Context
+	mockRepo      MockRepository
+	mockCountries MockCountryMatcher
+	mockHistory   MockHistoryRepository
+	service       *forex.RepositoryService
 )
 
 func TestService(t *testing.T) {
@@ -25,6 +28,8 @@ func TestService(t *testing.T) {
 		name       string
 		record     forex.Record
 		err        error
+		match      forex.CountryMatch
+		loaded     bool
 		wantErr    error
 		wantResult forex.ConversionResult
 	}{
@@ -35,20 +40,68 @@ func TestService(t *testing.T) {
 					Time: date.NewInUTC(2023, time.April, 4),
 				},
 				ExchangeRate: forex.ExchangeRate{
-					Value: 0.745,
+					Value: "0.745",
 				},
 			},
 			err:     nil,
 			wantErr: nil,
 			wantResult: forex.ConversionResult{
 				Amount:       9197,
-				ExchangeRate: 0.745,
+				ExchangeRate: forex.ExchangeRate{Value: "0.745"},
+				RateDate:     date.NewInUTC(2023, time.April, 4),
+				RoundingMode: forex.HalfAwayFromZeroRounding,
 			},
 		},
 		{
-			name:   "should return an error when no exchange rate record is found",
+			name: "should say when the exchange rate record is the last one known",
+			record: forex.Record{
+				RecordDate:   forex.RecordDate{Time: date.NewInUTC(2023, time.April, 4)},
+				ExchangeRate: forex.ExchangeRate{Value: "0.745"},
+				Fallback: &forex.Fallback{
+					FetchedAt: time.Date(2023, time.May, 1, 9, 0, 0, 0, time.UTC),
+					Age:       90 * time.Minute,
+				},
+			},
+			wantResult: forex.ConversionResult{
+				Amount:       9197,
+				ExchangeRate: forex.ExchangeRate{Value: "0.745"},
+				RateDate:     date.NewInUTC(2023, time.April, 4),
+				RoundingMode: forex.HalfAwayFromZeroRounding,
+				Fallback: &forex.Fallback{
+					FetchedAt: time.Date(2023, time.May, 1, 9, 0, 0, 0, time.UTC),
+					Age:       90 * time.Minute,
+				},
+			},
+		},
+		{
+			name: "should look up the exchange rate record of the known country matched",
+			record: forex.Record{
+				RecordDate:   forex.RecordDate{Time: date.NewInUTC(2023, time.April, 4)},
+				ExchangeRate: forex.ExchangeRate{Value: "0.745"},
+			},
+			match:  forex.CountryMatch{Country: "*Matched Country*"},
+			loaded: true,
+			wantResult: forex.ConversionResult{
+				Amount:       9197,
+				ExchangeRate: forex.ExchangeRate{Value: "0.745"},
+				RateDate:     date.NewInUTC(2023, time.April, 4),
+				RoundingMode: forex.HalfAwayFromZeroRounding,
+			},
+		},
+		{
+			name:   "should return an error when no exchange rate record is found for a known country",
 			record: forex.Record{},
 			err:    nil,
+			match:  forex.CountryMatch{Country: "*country*"},
+			loaded: true,
+			wantErr: &business.Error{
+				Message: "UNABLE_TO_CONVERT_TO_TARGET_CURRENCY",
+			},
+			wantResult: forex.ConversionResult{},
+		},
+		{
+			name:   "should return an error when no exchange rate record is found and the known countries are not loaded",
+			record: forex.Record{},
 			wantErr: &business.Error{
 				Message: "UNABLE_TO_CONVERT_TO_TARGET_CURRENCY",
 			},
@@ -65,23 +118,265 @@ func TestService(t *testing.T) {
 	for _, tc := range tcs {
 		t.Run(tc.name, func(t *testing.T) {
 			setUpService()
+			selection := forex.RateSelection{Date: date.NewInUTC(2023, time.August, 10)}
 			dateOfOldestRecord := date.NewInUTC(2023, time.February, 10)
 			amountInCents := 12345
-			mockRepo.On("FindByCountry", ctx, "*country*", dateOfOldestRecord).
+			lookedUp := "*country*"
+			if tc.loaded {
+				lookedUp = tc.match.Country
+			}
+			mockRepo.On("FindByCountry", ctx, lookedUp, dateOfOldestRecord).
 				Return(tc.record, tc.err)
+			mockCountries.On("Match", "*country*").Return(tc.match, tc.loaded)
 
-			result, err := service.Convert(context.Background(), "*country*", dateOfOldestRecord, amountInCents)
+			result, err := service.Convert(context.Background(), "*country*", selection, amountInCents)
 			assert.Equal(t, tc.wantErr, err)
 			assert.Equal(t, tc.wantResult, result)
 			mockRepo.AssertExpectations(t)
 		})
 	}
+	t.Run("should return an unknown country error, with suggestions, when no known country is matched", func(t *testing.T) {
+		setUpService()
+		mockCountries.On("Match", "Untied Kingdom").
+			Return(forex.CountryMatch{Suggestions: []string{"United Kingdom", "United States"}}, true)
+
+		result, err := service.Convert(ctx, "Untied Kingdom", forex.RateSelection{Date: date.NewInUTC(2023, time.August, 10)}, 12345)
+		assert.Equal(t, &business.Error{
+			Message:     "UNKNOWN_COUNTRY",
+			Suggestions: []string{"United Kingdom", "United States"},
+		}, err)
+		assert.Equal(t, forex.ConversionResult{}, result)
+		mockRepo.AssertNotCalled(t, "FindByCountry", mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("should return an error rather than converting to zero when the record holds no valid exchange rate", func(t *testing.T) {
+		setUpService()
+		mockCountries.On("Match", "*country*").Return(forex.CountryMatch{}, false)
+		mockRepo.On("FindByCountry", ctx, "*country*", mock.Anything).Return(forex.Record{
+			Country:      "*country*",
+			RecordDate:   forex.RecordDate{Time: date.NewInUTC(2023, time.April, 4)},
+			ExchangeRate: forex.ExchangeRate{Value: "n/a"},
+		}, nil)
+
+		result, err := service.Convert(ctx, "*country*", forex.RateSelection{Date: date.NewInUTC(2023, time.August, 10)}, 12345)
+		assert.EqualError(t, err, `exchange rate of *country* recorded on 2023-04-04: invalid exchange rate "n/a"`)
+		assert.Equal(t, forex.ConversionResult{}, result)
+	})
+	t.Run("should return an error when the converted amount is too large to be held in cents", func(t *testing.T) {
+		setUpService()
+		mockCountries.On("Match", "*country*").Return(forex.CountryMatch{}, false)
+		mockRepo.On("FindByCountry", ctx, "*country*", mock.Anything).Return(forex.Record{
+			RecordDate:   forex.RecordDate{Time: date.NewInUTC(2023, time.April, 4)},
+			ExchangeRate: forex.ExchangeRate{Value: "42000"},
+		}, nil)
+
+		_, err := service.Convert(ctx, "*country*", forex.RateSelection{Date: date.NewInUTC(2023, time.August, 10)}, math.MaxInt64/2)
+		assert.Equal(t, &business.Error{Message: "CONVERTED_AMOUNT_OUT_OF_RANGE"}, err)
+	})
+}
+
+func TestServiceConvertToCurrency(t *testing.T) {
+	selection := forex.RateSelection{Date: date.NewInUTC(2023, time.August, 10)}
+	dateOfOldestRecord := date.NewInUTC(2023, time.February, 10)
+	euroRecord := forex.Record{
+		Country:      "Euro Zone",
+		Currency:     "Euro",
+		RecordDate:   forex.RecordDate{Time: date.NewInUTC(2023, time.March, 31)},
+		ExchangeRate: forex.ExchangeRate{Value: "0.92"},
+	}
+	tcs := []struct {
+		name       string
+		code       string
+		record     forex.Record
+		err        error
+		wantErr    error
+		wantResult forex.ConversionResult
+	}{
+		{
+			name:   "should convert using the exchange rate of the country the currency code resolves to",
+			code:   "EUR",
+			record: euroRecord,
+			wantResult: forex.ConversionResult{
+				Amount:       11357,
+				ExchangeRate: forex.ExchangeRate{Value: "0.92"},
+				RateDate:     date.NewInUTC(2023, time.March, 31),
+				CurrencyCode: "EUR",
+				RoundingMode: forex.HalfAwayFromZeroRounding,
+			},
+		},
+		{
+			name: "should return an error when the exchange rate found is for another currency of the country",
+			code: "EUR",
+			record: forex.Record{
+				Currency:     "Drachma",
+				RecordDate:   forex.RecordDate{Time: date.NewInUTC(2023, time.March, 31)},
+				ExchangeRate: forex.ExchangeRate{Value: "1"},
+			},
+			wantErr: &business.Error{Message: "UNABLE_TO_CONVERT_TO_TARGET_CURRENCY"},
+		},
+		{
+			name:    "should return an error when no exchange rate record is found",
+			code:    "EUR",
+			record:  forex.Record{},
+			wantErr: &business.Error{Message: "UNABLE_TO_CONVERT_TO_TARGET_CURRENCY"},
+		},
+		{
+			name:    "should return an error when there is a system problem retrieving the exchange rate record",
+			code:    "EUR",
+			err:     errors.New("problem"),
+			wantErr: errors.New("problem"),
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			setUpService()
+			mockRepo.On("FindByCountry", ctx, "Euro Zone", dateOfOldestRecord).Return(tc.record, tc.err)
+
+			result, err := service.ConvertToCurrency(ctx, tc.code, selection, 12345)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantResult, result)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+	t.Run("should return an error when the currency code is not supported", func(t *testing.T) {
+		setUpService()
+
+		_, err := service.ConvertToCurrency(ctx, "XXX", selection, 12345)
+		assert.Equal(t, &business.Error{Message: "UNKNOWN_CURRENCY"}, err)
+		mockRepo.AssertExpectations(t)
+	})
+	t.Run("should include the currency code when converting to the currency of a country", func(t *testing.T) {
+		setUpService()
+		germanRecord := euroRecord
+		germanRecord.Country = "Germany"
+		mockCountries.On("Match", "Germany").Return(forex.CountryMatch{Country: "Germany"}, true)
+		mockRepo.On("FindByCountry", ctx, "Germany", dateOfOldestRecord).Return(germanRecord, nil)
+
+		result, err := service.Convert(ctx, "Germany", selection, 12345)
+		assert.Nil(t, err)
+		assert.Equal(t, "EUR", result.CurrencyCode)
+	})
+}
+
+func TestServiceRatePolicies(t *testing.T) {
+	transactionDate := date.NewInUTC(2023, time.May, 10)
+	newRecord := func(recordDate time.Time, exchangeRate string) forex.Record {
+		return forex.Record{RecordDate: forex.RecordDate{Time: recordDate}, ExchangeRate: forex.ExchangeRate{Value: exchangeRate}}
+	}
+	march := newRecord(date.NewInUTC(2023, time.March, 31), "1")
+	april := newRecord(date.NewInUTC(2023, time.April, 30), "2")
+	may := newRecord(date.NewInUTC(2023, time.May, 15), "3")
+	tcs := []struct {
+		name       string
+		rules      forex.RateRules
+		from, to   time.Time
+		records    []forex.Record
+		wantRecord forex.Record
+	}{
+		{
+			name:       "should select the newest exchange rate on or before the date",
+			rules:      forex.RateRules{Policy: forex.LatestOnOrBeforePolicy},
+			from:       date.NewInUTC(2022, time.November, 10),
+			to:         transactionDate,
+			records:    []forex.Record{march, april},
+			wantRecord: april,
+		},
+		{
+			name:       "should select the exchange rate nearest the date, on either side of it",
+			rules:      forex.RateRules{Policy: forex.NearestPolicy, WindowMonths: 1},
+			from:       date.NewInUTC(2023, time.April, 10),
+			to:         date.NewInUTC(2023, time.June, 10),
+			records:    []forex.Record{april, may},
+			wantRecord: may,
+		},
+		{
+			name:       "should select the newest exchange rate on or before the date within its quarter",
+			rules:      forex.RateRules{Policy: forex.WithinQuarterPolicy},
+			from:       date.NewInUTC(2023, time.April, 1),
+			to:         transactionDate,
+			records:    []forex.Record{april},
+			wantRecord: april,
+		},
+		{
+			name:       "should keep to a window narrower than the quarter",
+			rules:      forex.RateRules{Policy: forex.WithinQuarterPolicy, WindowMonths: 1},
+			from:       date.NewInUTC(2023, time.April, 10),
+			to:         transactionDate,
+			records:    nil,
+			wantRecord: forex.Record{},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			setUpService()
+			mockCountries.On("Match", "*country*").Return(forex.CountryMatch{}, false)
+			mockHistory.On("FindByCountryBetween", ctx, "*country*", tc.from, tc.to).Return(tc.records, nil)
+
+			result, err := service.Convert(ctx, "*country*", forex.RateSelection{Date: transactionDate, Rules: tc.rules}, 100)
+
+			if tc.wantRecord == (forex.Record{}) {
+				assert.Equal(t, &business.Error{Message: "UNABLE_TO_CONVERT_TO_TARGET_CURRENCY"}, err)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tc.wantRecord.RecordDate.Time, result.RateDate)
+			}
+			mockHistory.AssertExpectations(t)
+			mockRepo.AssertNotCalled(t, "FindByCountry", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+	t.Run("should prefer the earlier of two exchange rates equally near the date", func(t *testing.T) {
+		setUpService()
+		mockCountries.On("Match", "*country*").Return(forex.CountryMatch{}, false)
+		mockHistory.On("FindByCountryBetween", ctx, "*country*", mock.Anything, mock.Anything).
+			Return([]forex.Record{newRecord(date.NewInUTC(2023, time.May, 5), "1"), newRecord(date.NewInUTC(2023, time.May, 15), "2")}, nil)
+
+		result, err := service.Convert(ctx, "*country*",
+			forex.RateSelection{Date: transactionDate, Rules: forex.RateRules{Policy: forex.NearestPolicy}}, 100)
+
+		assert.Nil(t, err)
+		assert.Equal(t, date.NewInUTC(2023, time.May, 5), result.RateDate)
+	})
+	t.Run("should use the default rules where the selection does not override them", func(t *testing.T) {
+		ctx = context.Background()
+		mockRepo = MockRepository{}
+		mockHistory = MockHistoryRepository{}
+		mockCountries = MockCountryMatcher{}
+		service = forex.NewRepositoryService(&mockRepo, &mockHistory, &mockCountries,
+			forex.RateRules{Policy: forex.LatestOnOrBeforePolicy, WindowMonths: 3}, forex.DefaultRoundingMode)
+		mockCountries.On("Match", "*country*").Return(forex.CountryMatch{}, false)
+		mockHistory.On("FindByCountryBetween", ctx, "*country*", date.NewInUTC(2023, time.February, 10), transactionDate).
+			Return([]forex.Record{march, april}, nil)
+
+		result, err := service.Convert(ctx, "*country*", forex.RateSelection{Date: transactionDate}, 100)
+
+		assert.Nil(t, err)
+		assert.Equal(t, april.RecordDate.Time, result.RateDate)
+		mockHistory.AssertExpectations(t)
+	})
+}
+
+func TestServiceRoundingMode(t *testing.T) {
+	ctx = context.Background()
+	mockRepo = MockRepository{}
+	mockHistory = MockHistoryRepository{}
+	mockCountries = MockCountryMatcher{}
+	service = forex.NewRepositoryService(&mockRepo, &mockHistory, &mockCountries, forex.DefaultRateRules, forex.HalfEvenRounding)
+	mockCountries.On("Match", "*country*").Return(forex.CountryMatch{}, false)
+	mockRepo.On("FindByCountry", ctx, "*country*", mock.Anything).
+		Return(forex.Record{RecordDate: forex.RecordDate{Time: date.NewInUTC(2023, time.April, 4)}, ExchangeRate: forex.ExchangeRate{Value: "0.25"}}, nil)
+
+	result, err := service.Convert(ctx, "*country*", forex.RateSelection{Date: date.NewInUTC(2023, time.May, 10)}, 10)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, result.Amount)
+	assert.Equal(t, forex.HalfEvenRounding, result.RoundingMode)
 }
 
 func setUpService() {
 	ctx = context.Background()
 	mockRepo = MockRepository{}
-	service = forex.NewRepositoryService(&mockRepo)
+	mockCountries = MockCountryMatcher{}
+	mockHistory = MockHistoryRepository{}
+	service = forex.NewRepositoryService(&mockRepo, &mockHistory, &mockCountries, forex.DefaultRateRules, forex.DefaultRoundingMode)
 }
 
 type MockRepository struct {
@@ -92,3 +387,12 @@ func (m *MockRepository) FindByCountry(ctx context.Context, country string, olde
 	args := m.Called(ctx, country, oldest)
 	return args.Get(0).(forex.Record), args.Error(1)
 }
+
+type MockCountryMatcher struct {
+	mock.Mock
+}
+
+func (m *MockCountryMatcher) Match(country string) (forex.CountryMatch, bool) {
+	args := m.Called(country)
+	return args.Get(0).(forex.CountryMatch), args.Bool(1)
+}
@@ -0,0 +1,115 @@
+package forex
+
+import (
+	"context"
+	"log"
+	"math"
+	"sync"
+	"time"
+)
+
+// maxLastKnownPeriods is the number of periods whose records a StaleIfErrorRepository remembers, beyond which the
+// least recently used period is forgotten.
+const maxLastKnownPeriods = 1000
+
+// NewStaleIfErrorRepository creates a StaleIfErrorRepository that finds records using the supplied repository.
+func NewStaleIfErrorRepository(repository RateRepository) *StaleIfErrorRepository {
+	return &StaleIfErrorRepository{
+		repository: repository,
+		now:        time.Now,
+		lastKnown:  make(map[string]lastKnownRecord),
+		periods:    newLRUCache[periodKey](maxLastKnownPeriods, func(records []Record) bool { return len(records) == 0 }),
+	}
+}
+
+// StaleIfErrorRepository is a RateRepository that remembers the newest exchange rate record found for each country by
+// another RateRepository, such as the TreasuryRepository.  Should a later lookup fail, for instance because the
+// Treasury API is unavailable, the remembered record is returned in its place, as long as it is not older than the date
+// of the oldest acceptable record.  The records found for each period are remembered likewise, for the most recently
+// used periods.  Records returned in place of a failed lookup are marked with a Fallback.
+type StaleIfErrorRepository struct {
+	repository RateRepository
+	now        func() time.Time
+	periods    *lruCache[periodKey, []Record]
+
+	mu        sync.RWMutex
+	lastKnown map[string]lastKnownRecord
+}
+
+// lastKnownRecord is the newest record found for a country, along with when it was found.
+type lastKnownRecord struct {
+	record    Record
+	fetchedAt time.Time
+}
+
+// FindByCountry returns the record found for the specified country and dateOfOldestRecord by the wrapped Repository.
+// Should that fail, the newest record previously found for the country is returned instead if it is not older than
+// dateOfOldestRecord, otherwise the error is returned.  Lookups abandoned because their context is done do not fall
+// back.
+func (r *StaleIfErrorRepository) FindByCountry(ctx context.Context, country string, dateOfOldestRecord time.Time) (Record, error) {
+	record, err := r.repository.FindByCountry(ctx, country, dateOfOldestRecord)
+	if err == nil {
+		r.remember(country, record)
+		return record, nil
+	}
+	if ctx.Err() != nil {
+		return Record{}, err
+	}
+	r.mu.RLock()
+	lastKnown, ok := r.lastKnown[country]
+	r.mu.RUnlock()
+	if !ok || lastKnown.record.RecordDate.Before(dateOfOldestRecord) {
+		return Record{}, err
+	}
+	log.Printf("serving last known exchange rate for %s after failed lookup: %v\n", country, err)
+	fallback := lastKnown.record
+	fallback.Fallback = &Fallback{
+		FetchedAt: lastKnown.fetchedAt,
+		Age:       r.now().Sub(lastKnown.fetchedAt),
+	}
+	return fallback, nil
+}
+
+// remember holds on to the supplied record as the last known record of the supplied country, unless a newer record is
+// already known.
+func (r *StaleIfErrorRepository) remember(country string, record Record) {
+	if record == (Record{}) {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if known, ok := r.lastKnown[country]; ok && record.RecordDate.Before(known.record.RecordDate.Time) {
+		return
+	}
+	r.lastKnown[country] = lastKnownRecord{record: record, fetchedAt: r.now()}
+}
+
+// FindByCountryBetween returns the records found for the specified country and period by the wrapped RateRepository.
+// Should that fail, the records last found for the same period are returned instead if there are any, otherwise the
+// error is returned.  As with FindByCountry, lookups abandoned because their context is done do not fall back.
+func (r *StaleIfErrorRepository) FindByCountryBetween(ctx context.Context, country string, from, to time.Time) ([]Record, error) {
+	key := newPeriodKey(country, from, to)
+	records, err := r.repository.FindByCountryBetween(ctx, country, from, to)
+	if err == nil {
+		if len(records) > 0 {
+			r.periods.put(key, records, r.now(), math.MaxInt64)
+		}
+		return records, nil
+	}
+	if ctx.Err() != nil {
+		return nil, err
+	}
+	lastKnown, fetchedAt, ok := r.periods.get(key, r.now())
+	if !ok {
+		return nil, err
+	}
+	log.Printf("serving last known exchange rates for %s from %s to %s after failed lookup: %v\n",
+		country, key.from, key.to, err)
+	fallback := &Fallback{FetchedAt: fetchedAt, Age: r.now().Sub(fetchedAt)}
+	records = make([]Record, len(lastKnown))
+	for i, record := range lastKnown {
+		record.Fallback = fallback
+		records[i] = record
+	}
+	return records, nil
+}
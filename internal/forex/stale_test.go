@@ -0,0 +1,112 @@
+package forex
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"transaction-service/internal/date"
+)
+
+func TestStaleIfErrorRepository(t *testing.T) {
+	ctx := context.Background()
+	oldest := date.NewInUTC(2023, time.February, 10)
+	ukRecord := Record{
+		RecordDate:   RecordDate{Time: date.NewInUTC(2023, time.March, 31)},
+		ExchangeRate: ExchangeRate{Value: "0.812"},
+	}
+	fetchedAt := time.Date(2023, time.May, 1, 9, 0, 0, 0, time.UTC)
+	setUp := func() (*StaleIfErrorRepository, *countingRepository, *time.Time) {
+		upstream := &countingRepository{records: map[string]Record{"United Kingdom": ukRecord}}
+		repository := NewStaleIfErrorRepository(upstream)
+		now := fetchedAt
+		repository.now = func() time.Time { return now }
+		return repository, upstream, &now
+	}
+
+	t.Run("should return the record found by the wrapped repository", func(t *testing.T) {
+		repository, _, _ := setUp()
+
+		record, err := repository.FindByCountry(ctx, "United Kingdom", oldest)
+		assert.Nil(t, err)
+		assert.Equal(t, ukRecord, record)
+	})
+	t.Run("should return the last known record, saying how old it is, when the lookup fails", func(t *testing.T) {
+		repository, upstream, now := setUp()
+		repository.FindByCountry(ctx, "United Kingdom", oldest)
+		upstream.err = errors.New("problem")
+		*now = now.Add(90 * time.Minute)
+
+		record, err := repository.FindByCountry(ctx, "United Kingdom", oldest)
+		assert.Nil(t, err)
+		want := ukRecord
+		want.Fallback = &Fallback{FetchedAt: fetchedAt, Age: 90 * time.Minute}
+		assert.Equal(t, want, record)
+	})
+	t.Run("should return the error when the last known record is older than the oldest acceptable record", func(t *testing.T) {
+		repository, upstream, _ := setUp()
+		repository.FindByCountry(ctx, "United Kingdom", oldest)
+		upstream.err = errors.New("problem")
+
+		_, err := repository.FindByCountry(ctx, "United Kingdom", date.NewInUTC(2023, time.April, 1))
+		assert.EqualError(t, err, "problem")
+	})
+	t.Run("should return the error when no record is known for the country", func(t *testing.T) {
+		repository, upstream, _ := setUp()
+		repository.FindByCountry(ctx, "Atlantis", oldest)
+		upstream.err = errors.New("problem")
+
+		_, err := repository.FindByCountry(ctx, "Atlantis", oldest)
+		assert.EqualError(t, err, "problem")
+	})
+	t.Run("should keep the newest record known for the country", func(t *testing.T) {
+		repository, upstream, _ := setUp()
+		repository.FindByCountry(ctx, "United Kingdom", oldest)
+		older := Record{
+			RecordDate:   RecordDate{Time: date.NewInUTC(2022, time.December, 31)},
+			ExchangeRate: ExchangeRate{Value: "0.826"},
+		}
+		upstream.records["United Kingdom"] = older
+		repository.FindByCountry(ctx, "United Kingdom", date.NewInUTC(2022, time.October, 1))
+		upstream.err = errors.New("problem")
+
+		record, err := repository.FindByCountry(ctx, "United Kingdom", oldest)
+		assert.Nil(t, err)
+		assert.Equal(t, ukRecord.ExchangeRate, record.ExchangeRate)
+	})
+	t.Run("should return the last known records of a period, saying how old they are, when the lookup fails", func(t *testing.T) {
+		repository, upstream, now := setUp()
+		to := oldest.AddDate(0, 6, 0)
+		repository.FindByCountryBetween(ctx, "United Kingdom", oldest, to)
+		upstream.err = errors.New("problem")
+		*now = now.Add(90 * time.Minute)
+
+		records, err := repository.FindByCountryBetween(ctx, "United Kingdom", oldest, to)
+		assert.Nil(t, err)
+		want := ukRecord
+		want.Fallback = &Fallback{FetchedAt: fetchedAt, Age: 90 * time.Minute}
+		assert.Equal(t, []Record{want}, records)
+	})
+	t.Run("should return the error when no records are known for the period", func(t *testing.T) {
+		repository, upstream, _ := setUp()
+		to := oldest.AddDate(0, 6, 0)
+		repository.FindByCountryBetween(ctx, "United Kingdom", oldest, to)
+		upstream.err = errors.New("problem")
+
+		_, err := repository.FindByCountryBetween(ctx, "United Kingdom", oldest, to.AddDate(0, 0, 1))
+		assert.EqualError(t, err, "problem")
+	})
+	t.Run("should not fall back when the lookup is abandoned", func(t *testing.T) {
+		repository, upstream, _ := setUp()
+		repository.FindByCountry(ctx, "United Kingdom", oldest)
+		upstream.err = context.Canceled
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := repository.FindByCountry(cancelled, "United Kingdom", oldest)
+		assert.Equal(t, context.Canceled, err)
+	})
+}
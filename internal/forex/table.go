@@ -0,0 +1,169 @@
+package forex
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrTableNotLoaded is returned when looking up an exchange rate record before the TableRepository has been loaded.
+var ErrTableNotLoaded = errors.New("exchange rate table has not been loaded")
+
+// Dataset is the interface expected of the source of every exchange rate record, such as the TreasuryRepository.
+type Dataset interface {
+	FindAll(ctx context.Context) ([]Record, error)
+}
+
+// versionedDataset is implemented by a Dataset that can cheaply report a version which changes whenever its records
+// change, such as the FileDataset.  A TableRepository only reloads such a Dataset once its version has changed.
+type versionedDataset interface {
+	Version() (string, error)
+}
+
+// NewTableRepository creates a TableRepository that holds the records of the supplied Dataset.  It is empty until it
+// has been refreshed.
+func NewTableRepository(dataset Dataset) *TableRepository {
+	return &TableRepository{
+		dataset:   dataset,
+		refresher: newRefresher(),
+	}
+}
+
+// NewTableRepositoryWithFallback creates a TableRepository that holds the records of the supplied Dataset, as for
+// NewTableRepository, but which answers lookups using the supplied fallback until it has first been loaded.
+func NewTableRepositoryWithFallback(dataset Dataset, fallback RateRepository) *TableRepository {
+	repository := NewTableRepository(dataset)
+	repository.fallback = fallback
+	return repository
+}
+
+// TableRepository is a Repository that holds every exchange rate record of a Dataset in memory, indexed by country and
+// ordered by record date, so that records are looked up locally rather than by calling the Treasury API each time.
+// The table is refreshed on a schedule in the background once started.  Should a refresh fail, the previous table
+// continues to be used, so lookups are still answered while the Treasury API is briefly unavailable.
+type TableRepository struct {
+	dataset  Dataset
+	fallback RateRepository
+
+	mu       sync.RWMutex
+	table    map[string][]Record
+	loadedAt time.Time
+	version  string
+
+	refresher *refresher
+}
+
+// FindByCountry returns the most recent foreign exchange record for the specified country that is not older than the
+// specified dateOfOldestRecord.  An empty Record is returned if there is no such record.  If the table has not yet been
+// loaded, the record is found by the fallback instead, or ErrTableNotLoaded is returned if there is none.
+func (r *TableRepository) FindByCountry(ctx context.Context, country string, dateOfOldestRecord time.Time) (Record, error) {
+	records, loaded := r.records(country)
+	if !loaded {
+		if r.fallback != nil {
+			return r.fallback.FindByCountry(ctx, country, dateOfOldestRecord)
+		}
+		return Record{}, ErrTableNotLoaded
+	}
+	if len(records) == 0 {
+		return Record{}, nil
+	}
+	newest := records[len(records)-1]
+	if newest.RecordDate.Before(dateOfOldestRecord) {
+		return Record{}, nil
+	}
+	return newest, nil
+}
+
+// FindByCountryBetween returns every foreign exchange record for the specified country recorded on or between the
+// specified from and to dates, ordered by record date.  If the table has not yet been loaded, the records are found by
+// the fallback instead, or ErrTableNotLoaded is returned if there is none.
+func (r *TableRepository) FindByCountryBetween(ctx context.Context, country string, from, to time.Time) ([]Record, error) {
+	records, loaded := r.records(country)
+	if !loaded {
+		if r.fallback != nil {
+			return r.fallback.FindByCountryBetween(ctx, country, from, to)
+		}
+		return nil, ErrTableNotLoaded
+	}
+	start := sort.Search(len(records), func(i int) bool {
+		return !records[i].RecordDate.Before(from)
+	})
+	end := sort.Search(len(records), func(i int) bool {
+		return records[i].RecordDate.After(to)
+	})
+	if start >= end {
+		return nil, nil
+	}
+	return append([]Record(nil), records[start:end]...), nil
+}
+
+// records returns the records of the supplied country, ordered by record date, reporting false if the table has not yet
+// been loaded.  The records are never modified, since a refresh replaces the whole table, so they may be read once the
+// lock has been released.
+func (r *TableRepository) records(country string) ([]Record, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.table == nil {
+		return nil, false
+	}
+	return r.table[country], true
+}
+
+// LoadedAt returns the time at which the table was last loaded, which is the zero time if it has not been loaded.
+func (r *TableRepository) LoadedAt() time.Time {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.loadedAt
+}
+
+// Refresh replaces the table with every record currently in the Dataset, or returns an error (if one occurred) leaving
+// the table as it was.  Nothing is done if the Dataset reports that it has not changed since the table was loaded.
+func (r *TableRepository) Refresh(ctx context.Context) error {
+	var version string
+	if versioned, ok := r.dataset.(versionedDataset); ok {
+		var err error
+		if version, err = versioned.Version(); err != nil {
+			return err
+		}
+		r.mu.RLock()
+		unchanged := r.table != nil && version == r.version
+		r.mu.RUnlock()
+		if unchanged {
+			return nil
+		}
+	}
+	records, err := r.dataset.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	table := make(map[string][]Record)
+	for _, record := range records {
+		table[record.Country] = append(table[record.Country], record)
+	}
+	for _, countryRecords := range table {
+		sort.SliceStable(countryRecords, func(i, j int) bool {
+			return countryRecords[i].RecordDate.Before(countryRecords[j].RecordDate.Time)
+		})
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.table = table
+	r.loadedAt = time.Now()
+	r.version = version
+	return nil
+}
+
+// Start refreshes the table straight away and then every interval in the background, until the TableRepository is
+// closed.  Failed refreshes are logged and retried at the next interval.
+func (r *TableRepository) Start(interval time.Duration) {
+	r.refresher.start(interval, "exchange rate table", r.Refresh)
+}
+
+// Close stops refreshing the table in the background, waiting for a refresh in progress to stop.  The table can still
+// be used once closed.
+func (r *TableRepository) Close() error {
+	r.refresher.close()
+	return nil
+}
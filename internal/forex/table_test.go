@@ -0,0 +1,178 @@
+package forex_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"transaction-service/internal/date"
+	"transaction-service/internal/forex"
+)
+
+func TestTableRepository(t *testing.T) {
+	ctx := context.Background()
+	newRecord := func(country string, recordDate time.Time, rate string) forex.Record {
+		return forex.Record{
+			Country:      country,
+			RecordDate:   forex.RecordDate{Time: recordDate},
+			ExchangeRate: forex.ExchangeRate{Value: rate},
+		}
+	}
+	ukDecember := newRecord("United Kingdom", date.NewInUTC(2022, time.December, 31), "0.829")
+	ukMarch := newRecord("United Kingdom", date.NewInUTC(2023, time.March, 31), "0.812")
+	australiaMarch := newRecord("Australia", date.NewInUTC(2023, time.March, 31), "1.495")
+
+	t.Run("should return the newest record of the country that is not older than the supplied date", func(t *testing.T) {
+		dataset := &MockDataset{}
+		dataset.On("FindAll", ctx).Return([]forex.Record{ukMarch, australiaMarch, ukDecember}, nil)
+		repo := forex.NewTableRepository(dataset)
+		assert.Nil(t, repo.Refresh(ctx))
+
+		record, err := repo.FindByCountry(ctx, "United Kingdom", date.NewInUTC(2022, time.October, 1))
+		assert.Nil(t, err)
+		assert.Equal(t, ukMarch, record)
+		assert.False(t, repo.LoadedAt().IsZero())
+	})
+	t.Run("should return an empty record when the newest record of the country is older than the supplied date", func(t *testing.T) {
+		dataset := &MockDataset{}
+		dataset.On("FindAll", ctx).Return([]forex.Record{ukDecember, ukMarch}, nil)
+		repo := forex.NewTableRepository(dataset)
+		repo.Refresh(ctx)
+
+		record, err := repo.FindByCountry(ctx, "United Kingdom", date.NewInUTC(2023, time.April, 1))
+		assert.Nil(t, err)
+		assert.Equal(t, forex.Record{}, record)
+	})
+	t.Run("should return an empty record when there are no records of the country", func(t *testing.T) {
+		dataset := &MockDataset{}
+		dataset.On("FindAll", ctx).Return([]forex.Record{ukMarch}, nil)
+		repo := forex.NewTableRepository(dataset)
+		repo.Refresh(ctx)
+
+		record, err := repo.FindByCountry(ctx, "Atlantis", date.NewInUTC(2022, time.October, 1))
+		assert.Nil(t, err)
+		assert.Equal(t, forex.Record{}, record)
+	})
+	t.Run("should return the records of the country recorded on or between the supplied dates", func(t *testing.T) {
+		dataset := &MockDataset{}
+		dataset.On("FindAll", ctx).Return([]forex.Record{ukMarch, australiaMarch, ukDecember}, nil)
+		repo := forex.NewTableRepository(dataset)
+		repo.Refresh(ctx)
+
+		records, err := repo.FindByCountryBetween(ctx, "United Kingdom",
+			date.NewInUTC(2022, time.December, 31), date.NewInUTC(2023, time.March, 31))
+		assert.Nil(t, err)
+		assert.Equal(t, []forex.Record{ukDecember, ukMarch}, records)
+
+		records, err = repo.FindByCountryBetween(ctx, "United Kingdom",
+			date.NewInUTC(2023, time.January, 1), date.NewInUTC(2023, time.March, 30))
+		assert.Nil(t, err)
+		assert.Empty(t, records)
+	})
+	t.Run("should keep the previous table when a refresh fails", func(t *testing.T) {
+		dataset := &MockDataset{}
+		dataset.On("FindAll", ctx).Return([]forex.Record{ukMarch}, nil).Once()
+		dataset.On("FindAll", ctx).Return([]forex.Record(nil), errors.New("problem")).Once()
+		repo := forex.NewTableRepository(dataset)
+		repo.Refresh(ctx)
+
+		assert.EqualError(t, repo.Refresh(ctx), "problem")
+		record, err := repo.FindByCountry(ctx, "United Kingdom", date.NewInUTC(2022, time.October, 1))
+		assert.Nil(t, err)
+		assert.Equal(t, ukMarch, record)
+	})
+	t.Run("should only reload a versioned dataset once its version has changed", func(t *testing.T) {
+		dataset := &MockVersionedDataset{}
+		dataset.On("Version").Return("1", nil).Twice()
+		dataset.On("Version").Return("2", nil).Once()
+		dataset.On("FindAll", ctx).Return([]forex.Record{ukMarch}, nil).Twice()
+		repo := forex.NewTableRepository(dataset)
+
+		assert.Nil(t, repo.Refresh(ctx))
+		assert.Nil(t, repo.Refresh(ctx))
+		assert.Nil(t, repo.Refresh(ctx))
+		dataset.AssertExpectations(t)
+	})
+	t.Run("should return an error when the table has not been loaded", func(t *testing.T) {
+		repo := forex.NewTableRepository(&MockDataset{})
+
+		_, err := repo.FindByCountry(ctx, "United Kingdom", date.NewInUTC(2022, time.October, 1))
+		assert.Equal(t, forex.ErrTableNotLoaded, err)
+		_, err = repo.FindByCountryBetween(ctx, "United Kingdom", date.NewInUTC(2022, time.October, 1), time.Now())
+		assert.Equal(t, forex.ErrTableNotLoaded, err)
+	})
+	t.Run("should look up records using the fallback until the table has been loaded", func(t *testing.T) {
+		dataset := &MockDataset{}
+		dataset.On("FindAll", ctx).Return([]forex.Record{ukMarch}, nil)
+		fallback := &MockFallbackRepository{}
+		oldest := date.NewInUTC(2022, time.October, 1)
+		fallback.On("FindByCountry", ctx, "United Kingdom", oldest).Return(ukDecember, nil).Once()
+		fallback.On("FindByCountryBetween", ctx, "United Kingdom", oldest, ukMarch.RecordDate.Time).
+			Return([]forex.Record{ukDecember}, nil).Once()
+		repo := forex.NewTableRepositoryWithFallback(dataset, fallback)
+
+		record, err := repo.FindByCountry(ctx, "United Kingdom", oldest)
+		assert.Nil(t, err)
+		assert.Equal(t, ukDecember, record)
+		records, err := repo.FindByCountryBetween(ctx, "United Kingdom", oldest, ukMarch.RecordDate.Time)
+		assert.Nil(t, err)
+		assert.Equal(t, []forex.Record{ukDecember}, records)
+
+		assert.Nil(t, repo.Refresh(ctx))
+		record, err = repo.FindByCountry(ctx, "United Kingdom", oldest)
+		assert.Nil(t, err)
+		assert.Equal(t, ukMarch, record)
+		fallback.AssertExpectations(t)
+	})
+	t.Run("should refresh in the background once started, until closed", func(t *testing.T) {
+		dataset := &MockDataset{}
+		dataset.On("FindAll", mock.Anything).Return([]forex.Record{ukMarch}, nil)
+		repo := forex.NewTableRepository(dataset)
+
+		repo.Start(time.Millisecond)
+		assert.Eventually(t, func() bool {
+			_, err := repo.FindByCountry(ctx, "United Kingdom", date.NewInUTC(2022, time.October, 1))
+			return err == nil
+		}, time.Second, time.Millisecond)
+		assert.Nil(t, repo.Close())
+		calls := len(dataset.Calls)
+		time.Sleep(5 * time.Millisecond)
+		assert.Equal(t, calls, len(dataset.Calls))
+	})
+}
+
+type MockDataset struct {
+	mock.Mock
+}
+
+func (m *MockDataset) FindAll(ctx context.Context) ([]forex.Record, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]forex.Record), args.Error(1)
+}
+
+type MockVersionedDataset struct {
+	MockDataset
+}
+
+func (m *MockVersionedDataset) Version() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
+type MockFallbackRepository struct {
+	mock.Mock
+}
+
+func (m *MockFallbackRepository) FindByCountry(ctx context.Context, country string, oldest time.Time) (forex.Record, error) {
+	args := m.Called(ctx, country, oldest)
+	return args.Get(0).(forex.Record), args.Error(1)
+}
+
+func (m *MockFallbackRepository) FindByCountryBetween(ctx context.Context, country string, from, to time.Time) ([]forex.Record, error) {
+	args := m.Called(ctx, country, from, to)
+	return args.Get(0).([]forex.Record), args.Error(1)
+}
@@ -0,0 +1,57 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Statuses of the service and of the components on which it depends.
+const (
+	Up       = "UP"
+	Down     = "DOWN"
+	Degraded = "DEGRADED"
+)
+
+// Component describes the health of something on which the service depends.
+type Component struct {
+	Status  string `json:"status"`
+	Details any    `json:"details,omitempty"`
+}
+
+// Checker is implemented by anything able to report the health of a component on which the service depends.
+type Checker interface {
+	Health() Component
+}
+
+// Response represents the http response body of the 'health' operation.
+type Response struct {
+	Status     string               `json:"status"`
+	Components map[string]Component `json:"components,omitempty"`
+}
+
+// ConfigureHandler configures the supplied router with a handler that reports the health of the service, using the
+// supplied checkers to report the health of each named component.
+func ConfigureHandler(router *gin.Engine, checkers map[string]Checker) {
+	router.GET("/health", NewHandler(checkers))
+}
+
+// NewHandler is responsible for responding to the 'health' http request with the health of each component.  The
+// service is reported as degraded, rather than down, when a component is down, since it can still do everything that
+// does not depend on that component.  The http status is always 200, as the service is able to respond.
+func NewHandler(checkers map[string]Checker) func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		response := Response{Status: Up}
+		if len(checkers) > 0 {
+			response.Components = make(map[string]Component)
+		}
+		for name, checker := range checkers {
+			component := checker.Health()
+			if component.Status != Up {
+				response.Status = Degraded
+			}
+			response.Components[name] = component
+		}
+		ctx.JSON(http.StatusOK, response)
+	}
+}
@@ -0,0 +1,68 @@
+package health_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"transaction-service/internal/health"
+)
+
+func TestHandler(t *testing.T) {
+	setUp := func(checkers map[string]health.Checker) (*gin.Engine, *httptest.ResponseRecorder) {
+		router := gin.Default()
+		health.ConfigureHandler(router, checkers)
+		return router, httptest.NewRecorder()
+	}
+
+	t.Run("should report the service as up when it has no components", func(t *testing.T) {
+		router, rr := setUp(nil)
+
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"status": "UP"}`, rr.Body.String())
+	})
+	t.Run("should report the service as up when every component is up", func(t *testing.T) {
+		mockChecker := &MockChecker{}
+		mockChecker.On("Health").Return(health.Component{Status: health.Up, Details: map[string]any{"state": "closed"}})
+		router, rr := setUp(map[string]health.Checker{"treasuryApi": mockChecker})
+
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{
+			"status": "UP",
+			"components": {"treasuryApi": {"status": "UP", "details": {"state": "closed"}}}
+		}`, rr.Body.String())
+		mockChecker.AssertExpectations(t)
+	})
+	t.Run("should report the service as degraded when a component is down", func(t *testing.T) {
+		upChecker := &MockChecker{}
+		upChecker.On("Health").Return(health.Component{Status: health.Up})
+		downChecker := &MockChecker{}
+		downChecker.On("Health").Return(health.Component{Status: health.Down})
+		router, rr := setUp(map[string]health.Checker{"database": upChecker, "treasuryApi": downChecker})
+
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{
+			"status": "DEGRADED",
+			"components": {"database": {"status": "UP"}, "treasuryApi": {"status": "DOWN"}}
+		}`, rr.Body.String())
+	})
+}
+
+type MockChecker struct {
+	mock.Mock
+}
+
+func (m *MockChecker) Health() health.Component {
+	args := m.Called()
+	return args.Get(0).(health.Component)
+}
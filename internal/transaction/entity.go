@@ -8,4 +8,20 @@ type Entity struct {
 	Description     string    `json:"description"`
 	TransactionDate time.Time `json:"transactionDate"`
 	AmountInCents   int       `json:"amountInCents"`
+
+	// Version is incremented each time the transaction is changed, starting from one when it is first stored.  It
+	// allows concurrent changes to be detected.
+	Version int `json:"version"`
+
+	// Voided holds the details of the transaction having been voided, or is nil if it has not been voided.
+	Voided *Void `json:"voided,omitempty"`
+}
+
+// Void records the cancellation of a transaction, which is kept rather than deleted.
+type Void struct {
+	// Reason is the supplied explanation for voiding the transaction.
+	Reason string `json:"reason"`
+
+	// At is the time at which the transaction was voided.
+	At time.Time `json:"at"`
 }
@@ -0,0 +1,189 @@
+package transaction
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"transaction-service/internal/validation"
+)
+
+// exportFormat is a representation in which transactions can be exported.
+type exportFormat struct {
+	// contentType is the media type of the representation.
+	contentType string
+
+	// extension is the file name extension suggested for the export.
+	extension string
+}
+
+var (
+	csvFormat    = exportFormat{contentType: "text/csv", extension: "csv"}
+	ndjsonFormat = exportFormat{contentType: "application/x-ndjson", extension: "ndjson"}
+)
+
+// exportFormats maps each media type that may be requested in the Accept header to the exportFormat it selects.  CSV
+// is exported when any format is acceptable.
+var exportFormats = map[string]exportFormat{
+	"text/csv":             csvFormat,
+	"application/x-ndjson": ndjsonFormat,
+	"application/ndjson":   ndjsonFormat,
+	"text/*":               csvFormat,
+	"application/*":        ndjsonFormat,
+	"*/*":                  csvFormat,
+}
+
+// csvHeader holds the names of the columns of a CSV export, in order.
+var csvHeader = []string{
+	"id",
+	"description",
+	"transactionDate",
+	"usdAmountInCents",
+	"convertedAmountInCents",
+	"exchangeRate",
+	"rateDate",
+	"valuationDate",
+	"currencyCode",
+	"roundingMode",
+	"fallbackFetchedAt",
+	"fallbackAgeSeconds",
+	"conversionError",
+	"voidReason",
+	"voidedAt",
+}
+
+// negotiateExportFormat returns the exportFormat selected by the supplied Accept header, which is the first of the
+// listed media types that can be exported.  It reports false if none of them can be exported.  Quality values are not
+// taken into account.
+func negotiateExportFormat(accept string) (exportFormat, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return csvFormat, true
+	}
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(mediaRange)
+		if err != nil {
+			continue
+		}
+		if format, ok := exportFormats[mediaType]; ok {
+			return format, true
+		}
+	}
+	return exportFormat{}, false
+}
+
+// newExportWriter creates an exportWriter that writes transactions to the supplied http response in the supplied
+// format.
+func newExportWriter(ctx *gin.Context, format exportFormat) *exportWriter {
+	return &exportWriter{
+		ctx:    ctx,
+		format: format,
+		csv:    csv.NewWriter(ctx.Writer),
+		json:   json.NewEncoder(ctx.Writer),
+	}
+}
+
+// exportWriter streams exported transactions to a http response, flushing each one to the client as it is written.
+// The response status and headers are only written along with the first transaction (or when the export is finished,
+// if it is empty), so that an error occurring before then can still be reported in the usual way.
+type exportWriter struct {
+	ctx     *gin.Context
+	format  exportFormat
+	csv     *csv.Writer
+	json    *json.Encoder
+	started bool
+}
+
+// write writes a single transaction to the response.
+func (w *exportWriter) write(summary Summary) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	if w.format == ndjsonFormat {
+		if err := w.json.Encode(summary); err != nil {
+			return err
+		}
+	} else {
+		if err := w.csv.Write(csvRecord(summary)); err != nil {
+			return err
+		}
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	}
+	w.ctx.Writer.Flush()
+	return nil
+}
+
+// finish ensures the response has been started, so that an empty export is still a valid one.
+func (w *exportWriter) finish() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	w.csv.Flush()
+	if err := w.csv.Error(); err != nil {
+		return err
+	}
+	w.ctx.Writer.Flush()
+	return nil
+}
+
+// start writes the response status and headers, along with the CSV header row, unless they have already been
+// written.
+func (w *exportWriter) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	w.ctx.Header("Content-Type", w.format.contentType)
+	w.ctx.Header("Content-Disposition", `attachment; filename="transactions.`+w.format.extension+`"`)
+	w.ctx.Status(http.StatusOK)
+	if w.format == csvFormat {
+		return w.csv.Write(csvHeader)
+	}
+	return nil
+}
+
+// csvRecord returns the fields of the CSV row representing the supplied transaction, in the order of csvHeader.
+func csvRecord(summary Summary) []string {
+	record := make([]string, len(csvHeader))
+	record[0] = summary.ID
+	record[1] = csvText(summary.Description)
+	record[2] = summary.TransactionDate.Format(validation.DateFormat)
+	record[3] = strconv.Itoa(summary.AmountInCents)
+	if summary.Amount != nil {
+		record[4] = strconv.Itoa(summary.Amount.ConvertedAmountInCents)
+		record[5] = summary.Amount.ExchangeRate.String()
+		record[6] = summary.Amount.RateDate.Format(validation.DateFormat)
+		if summary.Amount.ValuationDate != nil {
+			record[7] = summary.Amount.ValuationDate.Format(validation.DateFormat)
+		}
+		record[8] = summary.Amount.CurrencyCode
+		record[9] = summary.Amount.RoundingMode
+		if summary.Amount.Fallback != nil {
+			record[10] = summary.Amount.Fallback.FetchedAt.Format(time.RFC3339)
+			record[11] = strconv.FormatInt(summary.Amount.Fallback.AgeSeconds, 10)
+		}
+	}
+	record[12] = summary.ConversionError
+	if summary.Voided != nil {
+		record[13] = csvText(summary.Voided.Reason)
+		record[14] = summary.Voided.VoidedAt.Format(time.RFC3339)
+	}
+	return record
+}
+
+// csvText returns the supplied free text as it is written to a CSV export.  Text that a spreadsheet would treat as a
+// formula is prefixed with an apostrophe, so that opening an export cannot run a formula supplied by a client.
+func csvText(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
@@ -0,0 +1,85 @@
+package transaction
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiateExportFormat(t *testing.T) {
+	tcs := []struct {
+		name       string
+		accept     string
+		wantFormat exportFormat
+		wantOK     bool
+	}{
+		{
+			name:       "should export CSV when no format is requested",
+			accept:     "",
+			wantFormat: csvFormat,
+			wantOK:     true,
+		},
+		{
+			name:       "should export CSV when any format is acceptable",
+			accept:     "*/*",
+			wantFormat: csvFormat,
+			wantOK:     true,
+		},
+		{
+			name:       "should export the first listed format that can be exported",
+			accept:     "application/pdf, application/ndjson;q=0.9, text/csv",
+			wantFormat: ndjsonFormat,
+			wantOK:     true,
+		},
+		{
+			name:   "should report that no listed format can be exported",
+			accept: "application/pdf, text/html",
+			wantOK: false,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			format, ok := negotiateExportFormat(tc.accept)
+			assert.Equal(t, tc.wantFormat, format)
+			assert.Equal(t, tc.wantOK, ok)
+		})
+	}
+}
+
+func TestCSVText(t *testing.T) {
+	assert.Equal(t, "A holiday", csvText("A holiday"))
+	assert.Equal(t, "", csvText(""))
+	assert.Equal(t, "'=1+2", csvText("=1+2"))
+	assert.Equal(t, "'+1", csvText("+1"))
+	assert.Equal(t, "'-1", csvText("-1"))
+	assert.Equal(t, "'@SUM(A1)", csvText("@SUM(A1)"))
+}
+
+func TestCSVRecord(t *testing.T) {
+	summary := Summary{
+		ID:              "*txn-id*",
+		Description:     "*description*",
+		TransactionDate: &FormattedDate{Time: time.Date(2023, time.May, 1, 0, 0, 0, 0, time.UTC)},
+		AmountInCents:   100,
+		Amount: &Amount{
+			USDAmountInCents:       100,
+			ConvertedAmountInCents: 154,
+			ExchangeRate:           "1.542",
+			RateDate:               &FormattedDate{Time: time.Date(2023, time.March, 31, 0, 0, 0, 0, time.UTC)},
+			ValuationDate:          &FormattedDate{Time: time.Date(2023, time.June, 30, 0, 0, 0, 0, time.UTC)},
+			CurrencyCode:           "AUD",
+			RoundingMode:           "HALF_EVEN",
+			Fallback: &RateFallback{
+				FetchedAt:  time.Date(2023, time.June, 30, 12, 0, 0, 0, time.UTC),
+				AgeSeconds: 90,
+			},
+		},
+	}
+
+	record := csvRecord(summary)
+
+	assert.Len(t, record, len(csvHeader))
+	assert.Equal(t, []string{"*txn-id*", "*description*", "2023-05-01", "100", "154", "1.542", "2023-03-31", "2023-06-30",
+		"AUD", "HALF_EVEN", "2023-06-30T12:00:00Z", "90", "", "", ""}, record)
+}
@@ -0,0 +1,81 @@
+package transaction
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// savedIdempotencyRecord is a single line of the log and snapshot of a FileIdempotencyStore, holding an idempotency
+// record along with its key and the time at which it is to be forgotten.
+type savedIdempotencyRecord struct {
+	Key         string        `json:"key"`
+	Fingerprint string        `json:"fingerprint"`
+	Response    StoreResponse `json:"response"`
+	ExpiresAt   time.Time     `json:"expiresAt"`
+}
+
+// NewFileIdempotencyStore creates a FileIdempotencyStore that keeps its files in the supplied directory, creating the
+// directory if it does not exist, and remembers each key for the supplied window.  Any previously saved records whose
+// window has not passed are restored by loading the latest snapshot and replaying the log written since.  A new
+// snapshot is taken after every snapshotInterval saves.
+func NewFileIdempotencyStore(dir string, window time.Duration, snapshotInterval int) (*FileIdempotencyStore, error) {
+	memory := NewInMemoryIdempotencyStore(window)
+	journal, err := openJournal(dir, "idempotency", snapshotInterval, memory.restore, memory.restore)
+	if err != nil {
+		return nil, fmt.Errorf("restoring idempotency keys: %w", err)
+	}
+	return &FileIdempotencyStore{
+		memory:  memory,
+		journal: journal,
+	}, nil
+}
+
+// FileIdempotencyStore stores idempotency records durably on the local file system, so that keys are still
+// remembered for the whole window after the application is restarted.  As with the FileRepository, every save is
+// appended to a log file and synced to disk before it is acknowledged, and the log is periodically compacted into a
+// snapshot, which holds only the records whose window has not passed.  Lookups are served from an
+// InMemoryIdempotencyStore that is rebuilt from the snapshot and the log when the store is opened.  Only a single
+// FileIdempotencyStore should use a given directory at any one time.
+type FileIdempotencyStore struct {
+	memory *InMemoryIdempotencyStore
+
+	mu      sync.Mutex
+	journal *journal[savedIdempotencyRecord, savedIdempotencyRecord]
+}
+
+// Save appends the record to the log under the supplied key and returns an error if one occurred.  The record is only
+// visible to FindByKey once it has been synced to disk.  ErrIdempotencyKeyExists is returned if a record whose window
+// has not passed is already stored with the key.
+func (s *FileIdempotencyStore) Save(key string, record IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	saved, err := s.memory.prepare(key, record)
+	if err != nil {
+		return err
+	}
+	if err := s.journal.append(saved); err != nil {
+		return err
+	}
+	s.memory.restore(saved)
+	if s.journal.snapshotDue() {
+		if err := s.journal.snapshot(s.memory.all()); err != nil {
+			log.Printf("unable to snapshot idempotency keys: %v\n", err)
+		}
+	}
+	return nil
+}
+
+// FindByKey fetches the record stored under the supplied key.  An empty IdempotencyRecord will be returned if nothing
+// has been stored with the key within the configured window.
+func (s *FileIdempotencyStore) FindByKey(key string) (IdempotencyRecord, error) {
+	return s.memory.FindByKey(key)
+}
+
+// Close closes the log file.  The store must not be used once it has been closed.
+func (s *FileIdempotencyStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.journal.Close()
+}
@@ -0,0 +1,116 @@
+package transaction
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileIdempotencyStore(t *testing.T) {
+	record := IdempotencyRecord{
+		Fingerprint: "*fingerprint*",
+		Response:    StoreResponse{ID: "*txn-id*"},
+	}
+	open := func(t *testing.T, dir string, snapshotInterval int) *FileIdempotencyStore {
+		store, err := NewFileIdempotencyStore(dir, time.Hour, snapshotInterval)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { store.Close() })
+		return store
+	}
+	lines := func(t *testing.T, path string) int {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return bytes.Count(content, []byte("\n"))
+	}
+
+	t.Run("should return the record saved with the key", func(t *testing.T) {
+		store := open(t, t.TempDir(), 10)
+
+		assert.Nil(t, store.Save("*key*", record))
+		found, err := store.FindByKey("*key*")
+		assert.Nil(t, err)
+		assert.Equal(t, record, found)
+	})
+	t.Run("should restore the records saved before a restart", func(t *testing.T) {
+		dir := t.TempDir()
+		store := open(t, dir, 10)
+		store.Save("*key*", record)
+		store.Close()
+
+		found, err := open(t, dir, 10).FindByKey("*key*")
+		assert.Nil(t, err)
+		assert.Equal(t, record, found)
+	})
+	t.Run("should not restore records whose window has passed", func(t *testing.T) {
+		dir := t.TempDir()
+		store := open(t, dir, 10)
+		store.memory.now = func() time.Time { return time.Now().Add(-2 * time.Hour) }
+		store.Save("*key*", record)
+		store.Close()
+
+		found, err := open(t, dir, 10).FindByKey("*key*")
+		assert.Nil(t, err)
+		assert.Equal(t, IdempotencyRecord{}, found)
+	})
+	t.Run("should snapshot the records still remembered and empty the log", func(t *testing.T) {
+		dir := t.TempDir()
+		store := open(t, dir, 3)
+		store.memory.now = func() time.Time { return time.Now().Add(-2 * time.Hour) }
+		store.Save("*expired-key-1*", record)
+		store.Save("*expired-key-2*", record)
+		store.memory.now = time.Now
+		store.Save("*key*", record)
+
+		assert.Equal(t, 0, lines(t, filepath.Join(dir, "idempotency.log")))
+		assert.Equal(t, 1, lines(t, filepath.Join(dir, "idempotency.snapshot")))
+		store.Close()
+		found, err := open(t, dir, 3).FindByKey("*key*")
+		assert.Nil(t, err)
+		assert.Equal(t, record, found)
+	})
+	t.Run("should not replace the record saved with the key", func(t *testing.T) {
+		store := open(t, t.TempDir(), 10)
+		store.Save("*key*", record)
+
+		another := IdempotencyRecord{Fingerprint: "*another-fingerprint*", Response: StoreResponse{ID: "*another-id*"}}
+		assert.Equal(t, ErrIdempotencyKeyExists, store.Save("*key*", another))
+		found, err := store.FindByKey("*key*")
+		assert.Nil(t, err)
+		assert.Equal(t, record, found)
+	})
+	t.Run("should discard an incomplete final log record", func(t *testing.T) {
+		dir := t.TempDir()
+		store := open(t, dir, 10)
+		store.Save("*key*", record)
+		store.Close()
+		file, err := os.OpenFile(filepath.Join(dir, "idempotency.log"), os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		file.WriteString(`{"key":"*another-key*","finger`)
+		file.Close()
+
+		reopened := open(t, dir, 10)
+		found, err := reopened.FindByKey("*key*")
+		assert.Nil(t, err)
+		assert.Equal(t, record, found)
+		found, err = reopened.FindByKey("*another-key*")
+		assert.Nil(t, err)
+		assert.Equal(t, IdempotencyRecord{}, found)
+	})
+	t.Run("should return an error when a record before the end of the log is unreadable", func(t *testing.T) {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, "idempotency.log"), []byte("not json\n{}\n"), 0o644)
+
+		_, err := NewFileIdempotencyStore(dir, time.Hour, 10)
+		assert.ErrorContains(t, err, "restoring idempotency keys: reading log at offset 0")
+	})
+}
@@ -0,0 +1,147 @@
+package transaction
+
+import (
+	"log"
+	"sync"
+)
+
+// NewFileRepository creates a FileRepository that keeps its files in the supplied directory, creating the directory
+// if it does not exist.  Any previously stored transactions are restored by loading the latest snapshot and replaying
+// the log written since.  A new snapshot is taken after every snapshotInterval writes to the log.
+func NewFileRepository(dir string, snapshotInterval int, idGenerator IDGenerator) (*FileRepository, error) {
+	memory := NewInMemoryRepository(idGenerator)
+	journal, err := openJournal(dir, "transactions", snapshotInterval,
+		func(txn Entity) { memory.put(txn) },
+		func(record logRecord) { memory.put(record.entities()...) })
+	if err != nil {
+		return nil, err
+	}
+	return &FileRepository{
+		idGenerator: idGenerator,
+		memory:      memory,
+		journal:     journal,
+	}, nil
+}
+
+// FileRepository stores transactions durably on the local file system.  Every write is appended to a log file and
+// synced to disk before it is acknowledged, and the log is periodically compacted into a snapshot of all transactions.
+// Reads are served from an InMemoryRepository that is rebuilt from the snapshot and the log when the repository is
+// opened.  Only a single FileRepository should use a given directory at any one time.
+type FileRepository struct {
+	idGenerator IDGenerator
+	memory      *InMemoryRepository
+
+	mu      sync.Mutex
+	journal *journal[Entity, logRecord]
+}
+
+// logRecord is a single line of the log, holding the state of a transaction at the time it was written, or of a
+// batch of transactions that were written together.  Since a line is either replayed in full or discarded, a batch is
+// stored atomically.
+type logRecord struct {
+	Entity   *Entity  `json:"entity,omitempty"`
+	Entities []Entity `json:"entities,omitempty"`
+}
+
+// newLogRecord creates the logRecord holding the supplied transactions.
+func newLogRecord(txns []Entity) logRecord {
+	if len(txns) == 1 {
+		return logRecord{Entity: &txns[0]}
+	}
+	return logRecord{Entities: txns}
+}
+
+// entities returns the transactions held in the logRecord.
+func (l logRecord) entities() []Entity {
+	if l.Entity != nil {
+		return []Entity{*l.Entity}
+	}
+	return l.Entities
+}
+
+// Save generates a new id for the transaction, appends it to the log and returns it, or returns an error if one
+// occurred.  The transaction is only visible to FindByID once it has been synced to disk.
+func (r *FileRepository) Save(txn Entity) (Entity, error) {
+	id, err := r.idGenerator.NewID()
+	if err != nil {
+		return Entity{}, err
+	}
+	txn.ID = id
+	txn.Version = initialVersion
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.write(txn); err != nil {
+		return Entity{}, err
+	}
+	return txn, nil
+}
+
+// SaveAll generates a new id for each of the transactions, appends them to the log as a single record and returns them,
+// or returns an error if one occurred.  Either all of the transactions are stored or, should an error occur, none of
+// them are.
+func (r *FileRepository) SaveAll(txns []Entity) ([]Entity, error) {
+	saved, err := withNewIDs(r.idGenerator, txns)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.write(saved...); err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+// Update appends the changed transaction to the log, provided the stored version matches the supplied version, and
+// returns it with its version incremented.  ErrNotFound is returned if no such transaction is stored and
+// ErrVersionConflict is returned if the versions do not match.
+func (r *FileRepository) Update(txn Entity) (Entity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, err := r.memory.FindByID(txn.ID)
+	if err != nil {
+		return Entity{}, err
+	}
+	updated, err := nextVersion(current, txn)
+	if err != nil {
+		return Entity{}, err
+	}
+	if err := r.write(updated); err != nil {
+		return Entity{}, err
+	}
+	return updated, nil
+}
+
+// FindByID fetches the transaction with the provided id.  An empty Entity will be returned if a transaction with the
+// supplied id is not found.
+func (r *FileRepository) FindByID(id string) (Entity, error) {
+	return r.memory.FindByID(id)
+}
+
+// Query lists the transactions satisfying the supplied Query, in the order it specifies.
+func (r *FileRepository) Query(query Query) ([]Entity, error) {
+	return r.memory.Query(query)
+}
+
+// Close closes the log file.  The repository must not be used once it has been closed.
+func (r *FileRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.journal.Close()
+}
+
+// write appends the transactions to the log as a single record, syncs it to disk and applies it to the in memory view,
+// taking a snapshot if enough writes have accumulated.  A failed snapshot does not fail the write, which is already
+// durable, and is retried on the next write.  The caller must hold the lock.
+func (r *FileRepository) write(txns ...Entity) error {
+	if err := r.journal.append(newLogRecord(txns)); err != nil {
+		return err
+	}
+	r.memory.put(txns...)
+	if r.journal.snapshotDue() {
+		if err := r.journal.snapshot(r.memory.all()); err != nil {
+			log.Printf("unable to snapshot transactions: %v\n", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package transaction_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"transaction-service/internal/date"
+	"transaction-service/internal/id"
+	"transaction-service/internal/transaction"
+)
+
+func TestFileRepository(t *testing.T) {
+	t.Run("store", func(t *testing.T) {
+		t.Run("success - should return entity with new id and make it available to find by id", func(t *testing.T) {
+			repo := openFileRepository(t, t.TempDir(), 10)
+
+			entity, err := repo.Save(transaction.Entity{
+				Description:     "*description*",
+				TransactionDate: date.NewInUTC(2023, time.January, 23),
+				AmountInCents:   5432,
+			})
+			assert.Nil(t, err)
+			wantEntity := transaction.Entity{
+				ID:              "sequentialID-1",
+				Description:     "*description*",
+				TransactionDate: date.NewInUTC(2023, time.January, 23),
+				AmountInCents:   5432,
+				Version:         1,
+			}
+			assert.Equal(t, wantEntity, entity)
+			assert.Equal(t, wantEntity, findByID(t, repo, "sequentialID-1"))
+		})
+		t.Run("failure - should return empty transaction and the error details", func(t *testing.T) {
+			repo, err := transaction.NewFileRepository(t.TempDir(), 10, &alwaysErrorIDGenerator{})
+			assert.Nil(t, err)
+			defer repo.Close()
+
+			entity, err := repo.Save(transaction.Entity{Description: "*description*"})
+			assert.EqualError(t, err, "problem")
+			assert.Equal(t, transaction.Entity{}, entity)
+		})
+	})
+
+	t.Run("find by id", func(t *testing.T) {
+		t.Run("should return empty entity when nothing has been stored with the provided id", func(t *testing.T) {
+			repo := openFileRepository(t, t.TempDir(), 10)
+
+			assert.Equal(t, transaction.Entity{}, findByID(t, repo, "sequentialID-1"))
+		})
+	})
+
+	t.Run("store all", func(t *testing.T) {
+		testSaveAll(t, func(t *testing.T) transaction.Repository {
+			return openFileRepository(t, t.TempDir(), 10)
+		})
+		t.Run("should restore a batch of transactions written as a single log record", func(t *testing.T) {
+			dir := t.TempDir()
+			repo := openFileRepository(t, dir, 10)
+			repo.SaveAll([]transaction.Entity{{Description: "one"}, {Description: "two"}})
+			repo.Close()
+
+			reopened := openFileRepository(t, dir, 10)
+			assert.Equal(t, "one", findByID(t, reopened, "sequentialID-1").Description)
+			assert.Equal(t, "two", findByID(t, reopened, "sequentialID-2").Description)
+		})
+	})
+
+	t.Run("query", func(t *testing.T) {
+		testQuery(t, func(t *testing.T) transaction.Repository {
+			return openFileRepository(t, t.TempDir(), 10)
+		})
+	})
+
+	t.Run("update", func(t *testing.T) {
+		testUpdate(t, func(t *testing.T) transaction.Repository {
+			return openFileRepository(t, t.TempDir(), 10)
+		})
+		t.Run("should restore the latest version of a changed transaction", func(t *testing.T) {
+			dir := t.TempDir()
+			repo := openFileRepository(t, dir, 10)
+			saved, _ := repo.Save(transaction.Entity{Description: "one"})
+			saved.Description = "changed"
+			repo.Update(saved)
+			repo.Close()
+
+			reopened := openFileRepository(t, dir, 10)
+			restored := findByID(t, reopened, "sequentialID-1")
+			assert.Equal(t, "changed", restored.Description)
+			assert.Equal(t, 2, restored.Version)
+		})
+	})
+
+	t.Run("restart", func(t *testing.T) {
+		tcs := []struct {
+			name             string
+			snapshotInterval int
+		}{
+			{
+				name:             "should restore transactions from the log",
+				snapshotInterval: 10,
+			},
+			{
+				name:             "should restore transactions from a snapshot",
+				snapshotInterval: 3,
+			},
+			{
+				name:             "should restore transactions from a snapshot and the log written since",
+				snapshotInterval: 2,
+			},
+		}
+		for _, tc := range tcs {
+			t.Run(tc.name, func(t *testing.T) {
+				dir := t.TempDir()
+				repo := openFileRepository(t, dir, tc.snapshotInterval)
+				for _, description := range []string{"one", "two", "three"} {
+					_, err := repo.Save(transaction.Entity{Description: description})
+					assert.Nil(t, err)
+				}
+				assert.Nil(t, repo.Close())
+
+				reopened := openFileRepository(t, dir, tc.snapshotInterval)
+				assert.Equal(t, "one", findByID(t, reopened, "sequentialID-1").Description)
+				assert.Equal(t, "two", findByID(t, reopened, "sequentialID-2").Description)
+				assert.Equal(t, "three", findByID(t, reopened, "sequentialID-3").Description)
+			})
+		}
+	})
+
+	t.Run("compaction - should empty the log once a snapshot has been taken", func(t *testing.T) {
+		dir := t.TempDir()
+		repo := openFileRepository(t, dir, 2)
+		repo.Save(transaction.Entity{Description: "one"})
+		assert.NotZero(t, fileSize(t, filepath.Join(dir, "transactions.log")))
+
+		repo.Save(transaction.Entity{Description: "two"})
+		assert.Zero(t, fileSize(t, filepath.Join(dir, "transactions.log")))
+		assert.NotZero(t, fileSize(t, filepath.Join(dir, "transactions.snapshot")))
+	})
+
+	t.Run("torn write", func(t *testing.T) {
+		t.Run("should discard an incomplete final log record", func(t *testing.T) {
+			dir := t.TempDir()
+			repo := openFileRepository(t, dir, 10)
+			repo.Save(transaction.Entity{Description: "one"})
+			repo.Close()
+			logPath := filepath.Join(dir, "transactions.log")
+			completeSize := fileSize(t, logPath)
+			appendToFile(t, logPath, `{"entity":{"id":"sequentialID-2","desc`)
+
+			reopened := openFileRepository(t, dir, 10)
+			assert.Equal(t, "one", findByID(t, reopened, "sequentialID-1").Description)
+			assert.Equal(t, transaction.Entity{}, findByID(t, reopened, "sequentialID-2"))
+			assert.Equal(t, completeSize, fileSize(t, logPath))
+		})
+		t.Run("should discard every transaction of an incomplete final batch record", func(t *testing.T) {
+			dir := t.TempDir()
+			logPath := filepath.Join(dir, "transactions.log")
+			appendToFile(t, logPath, `{"entities":[{"id":"sequentialID-1","description":"one"},{"id":"sequentialID-2"`)
+
+			reopened := openFileRepository(t, dir, 10)
+			assert.Equal(t, transaction.Entity{}, findByID(t, reopened, "sequentialID-1"))
+			assert.Zero(t, fileSize(t, logPath))
+		})
+		t.Run("should return an error when a record before the end of the log is unreadable", func(t *testing.T) {
+			dir := t.TempDir()
+			appendToFile(t, filepath.Join(dir, "transactions.log"), "rubbish\n{\"entity\":{\"id\":\"abc\"}}\n")
+
+			_, err := transaction.NewFileRepository(dir, 10, id.NewSequentialGenerator())
+			assert.ErrorContains(t, err, "reading log at offset 0")
+		})
+	})
+}
+
+func openFileRepository(t *testing.T, dir string, snapshotInterval int) *transaction.FileRepository {
+	repo, err := transaction.NewFileRepository(dir, snapshotInterval, id.NewSequentialGenerator())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+func fileSize(t *testing.T, path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info.Size()
+}
+
+func appendToFile(t *testing.T, path, content string) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -3,13 +3,26 @@ package transaction
 import (
 	"context"
 	"errors"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
+	"transaction-service/internal/business"
 	"transaction-service/internal/errorhandling"
 )
 
+const (
+	// IdempotencyKeyHeader is the http header with which a client may supply an idempotency key when storing a
+	// transaction.
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	ifMatchRequired           = "IF_MATCH_REQUIRED"
+	exportFormatNotAcceptable = "EXPORT_FORMAT_NOT_ACCEPTABLE"
+)
+
 // Storer is the interface of the transaction business service expected by the handler that deals with storing
 // transactions.
 type Storer interface {
@@ -23,7 +36,8 @@ func ConfigureStoreHandler(router *gin.Engine, service Storer) {
 }
 
 // NewStoreHandler is responsible for mapping the incoming 'fetch transaction' http request into the call to the
-// business service and mapping the result back to a http response.
+// business service and mapping the result back to a http response.  An idempotency key may be supplied through the
+// IdempotencyKeyHeader.
 func NewStoreHandler(service Storer) func(ctx *gin.Context) {
 	return func(ctx *gin.Context) {
 		var request StoreRequest
@@ -31,6 +45,7 @@ func NewStoreHandler(service Storer) func(ctx *gin.Context) {
 			ctx.Error(errors.New(errorhandling.BadRequest))
 			return
 		}
+		request.IdempotencyKey = ctx.GetHeader(IdempotencyKeyHeader)
 		response, err := service.Store(request)
 		if err != nil {
 			ctx.Error(err)
@@ -40,10 +55,46 @@ func NewStoreHandler(service Storer) func(ctx *gin.Context) {
 	}
 }
 
+// BatchStorer is the interface of the transaction business service expected by the handler that deals with storing
+// transactions in batch.
+type BatchStorer interface {
+	StoreBatch(request BatchRequest) (BatchResponse, error)
+}
+
+// ConfigureBatchStoreHandler configures the supplied router with a handler that uses the supplied service to store
+// transactions in batch.
+func ConfigureBatchStoreHandler(router *gin.Engine, service BatchStorer) {
+	router.POST("/transactions/batch", NewBatchStoreHandler(service))
+}
+
+// NewBatchStoreHandler is responsible for mapping the incoming 'store transactions in batch' http request, whose body
+// is an array of transactions, into the call to the business service and mapping the result back to a http response.
+// The batch is stored atomically when the 'atomic' query parameter is 'true', in which case the response has a 422
+// status should nothing be stored because some transactions are invalid.
+func NewBatchStoreHandler(service BatchStorer) func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		request := BatchRequest{Atomic: ctx.Query("atomic") == "true"}
+		if err := ctx.Bind(&request.Transactions); err != nil {
+			ctx.Error(errors.New(errorhandling.BadRequest))
+			return
+		}
+		response, err := service.StoreBatch(request)
+		if err != nil {
+			ctx.Error(err)
+			return
+		}
+		status := http.StatusOK
+		if request.Atomic && response.Failed > 0 {
+			status = http.StatusUnprocessableEntity
+		}
+		ctx.JSON(status, response)
+	}
+}
+
 // Fetcher is the interface of the transaction business service expected by the handler that deals with fetching
 // transactions.
 type Fetcher interface {
-	Fetch(ctx context.Context, transactionID, country string) (FetchResponse, error)
+	Fetch(ctx context.Context, request FetchRequest) (FetchResponse, error)
 }
 
 // ConfigureFetchHandler configures the supplied router with a fetch handler that uses the supplied service to fetch
@@ -53,16 +104,286 @@ func ConfigureFetchHandler(router *gin.Engine, service Fetcher) {
 }
 
 // NewFetchHandler is responsbile for mapping the incoming 'store transaction' http request into the call to the
-// business service and mapping the result back to a http response.
+// business service and mapping the result back to a http response.  The 'country' query parameter may be repeated to
+// convert the amount to the currencies of several countries.  A voided transaction is only returned when the
+// 'includeVoided' query parameter is 'true'.  The amount is converted as at the date given by the 'asOf' query parameter
+// rather than the transaction date if supplied, and the rules by which the exchange rate is selected may be overridden
+// by the 'ratePolicy' and 'rateWindowMonths' query parameters.
 func NewFetchHandler(service Fetcher) func(ctx *gin.Context) {
 	return func(ctx *gin.Context) {
-		id := ctx.Param("id")
-		country := ctx.Query("country")
-		response, err := service.Fetch(ctx, id, country)
+		request := FetchRequest{
+			TransactionID:    ctx.Param("id"),
+			Currency:         ctx.Query("currency"),
+			IncludeVoided:    ctx.Query("includeVoided") == "true",
+			AsOf:             queryParam(ctx, "asOf"),
+			RatePolicy:       queryParam(ctx, "ratePolicy"),
+			RateWindowMonths: queryParam(ctx, "rateWindowMonths"),
+		}
+		if countries := ctx.QueryArray("country"); len(countries) > 1 {
+			request.Countries = countries
+		} else {
+			request.Country = ctx.Query("country")
+		}
+		response, err := service.Fetch(ctx, request)
+		if err != nil {
+			ctx.Error(err)
+			return
+		}
+		ctx.Header("ETag", formatETag(response.Version))
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+// Lister is the interface of the transaction business service expected by the handler that deals with listing
+// transactions.
+type Lister interface {
+	List(ctx context.Context, request ListRequest) (ListResponse, error)
+}
+
+// ConfigureListHandler configures the supplied router with a list handler that uses the supplied service to list
+// transactions.
+func ConfigureListHandler(router *gin.Engine, service Lister) {
+	router.GET("/transactions", NewListHandler(service))
+}
+
+// NewListHandler is responsible for mapping the incoming 'list transactions' http request into the call to the
+// business service and mapping the result back to a http response.  The filters, sort, page size, cursor, country,
+// valuation date and exchange rate rules are all taken from query parameters.
+func NewListHandler(service Lister) func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		request := ListRequest{
+			FromDate:         queryParam(ctx, "fromDate"),
+			ToDate:           queryParam(ctx, "toDate"),
+			MinAmountInCents: queryParam(ctx, "minAmountInCents"),
+			MaxAmountInCents: queryParam(ctx, "maxAmountInCents"),
+			Description:      queryParam(ctx, "description"),
+			IncludeVoided:    ctx.Query("includeVoided") == "true",
+			Sort:             queryParam(ctx, "sort"),
+			Limit:            queryParam(ctx, "limit"),
+			Cursor:           queryParam(ctx, "cursor"),
+			Country:          queryParam(ctx, "country"),
+			AsOf:             queryParam(ctx, "asOf"),
+			RatePolicy:       queryParam(ctx, "ratePolicy"),
+			RateWindowMonths: queryParam(ctx, "rateWindowMonths"),
+		}
+		response, err := service.List(ctx, request)
+		if err != nil {
+			ctx.Error(err)
+			return
+		}
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+// queryParam returns the value of the supplied query parameter, or nil if it is not supplied.
+func queryParam(ctx *gin.Context, key string) *string {
+	value, ok := ctx.GetQuery(key)
+	if !ok {
+		return nil
+	}
+	return &value
+}
+
+// Exporter is the interface of the transaction business service expected by the handler that deals with exporting
+// transactions.
+type Exporter interface {
+	Export(ctx context.Context, request ExportRequest, write func(Summary) error) error
+}
+
+// ConfigureExportHandler configures the supplied router with an export handler that uses the supplied service to
+// export transactions.
+func ConfigureExportHandler(router *gin.Engine, service Exporter) {
+	router.GET("/transactions/export", NewExportHandler(service))
+}
+
+// NewExportHandler is responsible for mapping the incoming 'export transactions' http request into the call to the
+// business service and streaming the exported transactions back in the http response, as CSV or newline delimited
+// JSON according to the Accept header.  The filters, sort and country are taken from query parameters, as for listing
+// transactions.  Once the first transaction has been written the response status can no longer be changed, so should
+// the export then fail the connection is closed, letting the client know the export is incomplete.
+func NewExportHandler(service Exporter) func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		format, ok := negotiateExportFormat(ctx.GetHeader("Accept"))
+		if !ok {
+			ctx.Error(&business.Error{Message: exportFormatNotAcceptable, Kind: business.NotAcceptable})
+			return
+		}
+		request := ExportRequest{
+			FromDate:         queryParam(ctx, "fromDate"),
+			ToDate:           queryParam(ctx, "toDate"),
+			MinAmountInCents: queryParam(ctx, "minAmountInCents"),
+			MaxAmountInCents: queryParam(ctx, "maxAmountInCents"),
+			Description:      queryParam(ctx, "description"),
+			IncludeVoided:    ctx.Query("includeVoided") == "true",
+			Sort:             queryParam(ctx, "sort"),
+			Country:          queryParam(ctx, "country"),
+			AsOf:             queryParam(ctx, "asOf"),
+			RatePolicy:       queryParam(ctx, "ratePolicy"),
+			RateWindowMonths: queryParam(ctx, "rateWindowMonths"),
+		}
+		writer := newExportWriter(ctx, format)
+		err := service.Export(ctx, request, writer.write)
+		if err == nil {
+			err = writer.finish()
+		}
+		if err == nil {
+			return
+		}
+		if !writer.started {
+			ctx.Error(err)
+			return
+		}
+		log.Printf("export failed after it was started: %v\n", err)
+		abortConnection(ctx)
+	}
+}
+
+// abortConnection closes the connection of the supplied request without completing the response, so that the client
+// can tell the response is incomplete.
+func abortConnection(ctx *gin.Context) {
+	ctx.Abort()
+	conn, _, err := ctx.Writer.Hijack()
+	if err != nil {
+		return
+	}
+	conn.Close()
+}
+
+// Updater is the interface of the transaction business service expected by the handlers that deal with updating
+// transactions.
+type Updater interface {
+	Replace(transactionID string, version int, transaction StoreRequest) (UpdateResponse, error)
+	Amend(transactionID string, version int, transaction StoreRequest) (UpdateResponse, error)
+}
+
+// ConfigureUpdateHandlers configures the supplied router with handlers that use the supplied service to replace (PUT)
+// and amend (PATCH) transactions.
+func ConfigureUpdateHandlers(router *gin.Engine, service Updater) {
+	router.PUT("/transaction/:id", NewUpdateHandler(service.Replace))
+	router.PATCH("/transaction/:id", NewUpdateHandler(service.Amend))
+}
+
+// NewUpdateHandler is responsible for mapping the incoming 'update transaction' http request into the call to the
+// supplied business operation and mapping the result back to a http response.  The request must carry an If-Match
+// header holding the ETag of the version being updated (or '*' to update any version), so that concurrent updates
+// cannot silently overwrite one another.  The ETag of the new version is returned in the response.
+func NewUpdateHandler(update func(transactionID string, version int, transaction StoreRequest) (UpdateResponse, error)) func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		version, err := ifMatchVersion(ctx, true)
 		if err != nil {
 			ctx.Error(err)
 			return
 		}
+		var request StoreRequest
+		if err := ctx.Bind(&request); err != nil {
+			ctx.Error(errors.New(errorhandling.BadRequest))
+			return
+		}
+		response, err := update(ctx.Param("id"), version, request)
+		if err != nil {
+			ctx.Error(err)
+			return
+		}
+		ctx.Header("ETag", formatETag(response.Version))
 		ctx.JSON(http.StatusOK, response)
 	}
 }
+
+// Voider is the interface of the transaction business service expected by the handlers that deal with voiding and
+// restoring transactions.
+type Voider interface {
+	Void(transactionID string, version int, request VoidRequest) (UpdateResponse, error)
+	Restore(transactionID string, version int) (UpdateResponse, error)
+}
+
+// ConfigureVoidHandlers configures the supplied router with handlers that use the supplied service to void (DELETE)
+// and restore transactions.
+func ConfigureVoidHandlers(router *gin.Engine, service Voider) {
+	router.DELETE("/transaction/:id", NewVoidHandler(service))
+	router.POST("/transaction/:id/restore", NewRestoreHandler(service))
+}
+
+// NewVoidHandler is responsible for mapping the incoming 'void transaction' http request into the call to the business
+// service and mapping the result back to a http response.  An If-Match header may optionally be supplied to void the
+// transaction only if it is still at that version.
+func NewVoidHandler(service Voider) func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		version, err := ifMatchVersion(ctx, false)
+		if err != nil {
+			ctx.Error(err)
+			return
+		}
+		var request VoidRequest
+		if err := ctx.Bind(&request); err != nil {
+			ctx.Error(errors.New(errorhandling.BadRequest))
+			return
+		}
+		response, err := service.Void(ctx.Param("id"), version, request)
+		if err != nil {
+			ctx.Error(err)
+			return
+		}
+		ctx.Header("ETag", formatETag(response.Version))
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+// NewRestoreHandler is responsible for mapping the incoming 'restore transaction' http request into the call to the
+// business service and mapping the result back to a http response.  An If-Match header may optionally be supplied to
+// restore the transaction only if it is still at that version.
+func NewRestoreHandler(service Voider) func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		version, err := ifMatchVersion(ctx, false)
+		if err != nil {
+			ctx.Error(err)
+			return
+		}
+		response, err := service.Restore(ctx.Param("id"), version)
+		if err != nil {
+			ctx.Error(err)
+			return
+		}
+		ctx.Header("ETag", formatETag(response.Version))
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+// ifMatchVersion returns the version of the transaction held in the request's If-Match header.  When the header is
+// absent, an error is returned if it is required, otherwise AnyVersion is returned.
+func ifMatchVersion(ctx *gin.Context, required bool) (int, error) {
+	ifMatch := ctx.GetHeader("If-Match")
+	if ifMatch == "" {
+		if required {
+			return 0, &business.Error{Message: ifMatchRequired, Kind: business.PreconditionRequired}
+		}
+		return AnyVersion, nil
+	}
+	version, ok := parseETag(ifMatch)
+	if !ok {
+		return 0, &business.Error{Message: versionMismatch, Kind: business.PreconditionFailed}
+	}
+	return version, nil
+}
+
+// formatETag returns the (strong) ETag representing the supplied version of a transaction.
+func formatETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// parseETag returns the version represented by the supplied If-Match header value, or AnyVersion for '*'.  It reports
+// false if the value is not an ETag produced by formatETag, since such a value can never match.
+func parseETag(value string) (int, bool) {
+	value = strings.TrimSpace(value)
+	if value == "*" {
+		return AnyVersion, true
+	}
+	unquoted, err := strconv.Unquote(value)
+	if err != nil {
+		return 0, false
+	}
+	version, err := strconv.Atoi(unquoted)
+	if err != nil || version < initialVersion {
+		return 0, false
+	}
+	return version, true
+}
@@ -12,7 +12,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"transaction-service/internal/business"
 	"transaction-service/internal/date"
+	"transaction-service/internal/errorhandling"
 	"transaction-service/internal/transaction"
 )
 
@@ -23,6 +25,7 @@ var (
 
 func setUpHandlerTest() {
 	router = gin.Default()
+	router.Use(errorhandling.NewMiddleware)
 	rr = httptest.NewRecorder()
 }
 
@@ -51,21 +54,132 @@ func TestStoreHandler(t *testing.T) {
 	mockStorer.AssertExpectations(t)
 }
 
+func TestStoreHandlerWithIdempotencyKey(t *testing.T) {
+	setUpHandlerTest()
+	mockStorer := &MockStorer{}
+	transaction.ConfigureStoreHandler(router, mockStorer)
+
+	mockStorer.On("Store", transaction.StoreRequest{
+		Description:     stringPtr("*description*"),
+		TransactionDate: stringPtr("2023-05-01"),
+		AmountInCents:   intPtr(100),
+		IdempotencyKey:  "*key*",
+	}).Return(transaction.StoreResponse{
+		ID: "*txn-id*",
+	}, nil)
+
+	req := newPostRequest(t, "/transaction", `{
+			"description": "*description*",
+			"transactionDate": "2023-05-01",
+			"amountInCents": 100
+		}`)
+	req.Header.Add("Idempotency-Key", "*key*")
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"id": "*txn-id*"}`, rr.Body.String())
+	mockStorer.AssertExpectations(t)
+}
+
+func TestBatchStoreHandler(t *testing.T) {
+	request := []transaction.StoreRequest{
+		{
+			Description:     stringPtr("*description*"),
+			TransactionDate: stringPtr("2023-05-01"),
+			AmountInCents:   intPtr(100),
+		},
+		{
+			Description: stringPtr("*description*"),
+		},
+	}
+	body := `[
+		{"description": "*description*", "transactionDate": "2023-05-01", "amountInCents": 100},
+		{"description": "*description*"}
+	]`
+	response := transaction.BatchResponse{
+		Results: []transaction.BatchResult{
+			{Index: 0, ID: "*txn-id*"},
+			{Index: 1, Error: &business.Error{Message: "VALIDATION_ERROR"}},
+		},
+		Stored: 1,
+		Failed: 1,
+	}
+
+	t.Run("should return the outcome for each transaction", func(t *testing.T) {
+		setUpHandlerTest()
+		mockStorer := &MockBatchStorer{}
+		transaction.ConfigureBatchStoreHandler(router, mockStorer)
+		mockStorer.On("StoreBatch", transaction.BatchRequest{Transactions: request}).Return(response, nil)
+
+		router.ServeHTTP(rr, newPostRequest(t, "/transactions/batch", body))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{
+			"results": [
+				{"index": 0, "id": "*txn-id*"},
+				{"index": 1, "error": {"message": "VALIDATION_ERROR"}}
+			],
+			"stored": 1,
+			"failed": 1
+		}`, rr.Body.String())
+		mockStorer.AssertExpectations(t)
+	})
+	t.Run("should return an unprocessable status when an atomic batch has invalid transactions", func(t *testing.T) {
+		setUpHandlerTest()
+		mockStorer := &MockBatchStorer{}
+		transaction.ConfigureBatchStoreHandler(router, mockStorer)
+		atomicResponse := transaction.BatchResponse{
+			Results: []transaction.BatchResult{
+				{Index: 0},
+				{Index: 1, Error: &business.Error{Message: "VALIDATION_ERROR"}},
+			},
+			Failed: 1,
+		}
+		mockStorer.On("StoreBatch", transaction.BatchRequest{Transactions: request, Atomic: true}).
+			Return(atomicResponse, nil)
+
+		router.ServeHTTP(rr, newPostRequest(t, "/transactions/batch?atomic=true", body))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.JSONEq(t, `{
+			"results": [
+				{"index": 0},
+				{"index": 1, "error": {"message": "VALIDATION_ERROR"}}
+			],
+			"stored": 0,
+			"failed": 1
+		}`, rr.Body.String())
+		mockStorer.AssertExpectations(t)
+	})
+	t.Run("should return a bad request when the body is not an array", func(t *testing.T) {
+		setUpHandlerTest()
+		mockStorer := &MockBatchStorer{}
+		transaction.ConfigureBatchStoreHandler(router, mockStorer)
+
+		router.ServeHTTP(rr, newPostRequest(t, "/transactions/batch", `{"description": "*description*"}`))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockStorer.AssertExpectations(t)
+	})
+}
+
 func TestFetchHandler(t *testing.T) {
 	setUpHandlerTest()
 	mockFetcher := &MockFetcher{}
 	transaction.ConfigureFetchHandler(router, mockFetcher)
 
-	mockFetcher.On("Fetch", mock.Anything, "*txn-id*", "*country*").
+	mockFetcher.On("Fetch", mock.Anything, transaction.FetchRequest{TransactionID: "*txn-id*", Country: "*country*"}).
 		Return(transaction.FetchResponse{
+			Version: 7,
 			Transaction: transaction.Response{
 				ID:              "*txn-id*",
 				Description:     "*description*",
 				TransactionDate: &transaction.FormattedDate{Time: date.NewInUTC(2020, time.February, 15)},
-				Amount: transaction.Amount{
+				Amount: &transaction.Amount{
 					USDAmountInCents:       20,
 					ConvertedAmountInCents: 30,
-					ExchangeRate:           123.45,
+					ExchangeRate:           "123.45",
+					RateDate:               &transaction.FormattedDate{Time: date.NewInUTC(2019, time.December, 31)},
 				},
 			},
 		}, nil)
@@ -74,6 +188,7 @@ func TestFetchHandler(t *testing.T) {
 	router.ServeHTTP(rr, req)
 
 	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `"7"`, rr.Header().Get("ETag"))
 	assert.JSONEq(t, `{
 		"transaction": {
 			"id": "*txn-id*", 
@@ -82,13 +197,436 @@ func TestFetchHandler(t *testing.T) {
 			"amount": {
 				"usdAmountInCents": 20, 
 				"convertedAmountInCents": 30, 
-				"exchangeRate": 123.45
+				"exchangeRate": 123.45,
+				"rateDate": "2019-12-31"
+			}
+		}
+	}`, rr.Body.String())
+	mockFetcher.AssertExpectations(t)
+}
+
+func TestFetchHandlerWithSeveralCountries(t *testing.T) {
+	setUpHandlerTest()
+	mockFetcher := &MockFetcher{}
+	transaction.ConfigureFetchHandler(router, mockFetcher)
+
+	mockFetcher.On("Fetch", mock.Anything, transaction.FetchRequest{
+		TransactionID: "*txn-id*",
+		Countries:     []string{"*country-1*", "*country-2*"},
+	}).Return(transaction.FetchResponse{
+		Version: 1,
+		Transaction: transaction.Response{
+			ID:              "*txn-id*",
+			Description:     "*description*",
+			TransactionDate: &transaction.FormattedDate{Time: date.NewInUTC(2020, time.February, 15)},
+			Amounts: []transaction.CountryAmount{
+				{
+					Country: "*country-1*",
+					Amount: &transaction.Amount{
+						USDAmountInCents:       20,
+						ConvertedAmountInCents: 30,
+						ExchangeRate:           "1.5",
+						RateDate:               &transaction.FormattedDate{Time: date.NewInUTC(2019, time.December, 31)},
+					},
+				},
+				{
+					Country: "*country-2*",
+					Error:   &business.Error{Message: "UNKNOWN_COUNTRY"},
+				},
+			},
+		},
+	}, nil)
+
+	router.ServeHTTP(rr, newGetRequest(t, "/transaction/*txn-id*?country=*country-1*&country=*country-2*"))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{
+		"transaction": {
+			"id": "*txn-id*",
+			"description": "*description*",
+			"transactionDate": "2020-02-15",
+			"amounts": [
+				{
+					"country": "*country-1*",
+					"amount": {
+						"usdAmountInCents": 20,
+						"convertedAmountInCents": 30,
+						"exchangeRate": 1.5,
+						"rateDate": "2019-12-31"
+					}
+				},
+				{
+					"country": "*country-2*",
+					"error": {"message": "UNKNOWN_COUNTRY"}
+				}
+			]
+		}
+	}`, rr.Body.String())
+	mockFetcher.AssertExpectations(t)
+}
+
+func TestFetchHandlerWithCurrency(t *testing.T) {
+	setUpHandlerTest()
+	mockFetcher := &MockFetcher{}
+	transaction.ConfigureFetchHandler(router, mockFetcher)
+
+	mockFetcher.On("Fetch", mock.Anything, transaction.FetchRequest{TransactionID: "*txn-id*", Currency: "EUR"}).
+		Return(transaction.FetchResponse{
+			Version: 1,
+			Transaction: transaction.Response{
+				ID:              "*txn-id*",
+				Description:     "*description*",
+				TransactionDate: &transaction.FormattedDate{Time: date.NewInUTC(2020, time.February, 15)},
+				Amount: &transaction.Amount{
+					USDAmountInCents:       20,
+					ConvertedAmountInCents: 18,
+					ExchangeRate:           "0.92",
+					RateDate:               &transaction.FormattedDate{Time: date.NewInUTC(2019, time.December, 31)},
+					CurrencyCode:           "EUR",
+				},
+			},
+		}, nil)
+
+	router.ServeHTTP(rr, newGetRequest(t, "/transaction/*txn-id*?currency=EUR"))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{
+		"transaction": {
+			"id": "*txn-id*",
+			"description": "*description*",
+			"transactionDate": "2020-02-15",
+			"amount": {
+				"usdAmountInCents": 20,
+				"convertedAmountInCents": 18,
+				"exchangeRate": 0.92,
+				"rateDate": "2019-12-31",
+				"currencyCode": "EUR"
 			}
 		}
 	}`, rr.Body.String())
 	mockFetcher.AssertExpectations(t)
 }
 
+func TestListHandler(t *testing.T) {
+	setUpHandlerTest()
+	mockLister := &MockLister{}
+	transaction.ConfigureListHandler(router, mockLister)
+
+	mockLister.On("List", mock.Anything, transaction.ListRequest{
+		FromDate:         stringPtr("2020-02-01"),
+		MinAmountInCents: stringPtr("-10"),
+		Description:      stringPtr(""),
+		IncludeVoided:    true,
+		Sort:             stringPtr("-amountInCents"),
+		Limit:            stringPtr("1"),
+		Cursor:           stringPtr("*cursor*"),
+		Country:          stringPtr("*country*"),
+	}).Return(transaction.ListResponse{
+		Transactions: []transaction.Summary{
+			{
+				ID:              "*txn-id*",
+				Description:     "*description*",
+				TransactionDate: &transaction.FormattedDate{Time: date.NewInUTC(2020, time.February, 15)},
+				AmountInCents:   20,
+				Amount: &transaction.Amount{
+					USDAmountInCents:       20,
+					ConvertedAmountInCents: 30,
+					ExchangeRate:           "1.5",
+					RateDate:               &transaction.FormattedDate{Time: date.NewInUTC(2019, time.December, 31)},
+				},
+			},
+		},
+		NextCursor: "*next-cursor*",
+	}, nil)
+
+	req := newGetRequest(t, "/transactions?fromDate=2020-02-01&minAmountInCents=-10&description=&includeVoided=true"+
+		"&sort=-amountInCents&limit=1&cursor=*cursor*&country=*country*")
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{
+		"transactions": [
+			{
+				"id": "*txn-id*",
+				"description": "*description*",
+				"transactionDate": "2020-02-15",
+				"amountInCents": 20,
+				"amount": {
+					"usdAmountInCents": 20,
+					"convertedAmountInCents": 30,
+					"exchangeRate": 1.5,
+					"rateDate": "2019-12-31"
+				}
+			}
+		],
+		"nextCursor": "*next-cursor*"
+	}`, rr.Body.String())
+	mockLister.AssertExpectations(t)
+}
+
+func TestExportHandler(t *testing.T) {
+	request := transaction.ExportRequest{
+		FromDate: stringPtr("2020-02-01"),
+		Sort:     stringPtr("amountInCents"),
+		Country:  stringPtr("*country*"),
+	}
+	converted := transaction.Summary{
+		ID:              "*txn-id-1*",
+		Description:     "=SUM(A1:A2)",
+		TransactionDate: &transaction.FormattedDate{Time: date.NewInUTC(2020, time.February, 15)},
+		AmountInCents:   20,
+		Amount: &transaction.Amount{
+			USDAmountInCents:       20,
+			ConvertedAmountInCents: 30,
+			ExchangeRate:           "1.5",
+			RateDate:               &transaction.FormattedDate{Time: date.NewInUTC(2019, time.December, 31)},
+			ValuationDate:          &transaction.FormattedDate{Time: date.NewInUTC(2020, time.February, 15)},
+			CurrencyCode:           "*currency*",
+			RoundingMode:           "HALF_EVEN",
+		},
+	}
+	unconverted := transaction.Summary{
+		ID:              "*txn-id-2*",
+		Description:     "*description*",
+		TransactionDate: &transaction.FormattedDate{Time: date.NewInUTC(2020, time.February, 16)},
+		AmountInCents:   -5,
+		ConversionError: "UNABLE_TO_CONVERT_TO_TARGET_CURRENCY",
+	}
+	exportRows := func(rows ...transaction.Summary) func(args mock.Arguments) {
+		return func(args mock.Arguments) {
+			write := args.Get(2).(func(transaction.Summary) error)
+			for _, row := range rows {
+				write(row)
+			}
+		}
+	}
+	path := "/transactions/export?fromDate=2020-02-01&sort=amountInCents&country=*country*"
+
+	t.Run("should stream the transactions as CSV", func(t *testing.T) {
+		setUpHandlerTest()
+		mockExporter := &MockExporter{}
+		transaction.ConfigureExportHandler(router, mockExporter)
+		mockExporter.On("Export", mock.Anything, request, mock.Anything).
+			Run(exportRows(converted, unconverted)).Return(nil)
+
+		req := newGetRequest(t, path)
+		req.Header.Add("Accept", "text/csv")
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "text/csv", rr.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="transactions.csv"`, rr.Header().Get("Content-Disposition"))
+		assert.Equal(t, "id,description,transactionDate,usdAmountInCents,convertedAmountInCents,exchangeRate,rateDate,valuationDate,currencyCode,roundingMode,fallbackFetchedAt,fallbackAgeSeconds,conversionError,voidReason,voidedAt\n"+
+			"*txn-id-1*,'=SUM(A1:A2),2020-02-15,20,30,1.5,2019-12-31,2020-02-15,*currency*,HALF_EVEN,,,,,\n"+
+			"*txn-id-2*,*description*,2020-02-16,-5,,,,,,,,,UNABLE_TO_CONVERT_TO_TARGET_CURRENCY,,\n", rr.Body.String())
+		mockExporter.AssertExpectations(t)
+	})
+	t.Run("should stream the transactions as newline delimited JSON", func(t *testing.T) {
+		setUpHandlerTest()
+		mockExporter := &MockExporter{}
+		transaction.ConfigureExportHandler(router, mockExporter)
+		mockExporter.On("Export", mock.Anything, request, mock.Anything).
+			Run(exportRows(converted, unconverted)).Return(nil)
+
+		req := newGetRequest(t, path)
+		req.Header.Add("Accept", "application/x-ndjson")
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/x-ndjson", rr.Header().Get("Content-Type"))
+		lines := strings.Split(strings.TrimSuffix(rr.Body.String(), "\n"), "\n")
+		assert.Len(t, lines, 2)
+		assert.JSONEq(t, `{
+			"id": "*txn-id-1*",
+			"description": "=SUM(A1:A2)",
+			"transactionDate": "2020-02-15",
+			"amountInCents": 20,
+			"amount": {"usdAmountInCents": 20, "convertedAmountInCents": 30, "exchangeRate": 1.5, "rateDate": "2019-12-31",
+				"valuationDate": "2020-02-15", "currencyCode": "*currency*", "roundingMode": "HALF_EVEN"}
+		}`, lines[0])
+		assert.JSONEq(t, `{
+			"id": "*txn-id-2*",
+			"description": "*description*",
+			"transactionDate": "2020-02-16",
+			"amountInCents": -5,
+			"conversionError": "UNABLE_TO_CONVERT_TO_TARGET_CURRENCY"
+		}`, lines[1])
+		mockExporter.AssertExpectations(t)
+	})
+	t.Run("should write only the CSV header when there are no transactions", func(t *testing.T) {
+		setUpHandlerTest()
+		mockExporter := &MockExporter{}
+		transaction.ConfigureExportHandler(router, mockExporter)
+		mockExporter.On("Export", mock.Anything, request, mock.Anything).Return(nil)
+
+		router.ServeHTTP(rr, newGetRequest(t, path))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "id,description,transactionDate,usdAmountInCents,convertedAmountInCents,exchangeRate,rateDate,valuationDate,currencyCode,roundingMode,fallbackFetchedAt,fallbackAgeSeconds,conversionError,voidReason,voidedAt\n", rr.Body.String())
+		mockExporter.AssertExpectations(t)
+	})
+	t.Run("should report an error occurring before anything is written in the usual way", func(t *testing.T) {
+		setUpHandlerTest()
+		mockExporter := &MockExporter{}
+		transaction.ConfigureExportHandler(router, mockExporter)
+		mockExporter.On("Export", mock.Anything, request, mock.Anything).
+			Return(&business.Error{Message: "VALIDATION_ERROR"})
+
+		router.ServeHTTP(rr, newGetRequest(t, path))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.JSONEq(t, `{"message": "VALIDATION_ERROR"}`, rr.Body.String())
+		mockExporter.AssertExpectations(t)
+	})
+	t.Run("should return a not acceptable status when no acceptable format can be exported", func(t *testing.T) {
+		setUpHandlerTest()
+		mockExporter := &MockExporter{}
+		transaction.ConfigureExportHandler(router, mockExporter)
+
+		req := newGetRequest(t, path)
+		req.Header.Add("Accept", "application/pdf")
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotAcceptable, rr.Code)
+		assert.JSONEq(t, `{"message": "EXPORT_FORMAT_NOT_ACCEPTABLE"}`, rr.Body.String())
+		mockExporter.AssertExpectations(t)
+	})
+}
+
+func TestUpdateHandler(t *testing.T) {
+	body := `{"amountInCents": 100}`
+	request := transaction.StoreRequest{AmountInCents: intPtr(100)}
+
+	t.Run("success", func(t *testing.T) {
+		tcs := []struct {
+			name        string
+			method      string
+			ifMatch     string
+			wantVersion int
+		}{
+			{
+				name:        "should replace the transaction at the version in the If-Match header",
+				method:      http.MethodPut,
+				ifMatch:     `"3"`,
+				wantVersion: 3,
+			},
+			{
+				name:        "should amend the transaction at the version in the If-Match header",
+				method:      http.MethodPatch,
+				ifMatch:     `"3"`,
+				wantVersion: 3,
+			},
+			{
+				name:        "should amend the transaction at any version when the If-Match header is a wildcard",
+				method:      http.MethodPatch,
+				ifMatch:     "*",
+				wantVersion: transaction.AnyVersion,
+			},
+		}
+		for _, tc := range tcs {
+			t.Run(tc.name, func(t *testing.T) {
+				setUpHandlerTest()
+				mockUpdater := &MockUpdater{}
+				transaction.ConfigureUpdateHandlers(router, mockUpdater)
+				operation := map[string]string{http.MethodPut: "Replace", http.MethodPatch: "Amend"}[tc.method]
+				mockUpdater.On(operation, "*txn-id*", tc.wantVersion, request).
+					Return(transaction.UpdateResponse{ID: "*txn-id*", Version: 4}, nil)
+
+				req := newRequest(t, tc.method, "/transaction/*txn-id*", body)
+				req.Header.Add("If-Match", tc.ifMatch)
+				router.ServeHTTP(rr, req)
+
+				assert.Equal(t, http.StatusOK, rr.Code)
+				assert.Equal(t, `"4"`, rr.Header().Get("ETag"))
+				assert.JSONEq(t, `{"id": "*txn-id*"}`, rr.Body.String())
+				mockUpdater.AssertExpectations(t)
+			})
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		tcs := []struct {
+			name       string
+			ifMatch    string
+			wantStatus int
+			wantBody   string
+		}{
+			{
+				name:       "should require the If-Match header",
+				ifMatch:    "",
+				wantStatus: http.StatusPreconditionRequired,
+				wantBody:   `{"message": "IF_MATCH_REQUIRED"}`,
+			},
+			{
+				name:       "should fail the precondition when the If-Match header is not a transaction ETag",
+				ifMatch:    `W/"3"`,
+				wantStatus: http.StatusPreconditionFailed,
+				wantBody:   `{"message": "TRANSACTION_VERSION_MISMATCH"}`,
+			},
+		}
+		for _, tc := range tcs {
+			t.Run(tc.name, func(t *testing.T) {
+				setUpHandlerTest()
+				mockUpdater := &MockUpdater{}
+				transaction.ConfigureUpdateHandlers(router, mockUpdater)
+
+				req := newRequest(t, http.MethodPut, "/transaction/*txn-id*", body)
+				if tc.ifMatch != "" {
+					req.Header.Add("If-Match", tc.ifMatch)
+				}
+				router.ServeHTTP(rr, req)
+
+				assert.Equal(t, tc.wantStatus, rr.Code)
+				assert.JSONEq(t, tc.wantBody, rr.Body.String())
+				mockUpdater.AssertExpectations(t)
+			})
+		}
+	})
+}
+
+func TestVoidHandlers(t *testing.T) {
+	t.Run("should void the transaction with the supplied reason at any version when no If-Match header is supplied", func(t *testing.T) {
+		setUpHandlerTest()
+		mockVoider := &MockVoider{}
+		transaction.ConfigureVoidHandlers(router, mockVoider)
+		mockVoider.On("Void", "*txn-id*", transaction.AnyVersion, transaction.VoidRequest{Reason: stringPtr("*reason*")}).
+			Return(transaction.UpdateResponse{ID: "*txn-id*", Version: 2}, nil)
+
+		req := newRequest(t, http.MethodDelete, "/transaction/*txn-id*", `{"reason": "*reason*"}`)
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `"2"`, rr.Header().Get("ETag"))
+		assert.JSONEq(t, `{"id": "*txn-id*"}`, rr.Body.String())
+		mockVoider.AssertExpectations(t)
+	})
+	t.Run("should restore the transaction at the version in the If-Match header", func(t *testing.T) {
+		setUpHandlerTest()
+		mockVoider := &MockVoider{}
+		transaction.ConfigureVoidHandlers(router, mockVoider)
+		mockVoider.On("Restore", "*txn-id*", 2).
+			Return(transaction.UpdateResponse{ID: "*txn-id*", Version: 3}, nil)
+
+		req := newRequest(t, http.MethodPost, "/transaction/*txn-id*/restore", "")
+		req.Header.Add("If-Match", `"2"`)
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `"3"`, rr.Header().Get("ETag"))
+		assert.JSONEq(t, `{"id": "*txn-id*"}`, rr.Body.String())
+		mockVoider.AssertExpectations(t)
+	})
+}
+
+func newRequest(t *testing.T, method, url, body string) *http.Request {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Add("Content-Type", "application/json")
+	return req
+}
+
 func newPostRequest(t *testing.T, url, body string) *http.Request {
 	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
 	if err != nil {
@@ -119,7 +657,62 @@ type MockFetcher struct {
 	mock.Mock
 }
 
-func (m *MockFetcher) Fetch(ctx context.Context, transactionID, country string) (transaction.FetchResponse, error) {
-	args := m.Called(ctx, transactionID, country)
+func (m *MockFetcher) Fetch(ctx context.Context, request transaction.FetchRequest) (transaction.FetchResponse, error) {
+	args := m.Called(ctx, request)
 	return args.Get(0).(transaction.FetchResponse), args.Error(1)
 }
+
+type MockUpdater struct {
+	mock.Mock
+}
+
+func (m *MockUpdater) Replace(transactionID string, version int, txn transaction.StoreRequest) (transaction.UpdateResponse, error) {
+	args := m.Called(transactionID, version, txn)
+	return args.Get(0).(transaction.UpdateResponse), args.Error(1)
+}
+
+func (m *MockUpdater) Amend(transactionID string, version int, txn transaction.StoreRequest) (transaction.UpdateResponse, error) {
+	args := m.Called(transactionID, version, txn)
+	return args.Get(0).(transaction.UpdateResponse), args.Error(1)
+}
+
+type MockVoider struct {
+	mock.Mock
+}
+
+func (m *MockVoider) Void(transactionID string, version int, request transaction.VoidRequest) (transaction.UpdateResponse, error) {
+	args := m.Called(transactionID, version, request)
+	return args.Get(0).(transaction.UpdateResponse), args.Error(1)
+}
+
+func (m *MockVoider) Restore(transactionID string, version int) (transaction.UpdateResponse, error) {
+	args := m.Called(transactionID, version)
+	return args.Get(0).(transaction.UpdateResponse), args.Error(1)
+}
+
+type MockLister struct {
+	mock.Mock
+}
+
+func (m *MockLister) List(ctx context.Context, request transaction.ListRequest) (transaction.ListResponse, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(transaction.ListResponse), args.Error(1)
+}
+
+type MockBatchStorer struct {
+	mock.Mock
+}
+
+func (m *MockBatchStorer) StoreBatch(request transaction.BatchRequest) (transaction.BatchResponse, error) {
+	args := m.Called(request)
+	return args.Get(0).(transaction.BatchResponse), args.Error(1)
+}
+
+type MockExporter struct {
+	mock.Mock
+}
+
+func (m *MockExporter) Export(ctx context.Context, request transaction.ExportRequest, write func(transaction.Summary) error) error {
+	args := m.Called(ctx, request, write)
+	return args.Error(0)
+}
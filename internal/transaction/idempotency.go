@@ -0,0 +1,207 @@
+package transaction
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrIdempotencyKeyExists is returned when saving an idempotency record under a key that already holds one whose
+// window has not passed.
+var ErrIdempotencyKeyExists = errors.New("idempotency key already exists")
+
+// IdempotencyRecord represents the outcome of a 'store transaction' operation made with an idempotency key.
+type IdempotencyRecord struct {
+	// Fingerprint identifies the content of the request that was stored with the key.
+	Fingerprint string
+
+	// Response is the response returned to the request that was stored with the key.
+	Response StoreResponse
+}
+
+// NewInMemoryIdempotencyStore creates an InMemoryIdempotencyStore that remembers each key for the supplied window.
+func NewInMemoryIdempotencyStore(window time.Duration) *InMemoryIdempotencyStore {
+	return &InMemoryIdempotencyStore{
+		data:   make(map[string]idempotencyEntry),
+		window: window,
+		now:    time.Now,
+	}
+}
+
+// InMemoryIdempotencyStore stores idempotency records in an in memory map, forgetting each one once the configured
+// window has passed since it was saved.  Since every record is kept for the same window, records expire in the order in
+// which they were saved, which allows expired records to be discarded cheaply from the front of a queue.  As with the
+// InMemoryRepository, the records do not persist once the application is shut down.
+type InMemoryIdempotencyStore struct {
+	data   map[string]idempotencyEntry
+	expiry []idempotencyExpiry
+	mu     sync.Mutex
+	window time.Duration
+	now    func() time.Time
+}
+
+// idempotencyEntry holds a stored idempotency record along with the time at which it is to be forgotten.
+type idempotencyEntry struct {
+	record    IdempotencyRecord
+	expiresAt time.Time
+}
+
+// idempotencyExpiry records when the idempotency record stored under key is to be forgotten.
+type idempotencyExpiry struct {
+	key string
+	at  time.Time
+}
+
+// Save stores the record under the supplied key.  ErrIdempotencyKeyExists is returned if a record whose window has not
+// passed is already stored with the key.
+func (s *InMemoryIdempotencyStore) Save(key string, record IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.holds(key) {
+		return ErrIdempotencyKeyExists
+	}
+	s.put(key, record, s.now().Add(s.window))
+	return nil
+}
+
+// holds reports whether a record whose window has not passed is stored with the supplied key.  The caller must hold
+// the lock.
+func (s *InMemoryIdempotencyStore) holds(key string) bool {
+	s.discardExpired()
+	_, ok := s.data[key]
+	return ok
+}
+
+// put stores the record under the supplied key until the supplied time, replacing any record previously stored with
+// that key.  The caller must hold the lock.
+func (s *InMemoryIdempotencyStore) put(key string, record IdempotencyRecord, expiresAt time.Time) {
+	s.discardExpired()
+	s.data[key] = idempotencyEntry{record: record, expiresAt: expiresAt}
+	s.expiry = append(s.expiry, idempotencyExpiry{key: key, at: expiresAt})
+}
+
+// prepare returns the savedIdempotencyRecord holding the supplied record under the supplied key, to be forgotten once
+// the window has passed from now, without storing it.  ErrIdempotencyKeyExists is returned if a record whose window
+// has not passed is already stored with the key.
+func (s *InMemoryIdempotencyStore) prepare(key string, record IdempotencyRecord) (savedIdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.holds(key) {
+		return savedIdempotencyRecord{}, ErrIdempotencyKeyExists
+	}
+	return savedIdempotencyRecord{
+		Key:         key,
+		Fingerprint: record.Fingerprint,
+		Response:    record.Response,
+		ExpiresAt:   s.now().Add(s.window),
+	}, nil
+}
+
+// restore stores the saved record until the time at which it is to be forgotten, unless that has already passed.
+func (s *InMemoryIdempotencyStore) restore(saved savedIdempotencyRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !saved.ExpiresAt.After(s.now()) {
+		return
+	}
+	s.put(saved.Key, IdempotencyRecord{Fingerprint: saved.Fingerprint, Response: saved.Response}, saved.ExpiresAt)
+}
+
+// all returns every record that has not been forgotten, in the order in which they were saved.
+func (s *InMemoryIdempotencyStore) all() []savedIdempotencyRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.discardExpired()
+	var saved []savedIdempotencyRecord
+	for _, expiry := range s.expiry {
+		if entry := s.data[expiry.key]; entry.expiresAt.Equal(expiry.at) {
+			saved = append(saved, savedIdempotencyRecord{
+				Key:         expiry.key,
+				Fingerprint: entry.record.Fingerprint,
+				Response:    entry.record.Response,
+				ExpiresAt:   entry.expiresAt,
+			})
+		}
+	}
+	return saved
+}
+
+// FindByKey fetches the record stored under the supplied key.  An empty IdempotencyRecord will be returned if nothing
+// has been stored with the key within the configured window.
+func (s *InMemoryIdempotencyStore) FindByKey(key string) (IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.discardExpired()
+	return s.data[key].record, nil
+}
+
+// discardExpired forgets every record whose window has passed.  A key that has since been saved again is left alone.
+// The caller must hold the lock.
+func (s *InMemoryIdempotencyStore) discardExpired() {
+	now := s.now()
+	expired := 0
+	for _, expiry := range s.expiry {
+		if expiry.at.After(now) {
+			break
+		}
+		if s.data[expiry.key].expiresAt.Equal(expiry.at) {
+			delete(s.data, expiry.key)
+		}
+		expired++
+	}
+	s.expiry = s.expiry[expired:]
+}
+
+// keyLocks holds a mutex for each idempotency key in use, so that requests carrying the same key can be processed one
+// at a time without holding up requests carrying other keys.  The zero value is ready to use.
+type keyLocks struct {
+	mu    sync.Mutex
+	locks map[string]*keyLock
+}
+
+// keyLock is the mutex of a key, along with the number of requests holding or waiting for it.
+type keyLock struct {
+	mu    sync.Mutex
+	users int
+}
+
+// lock waits until no other request holds the lock of the supplied key, then takes it.  The returned function releases
+// the lock, forgetting it once no other request is waiting for it.
+func (l *keyLocks) lock(key string) (unlock func()) {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*keyLock)
+	}
+	lock, ok := l.locks[key]
+	if !ok {
+		lock = &keyLock{}
+		l.locks[key] = lock
+	}
+	lock.users++
+	l.mu.Unlock()
+
+	lock.mu.Lock()
+	return func() {
+		lock.mu.Unlock()
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		lock.users--
+		if lock.users == 0 {
+			delete(l.locks, key)
+		}
+	}
+}
+
+// fingerprint returns a digest of the content of the supplied StoreRequest, used to tell whether a request replayed
+// with an idempotency key is identical to the one originally stored with it.
+func fingerprint(txn StoreRequest) (string, error) {
+	content, err := json.Marshal(txn)
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256(content)
+	return hex.EncodeToString(digest[:]), nil
+}
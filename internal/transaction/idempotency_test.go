@@ -0,0 +1,133 @@
+package transaction
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInMemoryIdempotencyStore(t *testing.T) {
+	now := time.Date(2023, time.May, 1, 12, 0, 0, 0, time.UTC)
+	newStore := func() *InMemoryIdempotencyStore {
+		store := NewInMemoryIdempotencyStore(time.Hour)
+		store.now = func() time.Time { return now }
+		return store
+	}
+	record := IdempotencyRecord{
+		Fingerprint: "*fingerprint*",
+		Response:    StoreResponse{ID: "*txn-id*"},
+	}
+
+	t.Run("should return the record saved with the key while within the window", func(t *testing.T) {
+		store := newStore()
+		store.Save("*key*", record)
+		store.now = func() time.Time { return now.Add(59 * time.Minute) }
+
+		found, err := store.FindByKey("*key*")
+		assert.Nil(t, err)
+		assert.Equal(t, record, found)
+	})
+	t.Run("should return an empty record once the window has passed", func(t *testing.T) {
+		store := newStore()
+		store.Save("*key*", record)
+		store.now = func() time.Time { return now.Add(time.Hour) }
+
+		found, err := store.FindByKey("*key*")
+		assert.Nil(t, err)
+		assert.Equal(t, IdempotencyRecord{}, found)
+		assert.Empty(t, store.data)
+	})
+	t.Run("should return an empty record when nothing has been saved with the key", func(t *testing.T) {
+		store := newStore()
+		store.Save("*key*", record)
+
+		found, err := store.FindByKey("*another-key*")
+		assert.Nil(t, err)
+		assert.Equal(t, IdempotencyRecord{}, found)
+	})
+	t.Run("should not replace the record saved with the key while within the window", func(t *testing.T) {
+		store := newStore()
+		store.Save("*key*", record)
+		another := IdempotencyRecord{Fingerprint: "*another-fingerprint*", Response: StoreResponse{ID: "*another-id*"}}
+
+		assert.Equal(t, ErrIdempotencyKeyExists, store.Save("*key*", another))
+		found, err := store.FindByKey("*key*")
+		assert.Nil(t, err)
+		assert.Equal(t, record, found)
+	})
+	t.Run("should keep a key saved again for the full window", func(t *testing.T) {
+		store := newStore()
+		store.Save("*key*", record)
+		store.now = func() time.Time { return now.Add(time.Hour) }
+		store.Save("*key*", record)
+		store.now = func() time.Time { return now.Add(90 * time.Minute) }
+
+		found, err := store.FindByKey("*key*")
+		assert.Nil(t, err)
+		assert.Equal(t, record, found)
+	})
+}
+
+func TestKeyLocks(t *testing.T) {
+	t.Run("should not hold up a request carrying a different key", func(t *testing.T) {
+		var locks keyLocks
+		unlock := locks.lock("*key*")
+		defer unlock()
+
+		locked := make(chan struct{})
+		go func() {
+			locks.lock("*another-key*")()
+			close(locked)
+		}()
+		select {
+		case <-locked:
+		case <-time.After(time.Second):
+			t.Fatal("lock of another key was held up")
+		}
+	})
+	t.Run("should hold up a request carrying the same key until the lock is released", func(t *testing.T) {
+		var locks keyLocks
+		unlock := locks.lock("*key*")
+
+		locked := make(chan struct{})
+		go func() {
+			locks.lock("*key*")()
+			close(locked)
+		}()
+		select {
+		case <-locked:
+			t.Fatal("lock of the same key was not held up")
+		case <-time.After(10 * time.Millisecond):
+		}
+		unlock()
+		<-locked
+		assert.Empty(t, locks.locks)
+	})
+}
+
+func TestFingerprint(t *testing.T) {
+	request := StoreRequest{
+		Description:     stringPtr("*description*"),
+		TransactionDate: stringPtr("2023-01-25"),
+		AmountInCents:   intPtr(1),
+		IdempotencyKey:  "*key*",
+	}
+	original, err := fingerprint(request)
+	assert.Nil(t, err)
+
+	t.Run("should ignore the idempotency key", func(t *testing.T) {
+		withAnotherKey := request
+		withAnotherKey.IdempotencyKey = "*another-key*"
+		got, err := fingerprint(withAnotherKey)
+		assert.Nil(t, err)
+		assert.Equal(t, original, got)
+	})
+	t.Run("should differ when the content differs", func(t *testing.T) {
+		withAnotherAmount := request
+		withAnotherAmount.AmountInCents = intPtr(2)
+		got, err := fingerprint(withAnotherAmount)
+		assert.Nil(t, err)
+		assert.NotEqual(t, original, got)
+	})
+}
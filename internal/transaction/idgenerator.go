@@ -0,0 +1,141 @@
+package transaction
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NewUUIDv7Generator creates an IDGenerator that generates version 7 UUIDs.
+func NewUUIDv7Generator() UUIDv7Generator {
+	return UUIDv7Generator{}
+}
+
+// UUIDv7Generator generates version 7 UUIDs, which begin with the time at which they were generated and so sort in
+// order of creation.  Unlike version 1 UUIDs, they do not reveal the address of the host that generated them.
+type UUIDv7Generator struct {
+}
+
+// NewID returns a new UUID or an error (if one occurred)
+func (g UUIDv7Generator) NewID() (string, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
+
+// crockfordAlphabet holds the characters of Crockford's base32 encoding, in order of value.
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// maxULIDTime is the latest time, in milliseconds since the Unix epoch, that can be held in a ULID.
+const maxULIDTime = 1<<48 - 1
+
+// errULIDOverflow is returned when too many ULIDs are generated within the same millisecond.
+var errULIDOverflow = errors.New("ulid random component overflowed within the same millisecond")
+
+// NewULIDGenerator creates an IDGenerator that generates ULIDs.
+func NewULIDGenerator() *ULIDGenerator {
+	return &ULIDGenerator{
+		now:     time.Now,
+		entropy: rand.Reader,
+	}
+}
+
+// ULIDGenerator generates ULIDs (see https://github.com/ulid/spec): 26 character, case insensitive ids beginning with
+// the millisecond at which they were generated.  Ids generated within the same millisecond increment the random
+// component of the previous id, so every id sorts after those generated before it, even if the clock steps back.
+type ULIDGenerator struct {
+	now     func() time.Time
+	entropy io.Reader
+
+	mu         sync.Mutex
+	lastTime   uint64
+	lastRandom [10]byte
+}
+
+// NewID returns a new ULID or an error (if one occurred)
+func (g *ULIDGenerator) NewID() (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	ms := g.now().UnixMilli()
+	if ms < 0 || ms > maxULIDTime {
+		return "", errors.New("time cannot be represented in a ulid")
+	}
+	if uint64(ms) > g.lastTime {
+		if _, err := io.ReadFull(g.entropy, g.lastRandom[:]); err != nil {
+			return "", err
+		}
+		g.lastTime = uint64(ms)
+	} else {
+		next := g.lastRandom
+		if !increment(next[:]) {
+			return "", errULIDOverflow
+		}
+		g.lastRandom = next
+	}
+	var id [16]byte
+	for n := 0; n < 6; n++ {
+		id[n] = byte(g.lastTime >> (40 - 8*n))
+	}
+	copy(id[6:], g.lastRandom[:])
+	return encodeULID(id), nil
+}
+
+// increment adds one to the supplied big-endian number, reporting false if it overflowed.
+func increment(number []byte) bool {
+	for n := len(number) - 1; n >= 0; n-- {
+		number[n]++
+		if number[n] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeULID returns the 128 bit ULID in its text form.  The 26 characters of 5 bits each encode 130 bits, the first
+// two of which are always zero.
+func encodeULID(id [16]byte) string {
+	var text [26]byte
+	for n := range text {
+		var value byte
+		for b := 0; b < 5; b++ {
+			value <<= 1
+			bit := n*5 + b - 2
+			if bit >= 0 && id[bit/8]&(0x80>>(bit%8)) != 0 {
+				value |= 1
+			}
+		}
+		text[n] = crockfordAlphabet[value]
+	}
+	return string(text[:])
+}
+
+// NewPrefixedGenerator creates an IDGenerator that prefixes the ids generated by the supplied IDGenerator with the
+// supplied prefix, e.g. 'txn_'.
+func NewPrefixedGenerator(prefix string, generator IDGenerator) PrefixedGenerator {
+	return PrefixedGenerator{
+		prefix:    prefix,
+		generator: generator,
+	}
+}
+
+// PrefixedGenerator generates ids having a fixed prefix, which identifies the type of thing they identify.  Since every
+// id has the same prefix, they sort in the same order as the ids of the wrapped IDGenerator.
+type PrefixedGenerator struct {
+	prefix    string
+	generator IDGenerator
+}
+
+// NewID returns a new id having the prefix, or an error (if one occurred)
+func (g PrefixedGenerator) NewID() (string, error) {
+	id, err := g.generator.NewID()
+	if err != nil {
+		return "", err
+	}
+	return g.prefix + id, nil
+}
@@ -0,0 +1,102 @@
+package transaction
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUUIDv7Generator(t *testing.T) {
+	generator := NewUUIDv7Generator()
+
+	previous := ""
+	for n := 0; n < 1000; n++ {
+		id, err := generator.NewID()
+		assert.Nil(t, err)
+		assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), id)
+		assert.Greater(t, id, previous)
+		previous = id
+	}
+}
+
+func TestULIDGenerator(t *testing.T) {
+	newGenerator := func(now time.Time, entropy []byte) *ULIDGenerator {
+		generator := NewULIDGenerator()
+		generator.now = func() time.Time { return now }
+		generator.entropy = bytes.NewReader(entropy)
+		return generator
+	}
+
+	t.Run("should begin the id with the time it was generated, followed by the random component", func(t *testing.T) {
+		generator := newGenerator(time.UnixMilli(1469918176385), make([]byte, 10))
+
+		id, err := generator.NewID()
+		assert.Nil(t, err)
+		assert.Equal(t, "01ARYZ6S410000000000000000", id)
+	})
+	t.Run("should increment the random component of ids generated within the same millisecond", func(t *testing.T) {
+		generator := newGenerator(time.UnixMilli(1469918176385), []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0xff})
+
+		first, _ := generator.NewID()
+		second, err := generator.NewID()
+		assert.Nil(t, err)
+		assert.Equal(t, "01ARYZ6S41000000000000007Z", first)
+		assert.Equal(t, "01ARYZ6S410000000000000080", second)
+	})
+	t.Run("should sort every id after those generated before it", func(t *testing.T) {
+		generator := NewULIDGenerator()
+
+		previous := ""
+		for n := 0; n < 1000; n++ {
+			id, err := generator.NewID()
+			assert.Nil(t, err)
+			assert.Regexp(t, regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{26}$`), id)
+			assert.Greater(t, id, previous)
+			previous = id
+		}
+	})
+	t.Run("failure", func(t *testing.T) {
+		t.Run("should return an error when the random component overflows", func(t *testing.T) {
+			generator := newGenerator(time.UnixMilli(1469918176385), bytes.Repeat([]byte{0xff}, 10))
+			generator.NewID()
+
+			_, err := generator.NewID()
+			assert.Equal(t, errULIDOverflow, err)
+		})
+		t.Run("should return an error when random bytes cannot be read", func(t *testing.T) {
+			generator := newGenerator(time.UnixMilli(1469918176385), nil)
+
+			_, err := generator.NewID()
+			assert.NotNil(t, err)
+		})
+	})
+}
+
+func TestPrefixedGenerator(t *testing.T) {
+	t.Run("should prefix the generated id", func(t *testing.T) {
+		generator := NewPrefixedGenerator("txn_", &fixedIDGenerator{id: "01ARYZ6S410000000000000000"})
+
+		id, err := generator.NewID()
+		assert.Nil(t, err)
+		assert.Equal(t, "txn_01ARYZ6S410000000000000000", id)
+	})
+	t.Run("should return the error of the wrapped generator", func(t *testing.T) {
+		generator := NewPrefixedGenerator("txn_", &fixedIDGenerator{err: errors.New("problem")})
+
+		_, err := generator.NewID()
+		assert.EqualError(t, err, "problem")
+	})
+}
+
+type fixedIDGenerator struct {
+	id  string
+	err error
+}
+
+func (g *fixedIDGenerator) NewID() (string, error) {
+	return g.id, g.err
+}
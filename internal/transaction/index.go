@@ -0,0 +1,95 @@
+package transaction
+
+import "sort"
+
+// index keeps the ids of stored transactions ordered by the value of a sort field, then by id, so that a page of
+// listed transactions can be found by binary search instead of by examining and sorting every stored transaction.
+type index struct {
+	field   SortField
+	entries []indexEntry
+}
+
+// indexEntry is the position of a single transaction within an index.
+type indexEntry struct {
+	key int64
+	id  string
+}
+
+// less reports whether the entry is ordered before the other entry.
+func (e indexEntry) less(other indexEntry) bool {
+	if e.key != other.key {
+		return e.key < other.key
+	}
+	return e.id < other.id
+}
+
+// newIndex creates an empty index ordered by the supplied field.
+func newIndex(field SortField) *index {
+	return &index{field: field}
+}
+
+// entry returns the entry of the index for the supplied Position.
+func (i *index) entry(position Position) indexEntry {
+	return indexEntry{key: sortKey(i.field, position), id: position.ID}
+}
+
+// insert adds the supplied transaction to the index.
+func (i *index) insert(txn Entity) {
+	entry := i.entry(positionOf(txn))
+	at := i.search(entry)
+	i.entries = append(i.entries, indexEntry{})
+	copy(i.entries[at+1:], i.entries[at:])
+	i.entries[at] = entry
+}
+
+// remove takes the supplied transaction, as it was when inserted, out of the index.
+func (i *index) remove(txn Entity) {
+	entry := i.entry(positionOf(txn))
+	at := i.search(entry)
+	if at < len(i.entries) && i.entries[at] == entry {
+		i.entries = append(i.entries[:at], i.entries[at+1:]...)
+	}
+}
+
+// search returns the offset of the first entry that is not ordered before the supplied entry.
+func (i *index) search(entry indexEntry) int {
+	return sort.Search(len(i.entries), func(n int) bool {
+		return !i.entries[n].less(entry)
+	})
+}
+
+// scan calls visit with the id of each transaction in the order of the supplied Query, beginning after its After
+// Position and skipping those outside the range of keys to which the Query restricts the field of the index.  Scanning
+// stops once visit returns false.
+func (i *index) scan(query Query, visit func(id string) bool) {
+	lo, hi := query.keyRange(i.field)
+	if query.Sort.Descending {
+		end := len(i.entries) - 1
+		if hi != maxKey {
+			end = i.search(indexEntry{key: hi + 1}) - 1
+		}
+		if query.After != nil {
+			if before := i.search(i.entry(*query.After)) - 1; before < end {
+				end = before
+			}
+		}
+		for n := end; n >= 0 && i.entries[n].key >= lo; n-- {
+			if !visit(i.entries[n].id) {
+				return
+			}
+		}
+		return
+	}
+	start := i.search(indexEntry{key: lo})
+	if query.After != nil {
+		after := i.entry(*query.After)
+		if next := sort.Search(len(i.entries), func(n int) bool { return after.less(i.entries[n]) }); next > start {
+			start = next
+		}
+	}
+	for n := start; n < len(i.entries) && i.entries[n].key <= hi; n++ {
+		if !visit(i.entries[n].id) {
+			return
+		}
+	}
+}
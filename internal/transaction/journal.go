@@ -0,0 +1,214 @@
+package transaction
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// openJournal opens the journal whose files are named after the supplied name within the supplied directory, creating
+// the directory if it does not exist.  Each record of the latest snapshot is passed to restore, followed by each record
+// of the log written since it was taken, so that the caller can rebuild its state.  A new snapshot is due after every
+// snapshotInterval records appended to the log.
+func openJournal[S, L any](dir, name string, snapshotInterval int, restore func(S), replay func(L)) (*journal[S, L], error) {
+	if snapshotInterval <= 0 {
+		return nil, fmt.Errorf("snapshot interval must be positive, got %d", snapshotInterval)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	j := &journal[S, L]{
+		logPath:          filepath.Join(dir, name+".log"),
+		snapshotPath:     filepath.Join(dir, name+".snapshot"),
+		snapshotInterval: snapshotInterval,
+	}
+	if err := j.loadSnapshot(restore); err != nil {
+		return nil, err
+	}
+	if err := j.replayLog(replay); err != nil {
+		return nil, err
+	}
+	logFile, err := os.OpenFile(j.logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := logFile.Stat()
+	if err != nil {
+		logFile.Close()
+		return nil, err
+	}
+	j.log = logFile
+	j.logSize = info.Size()
+	return j, nil
+}
+
+// journal stores the state of a file backed store durably on the local file system, as an append-only log of records
+// of type L that is periodically compacted into a snapshot of records of type S.  Every record is synced to disk
+// before append returns, and a record is either replayed in full or discarded, so each one is written atomically.  A
+// journal is not safe for concurrent use; the store that owns it must serialise its writes.
+type journal[S, L any] struct {
+	logPath          string
+	snapshotPath     string
+	snapshotInterval int
+	log              *os.File
+	logSize          int64
+	writesPending    int
+}
+
+// append writes the record to the log as a single line and syncs it to disk, returning an error if one occurred.
+func (j *journal[S, L]) append(record L) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	if _, err := j.log.Write(line); err != nil {
+		return j.discardPartialWrite(err)
+	}
+	if err := j.log.Sync(); err != nil {
+		return j.discardPartialWrite(err)
+	}
+	j.logSize += int64(len(line))
+	j.writesPending++
+	return nil
+}
+
+// snapshotDue reports whether enough records have been appended to the log since the last snapshot to take another.
+func (j *journal[S, L]) snapshotDue() bool {
+	return j.writesPending >= j.snapshotInterval
+}
+
+// snapshot writes the supplied records to a new snapshot file, atomically replaces the previous snapshot with it and
+// then empties the log.  Should the process stop between replacing the snapshot and emptying the log, the log is
+// simply replayed over the new snapshot on the next start, so each log record must hold the full state of whatever it
+// records.
+func (j *journal[S, L]) snapshot(records []S) error {
+	tmpPath := j.snapshotPath + ".tmp"
+	tmp, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(writer)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, j.snapshotPath); err != nil {
+		return err
+	}
+	if err := syncDir(filepath.Dir(j.snapshotPath)); err != nil {
+		return err
+	}
+	if err := j.log.Truncate(0); err != nil {
+		return err
+	}
+	if err := j.log.Sync(); err != nil {
+		return err
+	}
+	j.logSize = 0
+	j.writesPending = 0
+	return nil
+}
+
+// Close closes the log file.  The journal must not be used once it has been closed.
+func (j *journal[S, L]) Close() error {
+	return j.log.Close()
+}
+
+// discardPartialWrite trims anything written to the log by a failed append, so that later records are not appended to
+// an incomplete one, and returns the error that caused the append to fail.
+func (j *journal[S, L]) discardPartialWrite(err error) error {
+	if truncateErr := j.log.Truncate(j.logSize); truncateErr != nil {
+		return errors.Join(err, truncateErr)
+	}
+	return err
+}
+
+// loadSnapshot passes each record of the snapshot file, if there is one, to restore.
+func (j *journal[S, L]) loadSnapshot(restore func(S)) error {
+	file, err := os.Open(j.snapshotPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	for {
+		var record S
+		err := decoder.Decode(&record)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading snapshot: %w", err)
+		}
+		restore(record)
+	}
+}
+
+// replayLog passes each record in the log to replay.  A final record that is incomplete, because the process stopped
+// part way through writing it, was never acknowledged to the caller and so is discarded.  Any other unreadable record
+// results in an error rather than silently losing data.
+func (j *journal[S, L]) replayLog(replay func(L)) error {
+	content, err := os.ReadFile(j.logPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	offset := 0
+	for offset < len(content) {
+		end := bytes.IndexByte(content[offset:], '\n')
+		if end < 0 {
+			return j.truncateLog(offset)
+		}
+		var record L
+		if err := json.Unmarshal(content[offset:offset+end], &record); err != nil {
+			if offset+end+1 == len(content) {
+				return j.truncateLog(offset)
+			}
+			return fmt.Errorf("reading log at offset %d: %w", offset, err)
+		}
+		replay(record)
+		j.writesPending++
+		offset += end + 1
+	}
+	return nil
+}
+
+// truncateLog discards the content of the log from the supplied offset onwards.
+func (j *journal[S, L]) truncateLog(offset int) error {
+	return os.Truncate(j.logPath, int64(offset))
+}
+
+// syncDir syncs the supplied directory so that a rename within it is durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
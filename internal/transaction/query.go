@@ -0,0 +1,227 @@
+package transaction
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"transaction-service/internal/validation"
+)
+
+// SortField identifies the field by which listed transactions are ordered.
+type SortField string
+
+const (
+	SortByTransactionDate SortField = "transactionDate"
+	SortByAmountInCents   SortField = "amountInCents"
+
+	// SortByID orders transactions by id alone, which is the order in which they were stored when ids are generated by
+	// a time-sortable IDGenerator such as the UUIDv7Generator or the ULIDGenerator.
+	SortByID SortField = "id"
+)
+
+// sortFields holds every SortField, in the order in which they are reported as supported.
+var sortFields = []SortField{SortByTransactionDate, SortByAmountInCents, SortByID}
+
+// Sort describes the order in which listed transactions are returned.  Transactions having the same value of the sort
+// field are ordered by id in the same direction, so that the order is total and consecutive pages never overlap.  The
+// zero Sort orders by ascending transaction date.
+type Sort struct {
+	Field      SortField
+	Descending bool
+}
+
+// field returns the field by which the Sort orders transactions.
+func (s Sort) field() SortField {
+	if s.Field == "" {
+		return SortByTransactionDate
+	}
+	return s.Field
+}
+
+// String returns the representation of the Sort used in the 'sort' query parameter, e.g. '-transactionDate'.
+func (s Sort) String() string {
+	if s.Descending {
+		return "-" + string(s.field())
+	}
+	return string(s.field())
+}
+
+// parseSort parses the representation of a Sort returned by Sort.String.  It reports false if the value does not
+// represent a supported Sort.
+func parseSort(value string) (Sort, bool) {
+	sort := Sort{Field: SortField(strings.TrimPrefix(value, "-")), Descending: strings.HasPrefix(value, "-")}
+	for _, field := range sortFields {
+		if sort.Field == field {
+			return sort, true
+		}
+	}
+	return Sort{}, false
+}
+
+// supportedSorts returns the representation of every supported Sort.
+func supportedSorts() []string {
+	var sorts []string
+	for _, field := range sortFields {
+		sorts = append(sorts, Sort{Field: field}.String(), Sort{Field: field, Descending: true}.String())
+	}
+	return sorts
+}
+
+// Position identifies the place of a transaction in the sort order, holding its id along with the values of the
+// fields by which it can be sorted.  A page of listed transactions begins after a Position.
+type Position struct {
+	ID              string
+	TransactionDate time.Time
+	AmountInCents   int
+}
+
+// positionOf returns the Position of the supplied transaction.
+func positionOf(txn Entity) Position {
+	return Position{
+		ID:              txn.ID,
+		TransactionDate: txn.TransactionDate,
+		AmountInCents:   txn.AmountInCents,
+	}
+}
+
+// Query describes the transactions to be listed by a Repository.  Each range is inclusive, and a nil bound leaves that
+// end of the range open.
+type Query struct {
+	FromDate         *time.Time
+	ToDate           *time.Time
+	MinAmountInCents *int
+	MaxAmountInCents *int
+
+	// Description, when not empty, matches transactions whose description contains it, ignoring case.
+	Description string
+
+	// IncludeVoided includes voided transactions, which are otherwise left out.
+	IncludeVoided bool
+
+	Sort Sort
+
+	// After, when not nil, leaves out every transaction up to and including the Position in the sort order.
+	After *Position
+
+	// Limit is the maximum number of transactions to list, or zero for no maximum.
+	Limit int
+}
+
+// matches reports whether the supplied transaction satisfies the filters of the Query.
+func (q Query) matches(txn Entity) bool {
+	if q.FromDate != nil && txn.TransactionDate.Before(*q.FromDate) {
+		return false
+	}
+	if q.ToDate != nil && txn.TransactionDate.After(*q.ToDate) {
+		return false
+	}
+	if q.MinAmountInCents != nil && txn.AmountInCents < *q.MinAmountInCents {
+		return false
+	}
+	if q.MaxAmountInCents != nil && txn.AmountInCents > *q.MaxAmountInCents {
+		return false
+	}
+	if q.Description != "" && !strings.Contains(strings.ToLower(txn.Description), strings.ToLower(q.Description)) {
+		return false
+	}
+	if txn.Voided != nil && !q.IncludeVoided {
+		return false
+	}
+	return true
+}
+
+// keyRange returns the inclusive range of sort keys (see sortKey) to which the Query restricts the supplied field.
+func (q Query) keyRange(field SortField) (int64, int64) {
+	lo, hi := int64(minKey), int64(maxKey)
+	switch field {
+	case SortByTransactionDate:
+		if q.FromDate != nil {
+			lo = q.FromDate.Unix()
+		}
+		if q.ToDate != nil {
+			hi = q.ToDate.Unix()
+		}
+	case SortByAmountInCents:
+		if q.MinAmountInCents != nil {
+			lo = int64(*q.MinAmountInCents)
+		}
+		if q.MaxAmountInCents != nil {
+			hi = int64(*q.MaxAmountInCents)
+		}
+	}
+	return lo, hi
+}
+
+// minKey and maxKey are the lowest and highest possible sort keys.
+const (
+	minKey = math.MinInt64
+	maxKey = math.MaxInt64
+)
+
+// sortKey returns the value of the supplied field at the supplied Position, as an integer that orders in the same way
+// as the field.  Every Position has the same key when sorting by id, so that they are ordered by id alone.
+func sortKey(field SortField, position Position) int64 {
+	switch field {
+	case SortByAmountInCents:
+		return int64(position.AmountInCents)
+	case SortByID:
+		return 0
+	default:
+		return position.TransactionDate.Unix()
+	}
+}
+
+// errInvalidCursor is returned when a cursor cannot be decoded.
+var errInvalidCursor = errors.New("invalid cursor")
+
+// cursor is the content of the opaque cursor handed to clients to fetch the next page of listed transactions.  The
+// sort is included so that a cursor cannot be used to continue a listing in a different order.
+type cursor struct {
+	Sort            string `json:"s"`
+	ID              string `json:"i"`
+	TransactionDate string `json:"d"`
+	AmountInCents   int    `json:"a"`
+}
+
+// encodeCursor returns the opaque cursor identifying the supplied Position in the supplied Sort.
+func encodeCursor(sort Sort, position Position) (string, error) {
+	content, err := json.Marshal(cursor{
+		Sort:            sort.String(),
+		ID:              position.ID,
+		TransactionDate: position.TransactionDate.Format(validation.DateFormat),
+		AmountInCents:   position.AmountInCents,
+	})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(content), nil
+}
+
+// decodeCursor returns the Position identified by the supplied opaque cursor.  errInvalidCursor is returned if the
+// cursor was not produced by encodeCursor for the supplied Sort.
+func decodeCursor(sort Sort, value string) (Position, error) {
+	content, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return Position{}, errInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(content, &c); err != nil {
+		return Position{}, errInvalidCursor
+	}
+	if c.Sort != sort.String() || c.ID == "" {
+		return Position{}, errInvalidCursor
+	}
+	txnDate, err := time.Parse(validation.DateFormat, c.TransactionDate)
+	if err != nil {
+		return Position{}, errInvalidCursor
+	}
+	return Position{
+		ID:              c.ID,
+		TransactionDate: txnDate,
+		AmountInCents:   c.AmountInCents,
+	}, nil
+}
@@ -1,11 +1,23 @@
 package transaction
 
 import (
+	"errors"
 	"sync"
 
 	"github.com/google/uuid"
 )
 
+var (
+	// ErrNotFound is returned when changing a transaction that has not been stored.
+	ErrNotFound = errors.New("transaction not found")
+
+	// ErrVersionConflict is returned when changing a transaction whose stored version differs from the expected version.
+	ErrVersionConflict = errors.New("transaction version conflict")
+)
+
+// initialVersion is the version given to a transaction when it is first stored.
+const initialVersion = 1
+
 // IDGenerator is the expected interface to be used when generating ids for stored transactions.
 type IDGenerator interface {
 	NewID() (string, error)
@@ -31,8 +43,13 @@ func (f UUIDGenerator) NewID() (string, error) {
 
 // NewInMemoryRepository creates a new in memory repository with the supplied id generator.
 func NewInMemoryRepository(idGenerator IDGenerator) *InMemoryRepository {
+	indexes := make(map[SortField]*index)
+	for _, field := range sortFields {
+		indexes[field] = newIndex(field)
+	}
 	return &InMemoryRepository{
 		data:        make(map[string]Entity),
+		indexes:     indexes,
 		idGenerator: idGenerator,
 	}
 }
@@ -40,9 +57,11 @@ func NewInMemoryRepository(idGenerator IDGenerator) *InMemoryRepository {
 // InMemoryRepository stores transactions in an in memory map.  It generates a new id for each transaction as it is
 // stored, using its configured IDGenerator.  This is intended a very simple way of storing transactions.  These
 // transactions do no persist once the application is shut down.  In a production environment a repository such as
-// this would manage communication with a real database to persist transactions long term.
+// this would manage communication with a real database to persist transactions long term.  The stored transactions are
+// indexed by each SortField so that they can be listed a page at a time.
 type InMemoryRepository struct {
 	data        map[string]Entity
+	indexes     map[SortField]*index
 	mu          sync.RWMutex
 	idGenerator IDGenerator
 }
@@ -55,17 +74,122 @@ func (r *InMemoryRepository) Save(txn Entity) (Entity, error) {
 		return Entity{}, err
 	}
 	txn.ID = id
+	txn.Version = initialVersion
+	r.put(txn)
+	return txn, nil
+}
+
+// SaveAll stores the transactions in the repository, generating a new id for each, or returns an error if one
+// occurred.  Either all of the transactions are stored or, should an error occur, none of them are.
+func (r *InMemoryRepository) SaveAll(txns []Entity) ([]Entity, error) {
+	saved, err := withNewIDs(r.idGenerator, txns)
+	if err != nil {
+		return nil, err
+	}
+	r.put(saved...)
+	return saved, nil
+}
+
+// Update replaces the stored transaction having the same id as the supplied transaction, provided the stored version
+// matches the supplied version, and returns it with its version incremented.  ErrNotFound is returned if no such
+// transaction is stored and ErrVersionConflict is returned if the versions do not match.  It performs locking to ensure
+// safe access for concurrent operations.
+func (r *InMemoryRepository) Update(txn Entity) (Entity, error) {
 	r.mu.Lock()
-	r.data[txn.ID] = txn
+	defer r.mu.Unlock()
+	updated, err := nextVersion(r.data[txn.ID], txn)
+	if err != nil {
+		return Entity{}, err
+	}
+	r.store(updated)
+	return updated, nil
+}
+
+// Query lists the stored transactions satisfying the supplied Query, in the order it specifies.  Only the transactions
+// within the range of the sort field covered by the Query are examined.  It performs locking to ensure safe access for
+// concurrent operations.
+func (r *InMemoryRepository) Query(query Query) ([]Entity, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var txns []Entity
+	r.indexes[query.Sort.field()].scan(query, func(id string) bool {
+		if txn := r.data[id]; query.matches(txn) {
+			txns = append(txns, txn)
+		}
+		return query.Limit == 0 || len(txns) < query.Limit
+	})
+	return txns, nil
+}
+
+// all returns every stored transaction, in no particular order.
+func (r *InMemoryRepository) all() []Entity {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	txns := make([]Entity, 0, len(r.data))
+	for _, txn := range r.data {
+		txns = append(txns, txn)
+	}
+	return txns
+}
+
+// put stores the supplied transactions under their existing ids, replacing any transactions previously stored with
+// those ids.
+func (r *InMemoryRepository) put(txns ...Entity) {
+	r.mu.Lock()
+	for _, txn := range txns {
+		r.store(txn)
+	}
 	r.mu.Unlock()
-	return txn, nil
+}
+
+// store stores the supplied transaction under its existing id and updates the indexes accordingly.  The caller must
+// hold the lock.
+func (r *InMemoryRepository) store(txn Entity) {
+	if current, ok := r.data[txn.ID]; ok {
+		for _, index := range r.indexes {
+			index.remove(current)
+		}
+	}
+	r.data[txn.ID] = txn
+	for _, index := range r.indexes {
+		index.insert(txn)
+	}
 }
 
 // FindByID fetches the transaction with the provided id from the store.  An empty Entity will be returned if a
 // transaction with the supplied id is not found.  It performs locking to ensure safe access for concurrent operations.
-func (r *InMemoryRepository) FindByID(id string) Entity {
+func (r *InMemoryRepository) FindByID(id string) (Entity, error) {
 	r.mu.RLock()
 	txn := r.data[id]
 	r.mu.RUnlock()
-	return txn
+	return txn, nil
+}
+
+// withNewIDs returns a copy of the supplied transactions, each given a new id generated by the supplied IDGenerator and
+// the initial version, or returns an error if any id could not be generated.
+func withNewIDs(idGenerator IDGenerator, txns []Entity) ([]Entity, error) {
+	saved := make([]Entity, len(txns))
+	for i, txn := range txns {
+		id, err := idGenerator.NewID()
+		if err != nil {
+			return nil, err
+		}
+		txn.ID = id
+		txn.Version = initialVersion
+		saved[i] = txn
+	}
+	return saved, nil
+}
+
+// nextVersion checks that the supplied change may be applied to the currently stored transaction, returning the change
+// with its version incremented.
+func nextVersion(current, change Entity) (Entity, error) {
+	if current == (Entity{}) {
+		return Entity{}, ErrNotFound
+	}
+	if current.Version != change.Version {
+		return Entity{}, ErrVersionConflict
+	}
+	change.Version++
+	return change, nil
 }
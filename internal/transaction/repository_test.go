@@ -30,7 +30,8 @@ func TestRepository(t *testing.T) {
 					name:   "empty entity",
 					entity: transaction.Entity{},
 					wantEntity: transaction.Entity{
-						ID: "sequentialID-1",
+						ID:      "sequentialID-1",
+						Version: 1,
 					},
 				},
 				{
@@ -45,6 +46,7 @@ func TestRepository(t *testing.T) {
 						Description:     "*description*",
 						TransactionDate: date.NewInUTC(2023, time.January, 23),
 						AmountInCents:   5432,
+						Version:         1,
 					},
 				},
 			}
@@ -81,29 +83,331 @@ func TestRepository(t *testing.T) {
 				AmountInCents:   5432,
 			})
 
-			entity := repository.FindByID("sequentialID-1")
+			entity, err := repository.FindByID("sequentialID-1")
+			assert.Nil(t, err)
 			wantEntity := transaction.Entity{
 				ID:              "sequentialID-1",
 				Description:     "*description*",
 				TransactionDate: date.NewInUTC(2023, time.January, 23),
 				AmountInCents:   5432,
+				Version:         1,
 			}
 			assert.Equal(t, wantEntity, entity)
 		})
 		t.Run("should return empty entity when nothing has been stored with the provided id", func(t *testing.T) {
 			setUpRepository()
 
-			entity := repository.FindByID("sequentialID-1")
+			entity, err := repository.FindByID("sequentialID-1")
+			assert.Nil(t, err)
 			wantEntity := transaction.Entity{}
 			assert.Equal(t, wantEntity, entity)
 		})
 	})
+
+	t.Run("update", func(t *testing.T) {
+		testUpdate(t, func(t *testing.T) transaction.Repository {
+			setUpRepository()
+			return repository
+		})
+	})
+
+	t.Run("query", func(t *testing.T) {
+		testQuery(t, func(t *testing.T) transaction.Repository {
+			setUpRepository()
+			return repository
+		})
+	})
+
+	t.Run("store all", func(t *testing.T) {
+		testSaveAll(t, func(t *testing.T) transaction.Repository {
+			setUpRepository()
+			return repository
+		})
+		t.Run("failure - should store nothing when an id cannot be generated", func(t *testing.T) {
+			repository = transaction.NewInMemoryRepository(&alwaysErrorIDGenerator{})
+
+			saved, err := repository.SaveAll([]transaction.Entity{{Description: "one"}})
+			assert.EqualError(t, err, "problem")
+			assert.Nil(t, saved)
+			txns, _ := repository.Query(transaction.Query{})
+			assert.Empty(t, txns)
+		})
+	})
+}
+
+// testUpdate exercises the Update operation of the repositories returned by newRepository, which must generate
+// sequential ids.  It is shared by the tests of each Repository implementation.
+func testUpdate(t *testing.T, newRepository func(t *testing.T) transaction.Repository) {
+	stored := transaction.Entity{
+		Description:     "*description*",
+		TransactionDate: date.NewInUTC(2023, time.January, 23),
+		AmountInCents:   5432,
+	}
+	change := transaction.Entity{
+		ID:              "sequentialID-1",
+		Description:     "*changed*",
+		TransactionDate: date.NewInUTC(2023, time.February, 1),
+		AmountInCents:   -10,
+		Version:         1,
+	}
+
+	t.Run("success - should store the change and return it with the next version", func(t *testing.T) {
+		repo := newRepository(t)
+		repo.Save(stored)
+
+		updated, err := repo.Update(change)
+		assert.Nil(t, err)
+		wantEntity := change
+		wantEntity.Version = 2
+		assert.Equal(t, wantEntity, updated)
+		assert.Equal(t, wantEntity, findByID(t, repo, "sequentialID-1"))
+	})
+	t.Run("success - should store and clear the void of a transaction", func(t *testing.T) {
+		repo := newRepository(t)
+		repo.Save(stored)
+		voided := change
+		voided.Voided = &transaction.Void{
+			Reason: "*reason*",
+			At:     time.Date(2023, time.February, 2, 10, 30, 15, 500, time.UTC),
+		}
+
+		repo.Update(voided)
+		assert.Equal(t, voided.Voided, findByID(t, repo, "sequentialID-1").Voided)
+
+		restored := change
+		restored.Version = 2
+		repo.Update(restored)
+		assert.Nil(t, findByID(t, repo, "sequentialID-1").Voided)
+	})
+	t.Run("failure - should return a version conflict when the stored version differs", func(t *testing.T) {
+		repo := newRepository(t)
+		repo.Save(stored)
+		repo.Update(change)
+
+		updated, err := repo.Update(change)
+		assert.Equal(t, transaction.ErrVersionConflict, err)
+		assert.Equal(t, transaction.Entity{}, updated)
+		assert.Equal(t, 2, findByID(t, repo, "sequentialID-1").Version)
+	})
+	t.Run("failure - should return not found when nothing has been stored with the id", func(t *testing.T) {
+		repo := newRepository(t)
+
+		updated, err := repo.Update(change)
+		assert.Equal(t, transaction.ErrNotFound, err)
+		assert.Equal(t, transaction.Entity{}, updated)
+	})
+}
+
+// testSaveAll exercises the SaveAll operation of the repositories returned by newRepository, which must generate
+// sequential ids.  It is shared by the tests of each Repository implementation.
+func testSaveAll(t *testing.T, newRepository func(t *testing.T) transaction.Repository) {
+	t.Run("success - should return the entities with new ids and make them available to find by id", func(t *testing.T) {
+		repo := newRepository(t)
+		repo.Save(transaction.Entity{Description: "zero"})
+
+		saved, err := repo.SaveAll([]transaction.Entity{
+			{Description: "one", TransactionDate: date.NewInUTC(2023, time.January, 23), AmountInCents: 1},
+			{Description: "two", TransactionDate: date.NewInUTC(2023, time.January, 24), AmountInCents: 2},
+		})
+		assert.Nil(t, err)
+		wantEntities := []transaction.Entity{
+			{
+				ID:              "sequentialID-2",
+				Description:     "one",
+				TransactionDate: date.NewInUTC(2023, time.January, 23),
+				AmountInCents:   1,
+				Version:         1,
+			},
+			{
+				ID:              "sequentialID-3",
+				Description:     "two",
+				TransactionDate: date.NewInUTC(2023, time.January, 24),
+				AmountInCents:   2,
+				Version:         1,
+			},
+		}
+		assert.Equal(t, wantEntities, saved)
+		assert.Equal(t, wantEntities[0], findByID(t, repo, "sequentialID-2"))
+		assert.Equal(t, wantEntities[1], findByID(t, repo, "sequentialID-3"))
+	})
+}
+
+// testQuery exercises the Query operation of the repositories returned by newRepository, which must generate
+// sequential ids.  It is shared by the tests of each Repository implementation.
+func testQuery(t *testing.T, newRepository func(t *testing.T) transaction.Repository) {
+	// Stored with ids sequentialID-1 to sequentialID-5.
+	stored := []transaction.Entity{
+		{Description: "Coffee", TransactionDate: date.NewInUTC(2023, time.March, 2), AmountInCents: 450},
+		{Description: "Refund: coffee", TransactionDate: date.NewInUTC(2023, time.March, 3), AmountInCents: -450},
+		{Description: "Groceries", TransactionDate: date.NewInUTC(2023, time.March, 1), AmountInCents: 8_000},
+		{Description: "100% cotton_shirt", TransactionDate: date.NewInUTC(2023, time.March, 2), AmountInCents: 2_500},
+		{Description: "Voided coffee", TransactionDate: date.NewInUTC(2023, time.March, 4), AmountInCents: 450},
+	}
+	setUp := func(t *testing.T) transaction.Repository {
+		repo := newRepository(t)
+		for _, txn := range stored {
+			repo.Save(txn)
+		}
+		voided := findByID(t, repo, "sequentialID-5")
+		voided.Voided = &transaction.Void{Reason: "*reason*", At: time.Date(2023, time.March, 5, 0, 0, 0, 0, time.UTC)}
+		repo.Update(voided)
+		return repo
+	}
+	ids := func(txns []transaction.Entity) []string {
+		var ids []string
+		for _, txn := range txns {
+			ids = append(ids, txn.ID)
+		}
+		return ids
+	}
+	ascendingDate := transaction.Sort{Field: transaction.SortByTransactionDate}
+	descendingDate := transaction.Sort{Field: transaction.SortByTransactionDate, Descending: true}
+	ascendingAmount := transaction.Sort{Field: transaction.SortByAmountInCents}
+	descendingAmount := transaction.Sort{Field: transaction.SortByAmountInCents, Descending: true}
+	ascendingID := transaction.Sort{Field: transaction.SortByID}
+	descendingID := transaction.Sort{Field: transaction.SortByID, Descending: true}
+	march2 := date.NewInUTC(2023, time.March, 2)
+	march3 := date.NewInUTC(2023, time.March, 3)
+
+	tcs := []struct {
+		name    string
+		query   transaction.Query
+		wantIDs []string
+	}{
+		{
+			name:    "should list every transaction that has not been voided in ascending order of date, then id",
+			query:   transaction.Query{Sort: ascendingDate},
+			wantIDs: []string{"sequentialID-3", "sequentialID-1", "sequentialID-4", "sequentialID-2"},
+		},
+		{
+			name:    "should list in descending order of date, then id",
+			query:   transaction.Query{Sort: descendingDate},
+			wantIDs: []string{"sequentialID-2", "sequentialID-4", "sequentialID-1", "sequentialID-3"},
+		},
+		{
+			name:    "should list in ascending order of amount",
+			query:   transaction.Query{Sort: ascendingAmount},
+			wantIDs: []string{"sequentialID-2", "sequentialID-1", "sequentialID-4", "sequentialID-3"},
+		},
+		{
+			name:    "should list in descending order of amount",
+			query:   transaction.Query{Sort: descendingAmount},
+			wantIDs: []string{"sequentialID-3", "sequentialID-4", "sequentialID-1", "sequentialID-2"},
+		},
+		{
+			name:    "should list in ascending order of id",
+			query:   transaction.Query{Sort: ascendingID},
+			wantIDs: []string{"sequentialID-1", "sequentialID-2", "sequentialID-3", "sequentialID-4"},
+		},
+		{
+			name:    "should list in descending order of id after the position",
+			query:   transaction.Query{Sort: descendingID, After: &transaction.Position{ID: "sequentialID-3"}},
+			wantIDs: []string{"sequentialID-2", "sequentialID-1"},
+		},
+		{
+			name:    "should include voided transactions when asked to",
+			query:   transaction.Query{Sort: descendingDate, IncludeVoided: true},
+			wantIDs: []string{"sequentialID-5", "sequentialID-2", "sequentialID-4", "sequentialID-1", "sequentialID-3"},
+		},
+		{
+			name:    "should list transactions within the inclusive date range",
+			query:   transaction.Query{Sort: ascendingAmount, FromDate: &march2, ToDate: &march3},
+			wantIDs: []string{"sequentialID-2", "sequentialID-1", "sequentialID-4"},
+		},
+		{
+			name:    "should list refunds when the amount range is negative",
+			query:   transaction.Query{Sort: descendingDate, MaxAmountInCents: intPtr(-1)},
+			wantIDs: []string{"sequentialID-2"},
+		},
+		{
+			name:    "should list transactions within the inclusive amount range",
+			query:   transaction.Query{Sort: descendingAmount, MinAmountInCents: intPtr(450), MaxAmountInCents: intPtr(2_500)},
+			wantIDs: []string{"sequentialID-4", "sequentialID-1"},
+		},
+		{
+			name:    "should list transactions whose description contains the text, ignoring case",
+			query:   transaction.Query{Sort: ascendingDate, Description: "COFFEE"},
+			wantIDs: []string{"sequentialID-1", "sequentialID-2"},
+		},
+		{
+			name:    "should match the description literally when the text contains wildcard characters",
+			query:   transaction.Query{Sort: ascendingDate, Description: "0% cotton_"},
+			wantIDs: []string{"sequentialID-4"},
+		},
+		{
+			name:    "should list no more than the limit",
+			query:   transaction.Query{Sort: ascendingDate, Limit: 2},
+			wantIDs: []string{"sequentialID-3", "sequentialID-1"},
+		},
+		{
+			name: "should list in ascending order after the position, including transactions with the same sort value",
+			query: transaction.Query{
+				Sort:  ascendingDate,
+				After: &transaction.Position{ID: "sequentialID-1", TransactionDate: march2},
+				Limit: 2,
+			},
+			wantIDs: []string{"sequentialID-4", "sequentialID-2"},
+		},
+		{
+			name: "should list in descending order after the position, including transactions with the same sort value",
+			query: transaction.Query{
+				Sort:  descendingDate,
+				After: &transaction.Position{ID: "sequentialID-4", TransactionDate: march2},
+			},
+			wantIDs: []string{"sequentialID-1", "sequentialID-3"},
+		},
+		{
+			name: "should list after the position of a transaction that no longer matches",
+			query: transaction.Query{
+				Sort:             descendingAmount,
+				After:            &transaction.Position{ID: "sequentialID-9", AmountInCents: 3_000},
+				MinAmountInCents: intPtr(0),
+			},
+			wantIDs: []string{"sequentialID-4", "sequentialID-1"},
+		},
+		{
+			name:    "should list nothing when no transaction matches",
+			query:   transaction.Query{Sort: ascendingDate, Description: "*no match*"},
+			wantIDs: nil,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := setUp(t)
+
+			txns, err := repo.Query(tc.query)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.wantIDs, ids(txns))
+		})
+	}
+
+	t.Run("should list transactions in their new position once they are updated", func(t *testing.T) {
+		repo := setUp(t)
+		changed := findByID(t, repo, "sequentialID-3")
+		changed.AmountInCents = -1_000
+		repo.Update(changed)
+
+		txns, err := repo.Query(transaction.Query{Sort: ascendingAmount})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"sequentialID-3", "sequentialID-2", "sequentialID-1", "sequentialID-4"}, ids(txns))
+		assert.Equal(t, -1_000, txns[0].AmountInCents)
+	})
 }
 
 func setUpRepository() {
 	repository = transaction.NewInMemoryRepository(id.NewSequentialGenerator())
 }
 
+// findByID finds the transaction with the supplied id in the supplied repository, failing the current test should an
+// error occur.
+func findByID(t *testing.T, repo transaction.Repository, id string) transaction.Entity {
+	entity, err := repo.FindByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entity
+}
+
 type alwaysErrorIDGenerator struct{}
 
 func (id *alwaysErrorIDGenerator) NewID() (string, error) {
@@ -5,4 +5,128 @@ type StoreRequest struct {
 	Description     *string `json:"description"`
 	TransactionDate *string `json:"transactionDate"`
 	AmountInCents   *int    `json:"amountInCents"`
+
+	// IdempotencyKey is the optional key supplied by the client to identify retries of the same request.  It is not
+	// part of the request body.
+	IdempotencyKey string `json:"-"`
+}
+
+// BatchRequest represents the user's request to store a batch of transactions
+type BatchRequest struct {
+	// Transactions holds the transactions to store.
+	Transactions []StoreRequest
+
+	// Atomic stores none of the transactions should any of them be invalid, rather than storing those that are valid.
+	Atomic bool
+}
+
+// FetchRequest represents the user's request to fetch a transaction
+type FetchRequest struct {
+	// TransactionID is the id of the transaction to fetch.
+	TransactionID string
+
+	// Country is the country to whose currency the transaction amount is converted.
+	Country string
+
+	// Countries are the countries to whose currencies the transaction amount is converted when more than one is
+	// requested, in place of the Country.
+	Countries []string
+
+	// Currency is the ISO 4217 code of the currency to which the transaction amount is converted, as an alternative to
+	// the Country.
+	Currency string
+
+	// IncludeVoided allows a voided transaction to be fetched.
+	IncludeVoided bool
+
+	// AsOf is the date as at which the transaction amount is converted in place of the transaction date, or is nil if
+	// not supplied.
+	AsOf *string
+
+	// RatePolicy and RateWindowMonths override the configured rules by which the exchange rate is selected, or are nil
+	// if not supplied.
+	RatePolicy       *string
+	RateWindowMonths *string
+}
+
+// VoidRequest represents the user's request to void a transaction
+type VoidRequest struct {
+	Reason *string `json:"reason"`
+}
+
+// ListRequest represents the user's request to list transactions.  Each field is taken from a query parameter, and is
+// nil when the parameter is not supplied.
+type ListRequest struct {
+	// FromDate and ToDate are the earliest and latest transaction dates of the transactions to list.
+	FromDate *string
+	ToDate   *string
+
+	// MinAmountInCents and MaxAmountInCents are the least and greatest amounts of the transactions to list.
+	MinAmountInCents *string
+	MaxAmountInCents *string
+
+	// Description is text that must appear, ignoring case, in the description of the transactions to list.
+	Description *string
+
+	// IncludeVoided allows voided transactions to be listed.
+	IncludeVoided bool
+
+	// Sort is the order of the listed transactions, e.g. 'amountInCents', or '-amountInCents' for descending order.
+	Sort *string
+
+	// Limit is the maximum number of transactions to list.
+	Limit *string
+
+	// Cursor is the cursor returned with the previous page of transactions, used to fetch the next page.
+	Cursor *string
+
+	// Country is the country to whose currency the amount of each transaction is converted, if supplied.
+	Country *string
+
+	// AsOf is the date as at which amounts are converted in place of their transaction dates.
+	AsOf *string
+
+	// RatePolicy and RateWindowMonths override the configured rules by which exchange rates are selected.
+	RatePolicy       *string
+	RateWindowMonths *string
+}
+
+// ExportRequest represents the user's request to export transactions.  The filters and sort are as for a ListRequest,
+// but every matching transaction is exported rather than a page of them.
+type ExportRequest struct {
+	FromDate         *string
+	ToDate           *string
+	MinAmountInCents *string
+	MaxAmountInCents *string
+	Description      *string
+	IncludeVoided    bool
+	Sort             *string
+
+	// Country is the country to whose currency the amount of each transaction is converted.
+	Country *string
+
+	// AsOf is the date as at which amounts are converted in place of their transaction dates.
+	AsOf *string
+
+	// RatePolicy and RateWindowMonths override the configured rules by which exchange rates are selected.
+	RatePolicy       *string
+	RateWindowMonths *string
+}
+
+// listRequest returns the ListRequest having the same filters, sort, country and valuation options as the
+// ExportRequest.
+func (r ExportRequest) listRequest() ListRequest {
+	return ListRequest{
+		FromDate:         r.FromDate,
+		ToDate:           r.ToDate,
+		MinAmountInCents: r.MinAmountInCents,
+		MaxAmountInCents: r.MaxAmountInCents,
+		Description:      r.Description,
+		IncludeVoided:    r.IncludeVoided,
+		Sort:             r.Sort,
+		Country:          r.Country,
+		AsOf:             r.AsOf,
+		RatePolicy:       r.RatePolicy,
+		RateWindowMonths: r.RateWindowMonths,
+	}
 }
@@ -3,11 +3,16 @@ package transaction
 import (
 	"encoding/json"
 	"time"
+
+	"transaction-service/internal/business"
 )
 
 // FetchResponse represents the response for a 'fetch transaction' operation, containing details of the transaction.
 type FetchResponse struct {
 	Transaction Response `json:"transaction"`
+
+	// Version is the current version of the transaction, which is returned in the ETag header rather than the body.
+	Version int `json:"-"`
 }
 
 // Response represents the fetched transaction details.
@@ -21,8 +26,24 @@ type Response struct {
 	// TransactionDate is the date on which the transaction occurred.
 	TransactionDate *FormattedDate `json:"transactionDate"`
 
-	// Amount contains details concerning the transaction amount.
-	Amount Amount `json:"amount"`
+	// Amount contains details concerning the transaction amount, or is omitted if several countries were requested.
+	Amount *Amount `json:"amount,omitempty"`
+
+	// Amounts holds the transaction amount converted to the currency of each requested country, in the order in which
+	// they were requested, or is omitted if only one country was requested.
+	Amounts []CountryAmount `json:"amounts,omitempty"`
+
+	// Voided contains the details of the transaction having been voided, or is omitted if it has not been voided.
+	Voided *VoidDetails `json:"voided,omitempty"`
+}
+
+// VoidDetails contains the details of a transaction having been voided.
+type VoidDetails struct {
+	// Reason is the supplied explanation for voiding the transaction.
+	Reason string `json:"reason"`
+
+	// VoidedAt is the time at which the transaction was voided.
+	VoidedAt time.Time `json:"voidedAt"`
 }
 
 // Amount contains the various details relating to the amount of the transaction
@@ -33,8 +54,48 @@ type Amount struct {
 	// ConvertedAmountInCents is the original transaction amount converted to the currency of the requested country
 	ConvertedAmountInCents int `json:"convertedAmountInCents"`
 
-	// ExchangeRate is the rate used to convert the USDAmountInCents to ConvertedAmountInCents
-	ExchangeRate float64 `json:"exchangeRate"`
+	// ExchangeRate is the rate used to convert the USDAmountInCents to ConvertedAmountInCents, written exactly as
+	// recorded, e.g. 1.495
+	ExchangeRate json.Number `json:"exchangeRate"`
+
+	// RateDate is the date of the exchange rate record from which the ExchangeRate was taken
+	RateDate *FormattedDate `json:"rateDate"`
+
+	// ValuationDate is the date as at which the amount was converted: the transaction date, or the requested 'as of'
+	// date.
+	ValuationDate *FormattedDate `json:"valuationDate,omitempty"`
+
+	// CurrencyCode is the ISO 4217 code of the currency converted to, or is omitted if it is not known.
+	CurrencyCode string `json:"currencyCode,omitempty"`
+
+	// RoundingMode is how the ConvertedAmountInCents was rounded to a whole cent, e.g. HALF_EVEN
+	RoundingMode string `json:"roundingMode,omitempty"`
+
+	// Fallback is present when the ExchangeRate is the last one known, used because the exchange rate could not be
+	// looked up.
+	Fallback *RateFallback `json:"fallback,omitempty"`
+}
+
+// CountryAmount contains the outcome of converting the transaction amount to the currency of one of several requested
+// countries.
+type CountryAmount struct {
+	// Country is the requested country.
+	Country string `json:"country"`
+
+	// Amount contains details of the converted transaction amount, or is omitted if it could not be converted.
+	Amount *Amount `json:"amount,omitempty"`
+
+	// Error explains why the amount could not be converted, or is omitted if it was converted.
+	Error *business.Error `json:"error,omitempty"`
+}
+
+// RateFallback describes how old the last known exchange rate used in place of a failed lookup is.
+type RateFallback struct {
+	// FetchedAt is when the exchange rate was last looked up successfully.
+	FetchedAt time.Time `json:"fetchedAt"`
+
+	// AgeSeconds is how many seconds before the failed lookup the exchange rate was last looked up successfully.
+	AgeSeconds int64 `json:"ageSeconds"`
 }
 
 // FormattedDate enables custom serialization of the transactionDate field to the response.
@@ -47,6 +108,41 @@ func (d *FormattedDate) MarshalJSON() ([]byte, error) {
 	return json.Marshal(d.Time.Format("2006-01-02"))
 }
 
+// ListResponse represents the response for a 'list transactions' operation, containing a page of transactions.
+type ListResponse struct {
+	// Transactions holds the transactions on the page, in the requested order.
+	Transactions []Summary `json:"transactions"`
+
+	// NextCursor is the cursor with which to fetch the next page, or is omitted if this is the last page.
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// Summary represents the details of a listed transaction.
+type Summary struct {
+	// ID is the generated id for the transaction.
+	ID string `json:"id"`
+
+	// Description is the supplied text description of the transaction.
+	Description string `json:"description"`
+
+	// TransactionDate is the date on which the transaction occurred.
+	TransactionDate *FormattedDate `json:"transactionDate"`
+
+	// AmountInCents is the original transaction amount.
+	AmountInCents int `json:"amountInCents"`
+
+	// Amount contains details of the converted transaction amount, or is omitted if no country was requested or the
+	// amount could not be converted.
+	Amount *Amount `json:"amount,omitempty"`
+
+	// ConversionError explains why the amount could not be converted to the currency of the requested country, or is
+	// omitted if it was converted.
+	ConversionError string `json:"conversionError,omitempty"`
+
+	// Voided contains the details of the transaction having been voided, or is omitted if it has not been voided.
+	Voided *VoidDetails `json:"voided,omitempty"`
+}
+
 // StoreResponse represents the response for a 'store transaction' operation, and contains the generated id for
 // transaction.
 type StoreResponse struct {
@@ -54,3 +150,38 @@ type StoreResponse struct {
 	// ID is the transaction's generated id
 	ID string `json:"id"`
 }
+
+// BatchResponse represents the response for a 'store transactions in batch' operation, containing the outcome for each
+// transaction in the batch.
+type BatchResponse struct {
+	// Results holds the outcome for each transaction, in the order in which they were supplied.
+	Results []BatchResult `json:"results"`
+
+	// Stored is the number of transactions that were stored.
+	Stored int `json:"stored"`
+
+	// Failed is the number of transactions that failed validation.
+	Failed int `json:"failed"`
+}
+
+// BatchResult represents the outcome for a single transaction of a batch.
+type BatchResult struct {
+	// Index is the position of the transaction within the batch, counting from zero.
+	Index int `json:"index"`
+
+	// ID is the transaction's generated id, or is omitted if it was not stored.
+	ID string `json:"id,omitempty"`
+
+	// Error contains the reasons the transaction failed validation, or is omitted if it is valid.
+	Error *business.Error `json:"error,omitempty"`
+}
+
+// UpdateResponse represents the response for an 'update transaction' operation.
+type UpdateResponse struct {
+
+	// ID is the id of the updated transaction
+	ID string `json:"id"`
+
+	// Version is the new version of the transaction, which is returned in the ETag header rather than the body.
+	Version int `json:"-"`
+}
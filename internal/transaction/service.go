@@ -2,6 +2,11 @@ package transaction
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"sync"
 	"time"
 
 	"transaction-service/internal/business"
@@ -10,44 +15,173 @@ import (
 )
 
 const (
-	transactionNotFound = "TRANSACTION_NOT_FOUND"
+	transactionNotFound     = "TRANSACTION_NOT_FOUND"
+	transactionVoided       = "TRANSACTION_VOIDED"
+	transactionNotVoided    = "TRANSACTION_NOT_VOIDED"
+	versionMismatch         = "TRANSACTION_VERSION_MISMATCH"
+	idempotencyKeyReused    = "IDEMPOTENCY_KEY_REUSED"
+	idempotencyKeyFieldName = "idempotencyKey"
+	idempotencyKeyMaxLength = 255
+	exchangeRateUnavailable = "EXCHANGE_RATE_UNAVAILABLE"
 )
 
 // ForExService is the expected interface for the service used to determine the exchange rate and perform the
 // exchange rate calculation
 type ForExService interface {
-	Convert(ctx context.Context, country string, dateOfOldestExchangeRate time.Time, amountInCents int) (forex.ConversionResult, error)
+	Convert(ctx context.Context, country string, selection forex.RateSelection, amountInCents int) (forex.ConversionResult, error)
+	ConvertToCurrency(ctx context.Context, code string, selection forex.RateSelection, amountInCents int) (forex.ConversionResult, error)
 }
 
+// defaultSort and defaultLimit are used to list transactions when no sort or limit is requested.
+var defaultSort = Sort{Field: SortByTransactionDate, Descending: true}
+
+const defaultLimit = 50
+
+// exportPageSize is the number of transactions read from the repository at a time when exporting transactions.
+const exportPageSize = 100
+
+// conversionWorkers is the greatest number of conversions run at once when a transaction is fetched for several
+// countries.
+const conversionWorkers = 4
+
+// AnyVersion may be supplied as the expected version when updating a transaction, to update it whatever its current
+// version.
+const AnyVersion = 0
+
 // Repository is the expected interface for the repository of transactions.
 type Repository interface {
 	Save(transaction Entity) (Entity, error)
-	FindByID(id string) Entity
+	SaveAll(transactions []Entity) ([]Entity, error)
+	Update(transaction Entity) (Entity, error)
+	FindByID(id string) (Entity, error)
+	Query(query Query) ([]Entity, error)
+}
+
+// IdempotencyStore is the expected interface for the store of idempotency records, which sits beside the Repository
+// to prevent a retried 'store transaction' request from storing the transaction twice.
+type IdempotencyStore interface {
+	Save(key string, record IdempotencyRecord) error
+	FindByKey(key string) (IdempotencyRecord, error)
+}
+
+// transactionalIdempotencyStore is implemented by an IdempotencyStore that can store a transaction together with the
+// idempotency record of its key as a single write, such as the SQLIdempotencyStore.  Should the process stop part way
+// through, neither is then stored, so a retry cannot store the transaction a second time.
+type transactionalIdempotencyStore interface {
+	SaveTransaction(key string, fingerprint string, txn Entity) (Entity, error)
 }
 
-// NewRepositoryService creates a RepositoryService that uses the supplied transaction repository and foreign exchange
-// service.
-func NewRepositoryService(txnRepository Repository, forExService ForExService) *RepositoryService {
+// NewRepositoryService creates a RepositoryService that uses the supplied transaction repository, idempotency store and
+// foreign exchange service.
+func NewRepositoryService(txnRepository Repository, idempotencyStore IdempotencyStore, forExService ForExService) *RepositoryService {
 	return &RepositoryService{
-		txnRepository:  txnRepository,
-		forExService:   forExService,
-		fetchValidator: fetchValidator{},
-		storeValidator: storeValidator{},
+		txnRepository:    txnRepository,
+		idempotencyStore: idempotencyStore,
+		forExService:     forExService,
+		fetchValidator:   fetchValidator{},
+		storeValidator:   storeValidator{},
+		voidValidator:    voidValidator{},
+		batchValidator:   batchValidator{},
+		listValidator:    listValidator{},
+		exportValidator:  exportValidator{},
+		now:              time.Now,
 	}
 }
 
 // RepositoryService is responsible for orchestrating the processes to store transactions and fetch transactions with
 // the amount converted to the currency of the requested country.
 type RepositoryService struct {
-	txnRepository  Repository
-	forExService   ForExService
-	storeValidator storeValidator
-	fetchValidator fetchValidator
+	txnRepository    Repository
+	idempotencyStore IdempotencyStore
+	idempotencyLocks keyLocks
+	forExService     ForExService
+	storeValidator   storeValidator
+	fetchValidator   fetchValidator
+	voidValidator    voidValidator
+	batchValidator   batchValidator
+	listValidator    listValidator
+	exportValidator  exportValidator
+	now              func() time.Time
 }
 
 // Store first ensures the request is validated, then stores the transaction in the repository and returns the new id
-// generated for the transaction.
+// generated for the transaction.  When the request carries an idempotency key, the transaction is only stored the first
+// time the key is seen.  Replaying the key with an identical request returns the original response, whereas replaying
+// it with a different request results in a conflict error.
 func (s *RepositoryService) Store(txn StoreRequest) (StoreResponse, error) {
+	if txn.IdempotencyKey == "" {
+		return s.store(txn, s.txnRepository.Save)
+	}
+	return s.storeIdempotently(txn)
+}
+
+// storeIdempotently stores the transaction unless its idempotency key has already been seen.  Requests carrying the
+// same key are processed one at a time so that concurrent retries cannot both store the transaction, while requests
+// carrying different keys do not wait on each other.  Should another instance of the service sharing the
+// IdempotencyStore save the key first, the request is answered as a replay of the one it stored.
+func (s *RepositoryService) storeIdempotently(txn StoreRequest) (StoreResponse, error) {
+	if err := validation.IsMaxLength(idempotencyKeyFieldName, &txn.IdempotencyKey, idempotencyKeyMaxLength); err != nil {
+		return StoreResponse{}, checkForErrors([]business.FieldError{*err})
+	}
+	fingerprint, err := fingerprint(txn)
+	if err != nil {
+		return StoreResponse{}, err
+	}
+	unlock := s.idempotencyLocks.lock(txn.IdempotencyKey)
+	defer unlock()
+	record, err := s.idempotencyStore.FindByKey(txn.IdempotencyKey)
+	if err != nil {
+		return StoreResponse{}, err
+	}
+	if record != (IdempotencyRecord{}) {
+		return replay(record, fingerprint)
+	}
+	response, err := s.storeWithKey(txn, fingerprint)
+	if errors.Is(err, ErrIdempotencyKeyExists) {
+		record, err = s.idempotencyStore.FindByKey(txn.IdempotencyKey)
+		if err != nil {
+			return StoreResponse{}, err
+		}
+		return replay(record, fingerprint)
+	}
+	return response, err
+}
+
+// storeWithKey stores the transaction along with the idempotency record of its key.  Where the IdempotencyStore can
+// store both as a single write it does, otherwise the record is saved once the transaction has been stored in the
+// repository.
+func (s *RepositoryService) storeWithKey(txn StoreRequest, fingerprint string) (StoreResponse, error) {
+	if store, ok := s.idempotencyStore.(transactionalIdempotencyStore); ok {
+		return s.store(txn, func(entity Entity) (Entity, error) {
+			return store.SaveTransaction(txn.IdempotencyKey, fingerprint, entity)
+		})
+	}
+	response, err := s.store(txn, s.txnRepository.Save)
+	if err != nil {
+		return StoreResponse{}, err
+	}
+	record := IdempotencyRecord{
+		Fingerprint: fingerprint,
+		Response:    response,
+	}
+	if err := s.idempotencyStore.Save(txn.IdempotencyKey, record); err != nil {
+		return StoreResponse{}, err
+	}
+	return response, nil
+}
+
+// replay returns the response stored with an idempotency key, or a conflict error if the key was stored with a
+// request whose fingerprint differs from the supplied one.
+func replay(record IdempotencyRecord, fingerprint string) (StoreResponse, error) {
+	if record.Fingerprint != fingerprint {
+		return StoreResponse{}, &business.Error{Message: idempotencyKeyReused, Kind: business.Conflict}
+	}
+	return record.Response, nil
+}
+
+// store validates the request, then stores the transaction using the supplied save function and returns the new id
+// generated for it.
+func (s *RepositoryService) store(txn StoreRequest, save func(Entity) (Entity, error)) (StoreResponse, error) {
 	if err := s.storeValidator.validate(txn); err != nil {
 		return StoreResponse{}, err
 	}
@@ -55,7 +189,7 @@ func (s *RepositoryService) Store(txn StoreRequest) (StoreResponse, error) {
 	if err != nil {
 		return StoreResponse{}, err
 	}
-	updated, err := s.txnRepository.Save(entity)
+	updated, err := save(entity)
 	if err != nil {
 		return StoreResponse{}, err
 	}
@@ -64,45 +198,462 @@ func (s *RepositoryService) Store(txn StoreRequest) (StoreResponse, error) {
 	}, nil
 }
 
+// StoreBatch first ensures the batch is of a suitable size, then validates each transaction in it and stores those that
+// are valid in the repository with a single operation, returning the outcome for each transaction: either its new id
+// or the reasons it is invalid.  When the request is atomic, nothing is stored unless every transaction is valid.
+func (s *RepositoryService) StoreBatch(request BatchRequest) (BatchResponse, error) {
+	if err := s.batchValidator.validate(request); err != nil {
+		return BatchResponse{}, err
+	}
+	response := BatchResponse{Results: make([]BatchResult, len(request.Transactions))}
+	var entities []Entity
+	var indexes []int
+	for i, txn := range request.Transactions {
+		response.Results[i].Index = i
+		if err := s.storeValidator.validate(txn); err != nil {
+			var businessErr *business.Error
+			if !errors.As(err, &businessErr) {
+				return BatchResponse{}, err
+			}
+			response.Results[i].Error = businessErr
+			response.Failed++
+			continue
+		}
+		entity, err := mapToEntity(txn)
+		if err != nil {
+			return BatchResponse{}, err
+		}
+		entities = append(entities, entity)
+		indexes = append(indexes, i)
+	}
+	if len(entities) == 0 || (request.Atomic && response.Failed > 0) {
+		return response, nil
+	}
+	saved, err := s.txnRepository.SaveAll(entities)
+	if err != nil {
+		return BatchResponse{}, err
+	}
+	for i, entity := range saved {
+		response.Results[indexes[i]].ID = entity.ID
+	}
+	response.Stored = len(saved)
+	return response, nil
+}
+
+// Replace first ensures the request is validated, then replaces every detail of the transaction with the supplied id,
+// provided its current version matches the supplied version (or AnyVersion is supplied).  A voided transaction cannot
+// be changed.
+func (s *RepositoryService) Replace(transactionID string, version int, txn StoreRequest) (UpdateResponse, error) {
+	if err := s.storeValidator.validate(txn); err != nil {
+		return UpdateResponse{}, err
+	}
+	current, err := s.findForEdit(transactionID, version)
+	if err != nil {
+		return UpdateResponse{}, err
+	}
+	return s.update(current, txn)
+}
+
+// Amend changes only those details of the transaction with the supplied id that are present in the request, provided
+// its current version matches the supplied version (or AnyVersion is supplied).  The amended transaction must satisfy
+// the same validation rules as a newly stored one.  A voided transaction cannot be changed.
+func (s *RepositoryService) Amend(transactionID string, version int, txn StoreRequest) (UpdateResponse, error) {
+	current, err := s.findForEdit(transactionID, version)
+	if err != nil {
+		return UpdateResponse{}, err
+	}
+	amended := mapToStoreRequest(current)
+	if txn.Description != nil {
+		amended.Description = txn.Description
+	}
+	if txn.TransactionDate != nil {
+		amended.TransactionDate = txn.TransactionDate
+	}
+	if txn.AmountInCents != nil {
+		amended.AmountInCents = txn.AmountInCents
+	}
+	if err := s.storeValidator.validate(amended); err != nil {
+		return UpdateResponse{}, err
+	}
+	return s.update(current, amended)
+}
+
+// findForUpdate fetches the transaction with the supplied id, ensuring it exists and is at the supplied version.  When
+// AnyVersion is supplied, the transaction's current version is used.
+func (s *RepositoryService) findForUpdate(transactionID string, version int) (Entity, error) {
+	current, err := s.txnRepository.FindByID(transactionID)
+	if err != nil {
+		return Entity{}, err
+	}
+	if current == (Entity{}) {
+		return Entity{}, &business.Error{Message: transactionNotFound}
+	}
+	if version != AnyVersion && version != current.Version {
+		return Entity{}, &business.Error{Message: versionMismatch, Kind: business.PreconditionFailed}
+	}
+	return current, nil
+}
+
+// Void first ensures the request is validated, then marks the transaction with the supplied id as voided, provided its
+// current version matches the supplied version (or AnyVersion is supplied).  The transaction is kept, along with the
+// reason for voiding it and the time at which it was voided, so that it can later be restored.
+func (s *RepositoryService) Void(transactionID string, version int, request VoidRequest) (UpdateResponse, error) {
+	if err := s.voidValidator.validate(request); err != nil {
+		return UpdateResponse{}, err
+	}
+	current, err := s.findForUpdate(transactionID, version)
+	if err != nil {
+		return UpdateResponse{}, err
+	}
+	if current.Voided != nil {
+		return UpdateResponse{}, &business.Error{Message: transactionVoided, Kind: business.Conflict}
+	}
+	current.Voided = &Void{
+		Reason: *request.Reason,
+		At:     s.now().UTC(),
+	}
+	return s.commit(current)
+}
+
+// Restore reinstates the voided transaction with the supplied id, provided its current version matches the supplied
+// version (or AnyVersion is supplied).
+func (s *RepositoryService) Restore(transactionID string, version int) (UpdateResponse, error) {
+	current, err := s.findForUpdate(transactionID, version)
+	if err != nil {
+		return UpdateResponse{}, err
+	}
+	if current.Voided == nil {
+		return UpdateResponse{}, &business.Error{Message: transactionNotVoided, Kind: business.Conflict}
+	}
+	current.Voided = nil
+	return s.commit(current)
+}
+
+// findForEdit fetches the transaction with the supplied id as findForUpdate does, additionally ensuring it has not been
+// voided.
+func (s *RepositoryService) findForEdit(transactionID string, version int) (Entity, error) {
+	current, err := s.findForUpdate(transactionID, version)
+	if err != nil {
+		return Entity{}, err
+	}
+	if current.Voided != nil {
+		return Entity{}, &business.Error{Message: transactionVoided, Kind: business.Conflict}
+	}
+	return current, nil
+}
+
+// update stores the details in the request against the current transaction.
+func (s *RepositoryService) update(current Entity, txn StoreRequest) (UpdateResponse, error) {
+	entity, err := mapToEntity(txn)
+	if err != nil {
+		return UpdateResponse{}, err
+	}
+	entity.ID = current.ID
+	entity.Version = current.Version
+	entity.Voided = current.Voided
+	return s.commit(entity)
+}
+
+// commit stores the changed transaction in the repository.  The repository checks the version again, since the
+// transaction may have been changed by someone else since it was fetched.
+func (s *RepositoryService) commit(entity Entity) (UpdateResponse, error) {
+	updated, err := s.txnRepository.Update(entity)
+	if errors.Is(err, ErrVersionConflict) {
+		return UpdateResponse{}, &business.Error{Message: versionMismatch, Kind: business.PreconditionFailed}
+	}
+	if errors.Is(err, ErrNotFound) {
+		return UpdateResponse{}, &business.Error{Message: transactionNotFound}
+	}
+	if err != nil {
+		return UpdateResponse{}, err
+	}
+	return UpdateResponse{
+		ID:      updated.ID,
+		Version: updated.Version,
+	}, nil
+}
+
 // Fetch first ensures the country is validated, then fetches the transaction from the repository, has its amount
 // converted to the currency of the requested country and returns the transaction details, including the exchange rate
-// used and the converted currency amount.
+// used and the converted currency amount.  When several countries are requested, the amount is converted to the
+// currency of each of them concurrently, and a country whose conversion fails is reported alongside the others rather
+// than failing the fetch (see convertForCountries).  A voided transaction is only returned when the request asks to
+// include voided transactions.
 //
 // transactionID cannot be invalid since the path parameter used in the route makes this impossible.  We could add
 // validation for transactionID here, but since it will never be executed in the current configuration I have left it
 // out for now.
-func (s *RepositoryService) Fetch(ctx context.Context, transactionID, country string) (FetchResponse, error) {
-	if err := s.fetchValidator.validate(country); err != nil {
+func (s *RepositoryService) Fetch(ctx context.Context, request FetchRequest) (FetchResponse, error) {
+	if err := s.fetchValidator.validate(request); err != nil {
+		return FetchResponse{}, err
+	}
+	entity, err := s.txnRepository.FindByID(request.TransactionID)
+	if err != nil {
 		return FetchResponse{}, err
 	}
-	entity := s.txnRepository.FindByID(transactionID)
 	if entity == (Entity{}) {
 		return FetchResponse{}, &business.Error{Message: transactionNotFound}
 	}
-	dateOfOldestExchangeRate := monthsOlderThan(entity.TransactionDate, 6)
-	result, err := s.forExService.Convert(ctx, country, dateOfOldestExchangeRate, entity.AmountInCents)
-	if err != nil {
-		return FetchResponse{}, err
+	if entity.Voided != nil && !request.IncludeVoided {
+		return FetchResponse{}, &business.Error{Message: transactionVoided}
 	}
-	return FetchResponse{
+	response := FetchResponse{
+		Version: entity.Version,
 		Transaction: Response{
 			ID:          entity.ID,
 			Description: entity.Description,
 			TransactionDate: &FormattedDate{
 				Time: entity.TransactionDate,
 			},
-			Amount: Amount{
-				USDAmountInCents:       entity.AmountInCents,
-				ConvertedAmountInCents: result.Amount,
-				ExchangeRate:           result.ExchangeRate,
-			},
+			Voided: mapToVoidDetails(entity.Voided),
 		},
-	}, nil
+	}
+	valuation := mapValuation(request.AsOf, request.RatePolicy, request.RateWindowMonths)
+	if len(request.Countries) > 0 {
+		if response.Transaction.Amounts, err = s.convertForCountries(ctx, request.Countries, entity, valuation); err != nil {
+			return FetchResponse{}, err
+		}
+		return response, nil
+	}
+	var amount Amount
+	if request.Currency != "" {
+		amount, err = s.convertToCurrency(ctx, request.Currency, entity, valuation)
+	} else {
+		amount, err = s.convert(ctx, request.Country, entity, valuation)
+	}
+	if err != nil {
+		return FetchResponse{}, err
+	}
+	response.Transaction.Amount = &amount
+	return response, nil
+}
+
+// convertForCountries has the amount of the supplied transaction converted to the currency of each of the supplied
+// countries, using up to conversionWorkers workers at once, and returns the outcomes in the order of the countries.  A
+// business error is reported against the country it occurred for.  Any other error, such as the exchange rate lookup
+// failing, is logged and reported against the country as EXCHANGE_RATE_UNAVAILABLE, unless the context is done, in
+// which case the context's error is returned.
+func (s *RepositoryService) convertForCountries(ctx context.Context, countries []string, entity Entity, valuation valuation) ([]CountryAmount, error) {
+	amounts := make([]CountryAmount, len(countries))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < conversionWorkers && worker < len(countries); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				amounts[i] = s.convertForCountry(ctx, countries[i], entity, valuation)
+			}
+		}()
+	}
+	for i := range countries {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return amounts, nil
 }
 
-// monthsOlderThan returns a time.Time representing a date that is numberOfMonths earlier than the date provided.
-func monthsOlderThan(date time.Time, numberOfMonths int) time.Time {
-	return date.AddDate(0, numberOfMonths*-1, 0)
+// convertForCountry has the amount of the supplied transaction converted to the currency of the supplied country,
+// reporting any error in the returned CountryAmount.
+func (s *RepositoryService) convertForCountry(ctx context.Context, country string, entity Entity, valuation valuation) CountryAmount {
+	amount, err := s.convert(ctx, country, entity, valuation)
+	if err == nil {
+		return CountryAmount{Country: country, Amount: &amount}
+	}
+	var businessErr *business.Error
+	if !errors.As(err, &businessErr) {
+		log.Printf("unable to convert transaction %s for %s: %v\n", entity.ID, country, err)
+		businessErr = &business.Error{Message: exchangeRateUnavailable}
+	}
+	return CountryAmount{Country: country, Error: businessErr}
+}
+
+// List first ensures the request is validated, then lists a page of the transactions satisfying the request's filters
+// in the requested order, along with a cursor with which to fetch the next page.  When a country is requested, the
+// amount of each transaction is converted to its currency.  A transaction whose amount cannot be converted is still
+// listed, explaining why it was not converted, so that one transaction cannot prevent the others from being listed.
+func (s *RepositoryService) List(ctx context.Context, request ListRequest) (ListResponse, error) {
+	if err := s.listValidator.validate(request); err != nil {
+		return ListResponse{}, err
+	}
+	query, err := mapToQuery(request)
+	if err != nil {
+		return ListResponse{}, err
+	}
+	limit := query.Limit
+	// One more transaction than will be listed is queried, to find out whether there is a next page.
+	query.Limit++
+	entities, err := s.txnRepository.Query(query)
+	if err != nil {
+		return ListResponse{}, err
+	}
+	response := ListResponse{Transactions: make([]Summary, 0, len(entities))}
+	if len(entities) > limit {
+		entities = entities[:limit]
+		if response.NextCursor, err = encodeCursor(query.Sort, positionOf(entities[limit-1])); err != nil {
+			return ListResponse{}, err
+		}
+	}
+	valuation := mapValuation(request.AsOf, request.RatePolicy, request.RateWindowMonths)
+	for _, entity := range entities {
+		summary, err := s.summarise(ctx, entity, request.Country, valuation)
+		if err != nil {
+			return ListResponse{}, err
+		}
+		response.Transactions = append(response.Transactions, summary)
+	}
+	return response, nil
+}
+
+// Export first ensures the request is validated, then passes every transaction satisfying the request's filters to the
+// supplied write function, in the requested order, with its amount converted to the currency of the requested country.
+// Transactions are read from the repository a page at a time and written as soon as they are converted, so the export
+// is never held in memory as a whole.  As with List, a transaction whose amount cannot be converted is still written,
+// explaining why it was not converted.  Since the export may already be partly written, this includes the exchange
+// rate being unavailable, which List reports as an error.  Should an error be returned, write may already have been
+// called.
+func (s *RepositoryService) Export(ctx context.Context, request ExportRequest, write func(Summary) error) error {
+	if err := s.exportValidator.validate(request); err != nil {
+		return err
+	}
+	query, err := mapToQuery(request.listRequest())
+	if err != nil {
+		return err
+	}
+	query.Limit = exportPageSize
+	valuation := mapValuation(request.AsOf, request.RatePolicy, request.RateWindowMonths)
+	for {
+		entities, err := s.txnRepository.Query(query)
+		if err != nil {
+			return err
+		}
+		for _, entity := range entities {
+			if err := write(s.exportSummary(ctx, entity, *request.Country, valuation)); err != nil {
+				return err
+			}
+		}
+		if len(entities) < query.Limit {
+			return nil
+		}
+		after := positionOf(entities[len(entities)-1])
+		query.After = &after
+	}
+}
+
+// summarise maps the supplied transaction into a Summary, having its amount converted to the currency of the supplied
+// country as for the supplied valuation, if a country is supplied.  Should the amount not be converted because of a
+// business error, the Summary explains why instead.
+func (s *RepositoryService) summarise(ctx context.Context, entity Entity, country *string, valuation valuation) (Summary, error) {
+	summary := mapToSummary(entity)
+	if country == nil {
+		return summary, nil
+	}
+	amount, err := s.convert(ctx, *country, entity, valuation)
+	var businessErr *business.Error
+	switch {
+	case errors.As(err, &businessErr):
+		summary.ConversionError = businessErr.Message
+	case err != nil:
+		return Summary{}, err
+	default:
+		summary.Amount = &amount
+	}
+	return summary, nil
+}
+
+// exportSummary maps the supplied transaction into a Summary, having its amount converted to the currency of the
+// supplied country as for the supplied valuation.  Should the amount not be converted for any reason, the Summary
+// explains why instead, as for convertForCountry.
+func (s *RepositoryService) exportSummary(ctx context.Context, entity Entity, country string, valuation valuation) Summary {
+	summary := mapToSummary(entity)
+	converted := s.convertForCountry(ctx, country, entity, valuation)
+	summary.Amount = converted.Amount
+	if converted.Error != nil {
+		summary.ConversionError = converted.Error.Message
+	}
+	return summary
+}
+
+// convert has the amount of the supplied transaction converted to the currency of the supplied country, using an
+// exchange rate selected as for the supplied valuation.
+func (s *RepositoryService) convert(ctx context.Context, country string, entity Entity, valuation valuation) (Amount, error) {
+	selection := valuation.selection(entity)
+	result, err := s.forExService.Convert(ctx, country, selection, entity.AmountInCents)
+	if err != nil {
+		return Amount{}, err
+	}
+	return mapToAmount(entity, selection.Date, result), nil
+}
+
+// convertToCurrency has the amount of the supplied transaction converted to the currency with the supplied ISO 4217
+// code, using an exchange rate selected as for convert.
+func (s *RepositoryService) convertToCurrency(ctx context.Context, code string, entity Entity, valuation valuation) (Amount, error) {
+	selection := valuation.selection(entity)
+	result, err := s.forExService.ConvertToCurrency(ctx, code, selection, entity.AmountInCents)
+	if err != nil {
+		return Amount{}, err
+	}
+	return mapToAmount(entity, selection.Date, result), nil
+}
+
+// mapToAmount maps the result of converting the amount of the supplied transaction as at the supplied valuation date
+// into an Amount.
+func mapToAmount(entity Entity, valuationDate time.Time, result forex.ConversionResult) Amount {
+	amount := Amount{
+		USDAmountInCents:       entity.AmountInCents,
+		ConvertedAmountInCents: result.Amount,
+		ExchangeRate:           json.Number(result.ExchangeRate.Value),
+		RateDate:               &FormattedDate{Time: result.RateDate},
+		ValuationDate:          &FormattedDate{Time: valuationDate},
+		CurrencyCode:           result.CurrencyCode,
+		RoundingMode:           string(result.RoundingMode),
+	}
+	if result.Fallback != nil {
+		amount.Fallback = &RateFallback{
+			FetchedAt:  result.Fallback.FetchedAt,
+			AgeSeconds: int64(result.Fallback.Age / time.Second),
+		}
+	}
+	return amount
+}
+
+// valuation holds the options supplied with a request for how transaction amounts are converted.
+type valuation struct {
+	// asOf is the date as at which amounts are converted, or the zero time to convert each as at its transaction date.
+	asOf time.Time
+
+	// rules override the configured rules by which exchange rates are selected.
+	rules forex.RateRules
+}
+
+// selection returns the forex.RateSelection by which the amount of the supplied transaction is converted.
+func (v valuation) selection(entity Entity) forex.RateSelection {
+	date := entity.TransactionDate
+	if !v.asOf.IsZero() {
+		date = v.asOf
+	}
+	return forex.RateSelection{Date: date, Rules: v.rules}
+}
+
+// mapValuation maps the supplied valuation date and overrides of the rules by which exchange rates are selected, which
+// have been validated, into a valuation.  Options that are not supplied are left as the zero value, so that amounts
+// are converted as at their transaction dates by the configured rules.
+func mapValuation(asOf, policy, windowMonths *string) valuation {
+	var v valuation
+	if asOf != nil {
+		v.asOf, _ = time.Parse(validation.DateFormat, *asOf)
+	}
+	if policy != nil {
+		v.rules.Policy = forex.RatePolicy(*policy)
+	}
+	if windowMonths != nil {
+		v.rules.WindowMonths, _ = strconv.Atoi(*windowMonths)
+	}
+	return v
 }
 
 // mapToEntity maps the provided transaction StoreRequest into a transaction Entity.
@@ -119,6 +670,107 @@ func mapToEntity(txn StoreRequest) (Entity, error) {
 	return entity, nil
 }
 
+// mapToQuery maps the provided ListRequest into the Query of the transactions to list.
+func mapToQuery(request ListRequest) (Query, error) {
+	query := Query{
+		IncludeVoided: request.IncludeVoided,
+		Sort:          mapSort(request.Sort),
+		Limit:         defaultLimit,
+	}
+	if request.FromDate != nil {
+		fromDate, err := mapDate(request.FromDate)
+		if err != nil {
+			return Query{}, err
+		}
+		query.FromDate = &fromDate
+	}
+	if request.ToDate != nil {
+		toDate, err := mapDate(request.ToDate)
+		if err != nil {
+			return Query{}, err
+		}
+		query.ToDate = &toDate
+	}
+	var err error
+	if query.MinAmountInCents, err = mapInt(request.MinAmountInCents); err != nil {
+		return Query{}, err
+	}
+	if query.MaxAmountInCents, err = mapInt(request.MaxAmountInCents); err != nil {
+		return Query{}, err
+	}
+	if request.Description != nil {
+		query.Description = *request.Description
+	}
+	if request.Limit != nil {
+		if query.Limit, err = strconv.Atoi(*request.Limit); err != nil {
+			return Query{}, err
+		}
+	}
+	if request.Cursor != nil {
+		after, err := decodeCursor(query.Sort, *request.Cursor)
+		if err != nil {
+			return Query{}, err
+		}
+		query.After = &after
+	}
+	return query, nil
+}
+
+// mapSort parses the supplied sort, returning the default Sort if none is supplied.
+func mapSort(sort *string) Sort {
+	if sort == nil {
+		return defaultSort
+	}
+	parsed, _ := parseSort(*sort)
+	return parsed
+}
+
+// mapInt parses the supplied whole number, returning nil if none is supplied.
+func mapInt(value *string) (*int, error) {
+	if value == nil {
+		return nil, nil
+	}
+	parsed, err := strconv.Atoi(*value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+// mapToSummary maps the provided transaction Entity into a Summary, without a converted amount.
+func mapToSummary(entity Entity) Summary {
+	return Summary{
+		ID:              entity.ID,
+		Description:     entity.Description,
+		TransactionDate: &FormattedDate{Time: entity.TransactionDate},
+		AmountInCents:   entity.AmountInCents,
+		Voided:          mapToVoidDetails(entity.Voided),
+	}
+}
+
+// mapToVoidDetails maps the provided Void into the VoidDetails of a response, or nil if there is no Void.
+func mapToVoidDetails(void *Void) *VoidDetails {
+	if void == nil {
+		return nil
+	}
+	return &VoidDetails{
+		Reason:   void.Reason,
+		VoidedAt: void.At,
+	}
+}
+
+// mapToStoreRequest maps the provided transaction Entity into a transaction StoreRequest.
+func mapToStoreRequest(entity Entity) StoreRequest {
+	description := entity.Description
+	txnDate := entity.TransactionDate.Format(validation.DateFormat)
+	amountInCents := entity.AmountInCents
+	return StoreRequest{
+		Description:     &description,
+		TransactionDate: &txnDate,
+		AmountInCents:   &amountInCents,
+	}
+}
+
 // mapDate parses the supplied date into a time.Time according to the configured date format.
 func mapDate(date *string) (time.Time, error) {
 	if date == nil {
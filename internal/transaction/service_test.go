@@ -3,6 +3,8 @@ package transaction_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -98,6 +100,529 @@ func TestServiceStore(t *testing.T) {
 	})
 }
 
+func TestServiceStoreBatch(t *testing.T) {
+	valid := transaction.StoreRequest{
+		Description:     stringPtr("*description*"),
+		TransactionDate: stringPtr("2022-10-01"),
+		AmountInCents:   intPtr(345),
+	}
+	invalid := transaction.StoreRequest{
+		Description:     stringPtr("*description*"),
+		TransactionDate: stringPtr("2022-10-01"),
+		AmountInCents:   intPtr(0),
+	}
+	entity := transaction.Entity{
+		Description:     "*description*",
+		TransactionDate: date.NewInUTC(2022, time.October, 1),
+		AmountInCents:   345,
+	}
+	invalidErr := &business.Error{
+		Fields: []business.FieldError{
+			{
+				FieldName: "amountInCents",
+				Reason:    "ZERO_VALUE",
+			},
+		},
+		Message: "VALIDATION_ERROR",
+	}
+	saved := func(ids ...string) []transaction.Entity {
+		var txns []transaction.Entity
+		for _, id := range ids {
+			txn := entity
+			txn.ID = id
+			txns = append(txns, txn)
+		}
+		return txns
+	}
+
+	t.Run("success", func(t *testing.T) {
+		t.Run("should store the valid transactions together and return the outcome for each transaction", func(t *testing.T) {
+			setUp()
+			mockRepo.On("SaveAll", []transaction.Entity{entity, entity}).Return(saved("*saved-1*", "*saved-2*"), nil)
+
+			response, err := service.StoreBatch(transaction.BatchRequest{
+				Transactions: []transaction.StoreRequest{valid, invalid, valid},
+			})
+
+			assert.Nil(t, err)
+			assert.Equal(t, transaction.BatchResponse{
+				Results: []transaction.BatchResult{
+					{Index: 0, ID: "*saved-1*"},
+					{Index: 1, Error: invalidErr},
+					{Index: 2, ID: "*saved-2*"},
+				},
+				Stored: 2,
+				Failed: 1,
+			}, response)
+			mockRepo.AssertExpectations(t)
+		})
+		t.Run("should store every transaction when the request is atomic and every transaction is valid", func(t *testing.T) {
+			setUp()
+			mockRepo.On("SaveAll", []transaction.Entity{entity, entity}).Return(saved("*saved-1*", "*saved-2*"), nil)
+
+			response, err := service.StoreBatch(transaction.BatchRequest{
+				Transactions: []transaction.StoreRequest{valid, valid},
+				Atomic:       true,
+			})
+
+			assert.Nil(t, err)
+			assert.Equal(t, 2, response.Stored)
+			mockRepo.AssertExpectations(t)
+		})
+		t.Run("should store nothing when the request is atomic and any transaction is invalid", func(t *testing.T) {
+			setUp()
+
+			response, err := service.StoreBatch(transaction.BatchRequest{
+				Transactions: []transaction.StoreRequest{valid, invalid},
+				Atomic:       true,
+			})
+
+			assert.Nil(t, err)
+			assert.Equal(t, transaction.BatchResponse{
+				Results: []transaction.BatchResult{
+					{Index: 0},
+					{Index: 1, Error: invalidErr},
+				},
+				Stored: 0,
+				Failed: 1,
+			}, response)
+			mockRepo.AssertExpectations(t)
+		})
+		t.Run("should store nothing when every transaction is invalid", func(t *testing.T) {
+			setUp()
+
+			response, err := service.StoreBatch(transaction.BatchRequest{
+				Transactions: []transaction.StoreRequest{invalid},
+			})
+
+			assert.Nil(t, err)
+			assert.Equal(t, 1, response.Failed)
+			mockRepo.AssertExpectations(t)
+		})
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		t.Run("should return a validation error when the batch is empty", func(t *testing.T) {
+			setUp()
+
+			response, err := service.StoreBatch(transaction.BatchRequest{})
+
+			expectedErr := &business.Error{
+				Fields: []business.FieldError{
+					{
+						FieldName: "transactions",
+						Reason:    "MIN_LENGTH",
+					},
+				},
+				Message: "VALIDATION_ERROR",
+			}
+			assert.Equal(t, expectedErr, err)
+			assert.Equal(t, transaction.BatchResponse{}, response)
+			mockRepo.AssertExpectations(t)
+		})
+		t.Run("should return a validation error when the batch is too large", func(t *testing.T) {
+			setUp()
+
+			response, err := service.StoreBatch(transaction.BatchRequest{
+				Transactions: make([]transaction.StoreRequest, 1001),
+			})
+
+			expectedErr := &business.Error{
+				Fields: []business.FieldError{
+					{
+						FieldName: "transactions",
+						Reason:    "MAX_LENGTH",
+					},
+				},
+				Message: "VALIDATION_ERROR",
+			}
+			assert.Equal(t, expectedErr, err)
+			assert.Equal(t, transaction.BatchResponse{}, response)
+			mockRepo.AssertExpectations(t)
+		})
+		t.Run("should return an error when a problem occurs storing the transactions", func(t *testing.T) {
+			setUp()
+			mockRepo.On("SaveAll", []transaction.Entity{entity}).
+				Return([]transaction.Entity(nil), errors.New("*save-error*"))
+
+			response, err := service.StoreBatch(transaction.BatchRequest{
+				Transactions: []transaction.StoreRequest{valid},
+			})
+
+			assert.EqualError(t, err, "*save-error*")
+			assert.Equal(t, transaction.BatchResponse{}, response)
+			mockRepo.AssertExpectations(t)
+		})
+	})
+}
+
+func TestServiceStoreIdempotently(t *testing.T) {
+	request := func(description string) transaction.StoreRequest {
+		return transaction.StoreRequest{
+			Description:     stringPtr(description),
+			TransactionDate: stringPtr("2022-10-01"),
+			AmountInCents:   intPtr(345),
+			IdempotencyKey:  "*key*",
+		}
+	}
+	expectSave := func() {
+		mockRepo.On("Save", mock.Anything).Return(transaction.Entity{ID: "*saved*"}, nil).Once()
+	}
+
+	t.Run("should store the transaction the first time the key is seen", func(t *testing.T) {
+		setUp()
+		expectSave()
+
+		response, err := service.Store(request("*description*"))
+
+		assert.Nil(t, err)
+		assert.Equal(t, transaction.StoreResponse{ID: "*saved*"}, response)
+		mockRepo.AssertExpectations(t)
+	})
+	t.Run("should return the original response without storing again when the key is replayed with an identical request", func(t *testing.T) {
+		setUp()
+		expectSave()
+		service.Store(request("*description*"))
+
+		response, err := service.Store(request("*description*"))
+
+		assert.Nil(t, err)
+		assert.Equal(t, transaction.StoreResponse{ID: "*saved*"}, response)
+		mockRepo.AssertNumberOfCalls(t, "Save", 1)
+	})
+	t.Run("should return a conflict error when the key is replayed with a different request", func(t *testing.T) {
+		setUp()
+		expectSave()
+		service.Store(request("*description*"))
+
+		response, err := service.Store(request("*another description*"))
+
+		assert.Equal(t, &business.Error{Message: "IDEMPOTENCY_KEY_REUSED", Kind: business.Conflict}, err)
+		assert.Equal(t, transaction.StoreResponse{}, response)
+		mockRepo.AssertNumberOfCalls(t, "Save", 1)
+	})
+	t.Run("should not remember the key when the request does not meet the business validation rules", func(t *testing.T) {
+		setUp()
+		invalid := request("")
+		_, err := service.Store(invalid)
+		assert.NotNil(t, err)
+		expectSave()
+
+		response, err := service.Store(request("*description*"))
+
+		assert.Nil(t, err)
+		assert.Equal(t, transaction.StoreResponse{ID: "*saved*"}, response)
+	})
+	t.Run("should return a validation error when the key is too long", func(t *testing.T) {
+		setUp()
+		txn := request("*description*")
+		txn.IdempotencyKey = strings.Repeat("k", 256)
+
+		_, err := service.Store(txn)
+
+		expectedErr := &business.Error{
+			Fields: []business.FieldError{
+				{
+					FieldName: "idempotencyKey",
+					Reason:    "MAX_LENGTH",
+				},
+			},
+			Message: "VALIDATION_ERROR",
+		}
+		assert.Equal(t, expectedErr, err)
+		mockRepo.AssertExpectations(t)
+	})
+	t.Run("should store the transaction together with the key when the store can write both at once", func(t *testing.T) {
+		setUp()
+		store := &MockTransactionalIdempotencyStore{}
+		service = transaction.NewRepositoryService(&mockRepo, store, &mockForEx)
+		store.On("FindByKey", "*key*").Return(transaction.IdempotencyRecord{}, nil)
+		store.On("SaveTransaction", "*key*", mock.Anything, mock.MatchedBy(func(txn transaction.Entity) bool {
+			return txn.Description == "*description*"
+		})).Return(transaction.Entity{ID: "*saved*"}, nil)
+
+		response, err := service.Store(request("*description*"))
+
+		assert.Nil(t, err)
+		assert.Equal(t, transaction.StoreResponse{ID: "*saved*"}, response)
+		store.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything)
+	})
+	t.Run("should return a conflict error when another instance saved the key first with a different request", func(t *testing.T) {
+		setUp()
+		store := &MockTransactionalIdempotencyStore{}
+		service = transaction.NewRepositoryService(&mockRepo, store, &mockForEx)
+		store.On("FindByKey", "*key*").Return(transaction.IdempotencyRecord{}, nil).Once()
+		store.On("SaveTransaction", "*key*", mock.Anything, mock.Anything).
+			Return(transaction.Entity{}, transaction.ErrIdempotencyKeyExists)
+		store.On("FindByKey", "*key*").Return(transaction.IdempotencyRecord{
+			Fingerprint: "*another-fingerprint*",
+			Response:    transaction.StoreResponse{ID: "*another-id*"},
+		}, nil).Once()
+
+		response, err := service.Store(request("*description*"))
+
+		assert.Equal(t, &business.Error{Message: "IDEMPOTENCY_KEY_REUSED", Kind: business.Conflict}, err)
+		assert.Equal(t, transaction.StoreResponse{}, response)
+		store.AssertExpectations(t)
+	})
+}
+
+func TestServiceUpdate(t *testing.T) {
+	stored := transaction.Entity{
+		ID:              "*txn-id*",
+		Description:     "*description*",
+		TransactionDate: date.NewInUTC(2022, time.October, 1),
+		AmountInCents:   345,
+		Version:         3,
+	}
+
+	t.Run("replace", func(t *testing.T) {
+		t.Run("success - should store every detail of the request against the transaction", func(t *testing.T) {
+			setUp()
+			mockRepo.On("FindByID", "*txn-id*").Return(stored, nil)
+			mockRepo.On("Update", transaction.Entity{
+				ID:              "*txn-id*",
+				Description:     "*changed*",
+				TransactionDate: date.NewInUTC(2022, time.November, 2),
+				AmountInCents:   -5,
+				Version:         3,
+			}).Return(transaction.Entity{ID: "*txn-id*", Version: 4}, nil)
+
+			response, err := service.Replace("*txn-id*", 3, transaction.StoreRequest{
+				Description:     stringPtr("*changed*"),
+				TransactionDate: stringPtr("2022-11-02"),
+				AmountInCents:   intPtr(-5),
+			})
+
+			assert.Nil(t, err)
+			assert.Equal(t, transaction.UpdateResponse{ID: "*txn-id*", Version: 4}, response)
+			mockRepo.AssertExpectations(t)
+		})
+		t.Run("failure - should return a validation error when the request does not meet the business validation rules", func(t *testing.T) {
+			setUp()
+
+			response, err := service.Replace("*txn-id*", 3, transaction.StoreRequest{
+				TransactionDate: stringPtr("2022-11-02"),
+				AmountInCents:   intPtr(-5),
+			})
+
+			expectedErr := &business.Error{
+				Fields: []business.FieldError{
+					{
+						FieldName: "description",
+						Reason:    "REQUIRED",
+					},
+				},
+				Message: "VALIDATION_ERROR",
+			}
+			assert.Equal(t, expectedErr, err)
+			assert.Equal(t, transaction.UpdateResponse{}, response)
+			mockRepo.AssertExpectations(t)
+		})
+	})
+
+	t.Run("amend", func(t *testing.T) {
+		t.Run("success - should store only the details present in the request against the transaction", func(t *testing.T) {
+			setUp()
+			mockRepo.On("FindByID", "*txn-id*").Return(stored, nil)
+			mockRepo.On("Update", transaction.Entity{
+				ID:              "*txn-id*",
+				Description:     "*description*",
+				TransactionDate: date.NewInUTC(2022, time.October, 1),
+				AmountInCents:   999,
+				Version:         3,
+			}).Return(transaction.Entity{ID: "*txn-id*", Version: 4}, nil)
+
+			response, err := service.Amend("*txn-id*", 3, transaction.StoreRequest{
+				AmountInCents: intPtr(999),
+			})
+
+			assert.Nil(t, err)
+			assert.Equal(t, transaction.UpdateResponse{ID: "*txn-id*", Version: 4}, response)
+			mockRepo.AssertExpectations(t)
+		})
+		t.Run("success - should update whatever the current version when any version is supplied", func(t *testing.T) {
+			setUp()
+			mockRepo.On("FindByID", "*txn-id*").Return(stored, nil)
+			mockRepo.On("Update", mock.MatchedBy(func(txn transaction.Entity) bool { return txn.Version == 3 })).
+				Return(transaction.Entity{ID: "*txn-id*", Version: 4}, nil)
+
+			response, err := service.Amend("*txn-id*", transaction.AnyVersion, transaction.StoreRequest{
+				AmountInCents: intPtr(999),
+			})
+
+			assert.Nil(t, err)
+			assert.Equal(t, transaction.UpdateResponse{ID: "*txn-id*", Version: 4}, response)
+			mockRepo.AssertExpectations(t)
+		})
+		t.Run("failure - should return a validation error when the amended transaction does not meet the business validation rules", func(t *testing.T) {
+			setUp()
+			mockRepo.On("FindByID", "*txn-id*").Return(stored, nil)
+
+			response, err := service.Amend("*txn-id*", 3, transaction.StoreRequest{
+				AmountInCents: intPtr(0),
+			})
+
+			expectedErr := &business.Error{
+				Fields: []business.FieldError{
+					{
+						FieldName: "amountInCents",
+						Reason:    "ZERO_VALUE",
+					},
+				},
+				Message: "VALIDATION_ERROR",
+			}
+			assert.Equal(t, expectedErr, err)
+			assert.Equal(t, transaction.UpdateResponse{}, response)
+			mockRepo.AssertExpectations(t)
+		})
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		tcs := []struct {
+			name      string
+			version   int
+			found     transaction.Entity
+			updateErr error
+			wantErr   error
+		}{
+			{
+				name:    "should return a precondition failed error when the supplied version is not the current version",
+				version: 2,
+				found:   stored,
+				wantErr: &business.Error{Message: "TRANSACTION_VERSION_MISMATCH", Kind: business.PreconditionFailed},
+			},
+			{
+				name:      "should return a precondition failed error when the transaction is changed by someone else in the meantime",
+				version:   3,
+				found:     stored,
+				updateErr: transaction.ErrVersionConflict,
+				wantErr:   &business.Error{Message: "TRANSACTION_VERSION_MISMATCH", Kind: business.PreconditionFailed},
+			},
+			{
+				name:    "should return an error when a transaction with the supplied id cannot be found",
+				version: 3,
+				found:   transaction.Entity{},
+				wantErr: &business.Error{Message: "TRANSACTION_NOT_FOUND"},
+			},
+			{
+				name:      "should return an error when there is a problem with the transaction repository",
+				version:   3,
+				found:     stored,
+				updateErr: errors.New("problem"),
+				wantErr:   errors.New("problem"),
+			},
+		}
+		for _, tc := range tcs {
+			t.Run(tc.name, func(t *testing.T) {
+				setUp()
+				mockRepo.On("FindByID", "*txn-id*").Return(tc.found, nil)
+				mockRepo.On("Update", mock.Anything).Return(transaction.Entity{}, tc.updateErr).Maybe()
+
+				response, err := service.Amend("*txn-id*", tc.version, transaction.StoreRequest{})
+
+				assert.Equal(t, tc.wantErr, err)
+				assert.Equal(t, transaction.UpdateResponse{}, response)
+				mockRepo.AssertExpectations(t)
+			})
+		}
+	})
+}
+
+func TestServiceVoid(t *testing.T) {
+	stored := transaction.Entity{
+		ID:              "*txn-id*",
+		Description:     "*description*",
+		TransactionDate: date.NewInUTC(2022, time.October, 1),
+		AmountInCents:   345,
+		Version:         3,
+	}
+	voided := stored
+	voided.Voided = &transaction.Void{Reason: "*reason*", At: time.Date(2022, time.October, 2, 0, 0, 0, 0, time.UTC)}
+
+	t.Run("void", func(t *testing.T) {
+		t.Run("success - should mark the transaction as voided with the supplied reason and the current time", func(t *testing.T) {
+			setUp()
+			before := time.Now()
+			mockRepo.On("FindByID", "*txn-id*").Return(stored, nil)
+			mockRepo.On("Update", mock.MatchedBy(func(txn transaction.Entity) bool {
+				return txn.ID == "*txn-id*" && txn.Version == 3 && txn.Description == "*description*" &&
+					txn.Voided != nil && txn.Voided.Reason == "*reason*" && !txn.Voided.At.Before(before.UTC().Truncate(time.Second))
+			})).Return(transaction.Entity{ID: "*txn-id*", Version: 4}, nil)
+
+			response, err := service.Void("*txn-id*", 3, transaction.VoidRequest{Reason: stringPtr("*reason*")})
+
+			assert.Nil(t, err)
+			assert.Equal(t, transaction.UpdateResponse{ID: "*txn-id*", Version: 4}, response)
+			mockRepo.AssertExpectations(t)
+		})
+		t.Run("failure - should return a validation error when no reason is supplied", func(t *testing.T) {
+			setUp()
+
+			response, err := service.Void("*txn-id*", 3, transaction.VoidRequest{})
+
+			expectedErr := &business.Error{
+				Fields: []business.FieldError{
+					{
+						FieldName: "reason",
+						Reason:    "REQUIRED",
+					},
+				},
+				Message: "VALIDATION_ERROR",
+			}
+			assert.Equal(t, expectedErr, err)
+			assert.Equal(t, transaction.UpdateResponse{}, response)
+			mockRepo.AssertExpectations(t)
+		})
+		t.Run("failure - should return a conflict error when the transaction is already voided", func(t *testing.T) {
+			setUp()
+			mockRepo.On("FindByID", "*txn-id*").Return(voided, nil)
+
+			response, err := service.Void("*txn-id*", transaction.AnyVersion, transaction.VoidRequest{Reason: stringPtr("*reason*")})
+
+			assert.Equal(t, &business.Error{Message: "TRANSACTION_VOIDED", Kind: business.Conflict}, err)
+			assert.Equal(t, transaction.UpdateResponse{}, response)
+			mockRepo.AssertExpectations(t)
+		})
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		t.Run("success - should clear the void from the transaction", func(t *testing.T) {
+			setUp()
+			mockRepo.On("FindByID", "*txn-id*").Return(voided, nil)
+			mockRepo.On("Update", stored).Return(transaction.Entity{ID: "*txn-id*", Version: 4}, nil)
+
+			response, err := service.Restore("*txn-id*", 3)
+
+			assert.Nil(t, err)
+			assert.Equal(t, transaction.UpdateResponse{ID: "*txn-id*", Version: 4}, response)
+			mockRepo.AssertExpectations(t)
+		})
+		t.Run("failure - should return a conflict error when the transaction is not voided", func(t *testing.T) {
+			setUp()
+			mockRepo.On("FindByID", "*txn-id*").Return(stored, nil)
+
+			response, err := service.Restore("*txn-id*", 3)
+
+			assert.Equal(t, &business.Error{Message: "TRANSACTION_NOT_VOIDED", Kind: business.Conflict}, err)
+			assert.Equal(t, transaction.UpdateResponse{}, response)
+			mockRepo.AssertExpectations(t)
+		})
+	})
+
+	t.Run("should not allow a voided transaction to be changed", func(t *testing.T) {
+		setUp()
+		mockRepo.On("FindByID", "*txn-id*").Return(voided, nil)
+
+		response, err := service.Amend("*txn-id*", 3, transaction.StoreRequest{AmountInCents: intPtr(1)})
+
+		assert.Equal(t, &business.Error{Message: "TRANSACTION_VOIDED", Kind: business.Conflict}, err)
+		assert.Equal(t, transaction.UpdateResponse{}, response)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
 func TestServiceFetch(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		t.Run("should return the fetched transaction details with the requested currency conversion for the supplied country", func(t *testing.T) {
@@ -108,27 +633,34 @@ func TestServiceFetch(t *testing.T) {
 					Description:     "*description*",
 					TransactionDate: date.NewInUTC(2022, time.May, 12),
 					AmountInCents:   543,
+					Version:         1,
 				}, nil)
 			mockForEx.On("Convert", ctx, "*country*", mock.Anything, 543).
 				Return(forex.ConversionResult{
 					Amount:       1234,
-					ExchangeRate: 0.456,
+					ExchangeRate: forex.ExchangeRate{Value: "0.456"},
+					RateDate:     date.NewInUTC(2022, time.March, 31),
+					RoundingMode: forex.HalfEvenRounding,
 				}, nil)
 
-			response, err := service.Fetch(ctx, "*txn-id*", "*country*")
+			response, err := service.Fetch(ctx, transaction.FetchRequest{TransactionID: "*txn-id*", Country: "*country*"})
 
 			assert.Nil(t, err)
 			expectedResponse := transaction.FetchResponse{
+				Version: 1,
 				Transaction: transaction.Response{
 					ID:          "*txn-id*",
 					Description: "*description*",
 					TransactionDate: &transaction.FormattedDate{
 						Time: date.NewInUTC(2022, time.May, 12),
 					},
-					Amount: transaction.Amount{
+					Amount: &transaction.Amount{
 						USDAmountInCents:       543,
 						ConvertedAmountInCents: 1234,
-						ExchangeRate:           0.456,
+						ExchangeRate:           "0.456",
+						RateDate:               &transaction.FormattedDate{Time: date.NewInUTC(2022, time.March, 31)},
+						ValuationDate:          &transaction.FormattedDate{Time: date.NewInUTC(2022, time.May, 12)},
+						RoundingMode:           "HALF_EVEN",
 					},
 				},
 			}
@@ -136,16 +668,194 @@ func TestServiceFetch(t *testing.T) {
 			mockRepo.AssertExpectations(t)
 			mockForEx.AssertExpectations(t)
 		})
-		t.Run("should request a foreign exchange rate that was recorded within six months of the transaction date", func(t *testing.T) {
+		t.Run("should say how old the exchange rate is when it is the last one known", func(t *testing.T) {
+			setUp()
+			mockRepo.On("FindByID", "*txn-id*").
+				Return(transaction.Entity{TransactionDate: date.NewInUTC(2022, time.May, 12), AmountInCents: 543}, nil)
+			mockForEx.On("Convert", ctx, "*country*", mock.Anything, 543).
+				Return(forex.ConversionResult{
+					Amount:       1234,
+					ExchangeRate: forex.ExchangeRate{Value: "0.456"},
+					RateDate:     date.NewInUTC(2022, time.March, 31),
+					Fallback: &forex.Fallback{
+						FetchedAt: time.Date(2022, time.June, 1, 9, 0, 0, 0, time.UTC),
+						Age:       90*time.Minute + 500*time.Millisecond,
+					},
+				}, nil)
+
+			response, err := service.Fetch(ctx, transaction.FetchRequest{TransactionID: "*txn-id*", Country: "*country*"})
+
+			assert.Nil(t, err)
+			assert.Equal(t, &transaction.RateFallback{
+				FetchedAt:  time.Date(2022, time.June, 1, 9, 0, 0, 0, time.UTC),
+				AgeSeconds: 5400,
+			}, response.Transaction.Amount.Fallback)
+		})
+		t.Run("should convert to the currency with the supplied code, including the code in the amount", func(t *testing.T) {
+			setUp()
+			mockRepo.On("FindByID", "*txn-id*").
+				Return(transaction.Entity{TransactionDate: date.NewInUTC(2022, time.May, 12), AmountInCents: 543}, nil)
+			mockForEx.On("ConvertToCurrency", ctx, "EUR", forex.RateSelection{Date: date.NewInUTC(2022, time.May, 12)}, 543).
+				Return(forex.ConversionResult{
+					Amount:       500,
+					ExchangeRate: forex.ExchangeRate{Value: "0.92"},
+					RateDate:     date.NewInUTC(2022, time.March, 31),
+					CurrencyCode: "EUR",
+				}, nil)
+
+			response, err := service.Fetch(ctx, transaction.FetchRequest{TransactionID: "*txn-id*", Currency: "EUR"})
+
+			assert.Nil(t, err)
+			assert.Equal(t, &transaction.Amount{
+				USDAmountInCents:       543,
+				ConvertedAmountInCents: 500,
+				ExchangeRate:           "0.92",
+				RateDate:               &transaction.FormattedDate{Time: date.NewInUTC(2022, time.March, 31)},
+				ValuationDate:          &transaction.FormattedDate{Time: date.NewInUTC(2022, time.May, 12)},
+				CurrencyCode:           "EUR",
+			}, response.Transaction.Amount)
+			mockForEx.AssertExpectations(t)
+		})
+		t.Run("should convert to the currency of each of several countries, reporting each failure against its country", func(t *testing.T) {
+			setUp()
+			mockRepo.On("FindByID", "*txn-id*").
+				Return(transaction.Entity{ID: "*txn-id*", TransactionDate: date.NewInUTC(2022, time.May, 12), AmountInCents: 543}, nil)
+			mockForEx.On("Convert", ctx, "*country-1*", mock.Anything, 543).
+				Return(forex.ConversionResult{
+					Amount:       1234,
+					ExchangeRate: forex.ExchangeRate{Value: "0.456"},
+					RateDate:     date.NewInUTC(2022, time.March, 31),
+				}, nil)
+			mockForEx.On("Convert", ctx, "*country-2*", mock.Anything, 543).
+				Return(forex.ConversionResult{}, &business.Error{Message: "UNKNOWN_COUNTRY", Suggestions: []string{"*country-1*"}})
+			mockForEx.On("Convert", ctx, "*country-3*", mock.Anything, 543).
+				Return(forex.ConversionResult{}, errors.New("problem"))
+
+			response, err := service.Fetch(ctx, transaction.FetchRequest{
+				TransactionID: "*txn-id*",
+				Countries:     []string{"*country-1*", "*country-2*", "*country-3*"},
+			})
+
+			assert.Nil(t, err)
+			assert.Nil(t, response.Transaction.Amount)
+			assert.Equal(t, []transaction.CountryAmount{
+				{
+					Country: "*country-1*",
+					Amount: &transaction.Amount{
+						USDAmountInCents:       543,
+						ConvertedAmountInCents: 1234,
+						ExchangeRate:           "0.456",
+						RateDate:               &transaction.FormattedDate{Time: date.NewInUTC(2022, time.March, 31)},
+						ValuationDate:          &transaction.FormattedDate{Time: date.NewInUTC(2022, time.May, 12)},
+					},
+				},
+				{
+					Country: "*country-2*",
+					Error:   &business.Error{Message: "UNKNOWN_COUNTRY", Suggestions: []string{"*country-1*"}},
+				},
+				{
+					Country: "*country-3*",
+					Error:   &business.Error{Message: "EXCHANGE_RATE_UNAVAILABLE"},
+				},
+			}, response.Transaction.Amounts)
+			mockForEx.AssertExpectations(t)
+		})
+		t.Run("should request a foreign exchange rate selected as at the transaction date", func(t *testing.T) {
 			setUp()
 			mockRepo.On("FindByID", mock.Anything).
 				Return(transaction.Entity{
 					TransactionDate: date.NewInUTC(2022, time.May, 12),
 				}, nil)
-			mockForEx.On("Convert", ctx, mock.Anything, date.NewInUTC(2021, time.November, 12), mock.Anything).
+			mockForEx.On("Convert", ctx, mock.Anything, forex.RateSelection{Date: date.NewInUTC(2022, time.May, 12)}, mock.Anything).
 				Return(forex.ConversionResult{}, nil)
 
-			service.Fetch(ctx, "*txn-id*", "*country*")
+			service.Fetch(ctx, transaction.FetchRequest{TransactionID: "*txn-id*", Country: "*country*"})
+			mockForEx.AssertExpectations(t)
+		})
+		t.Run("should request a foreign exchange rate selected by the rules supplied with the request", func(t *testing.T) {
+			setUp()
+			mockRepo.On("FindByID", mock.Anything).
+				Return(transaction.Entity{
+					TransactionDate: date.NewInUTC(2022, time.May, 12),
+				}, nil)
+			mockForEx.On("Convert", ctx, mock.Anything, forex.RateSelection{
+				Date:  date.NewInUTC(2022, time.May, 12),
+				Rules: forex.RateRules{Policy: forex.LatestOnOrBeforePolicy, WindowMonths: 3},
+			}, mock.Anything).Return(forex.ConversionResult{}, nil)
+
+			service.Fetch(ctx, transaction.FetchRequest{
+				TransactionID:    "*txn-id*",
+				Country:          "*country*",
+				RatePolicy:       stringPtr("LATEST_ON_OR_BEFORE"),
+				RateWindowMonths: stringPtr("3"),
+			})
+			mockForEx.AssertExpectations(t)
+		})
+		t.Run("should convert as at the supplied valuation date, stating it in the amount", func(t *testing.T) {
+			setUp()
+			mockRepo.On("FindByID", mock.Anything).
+				Return(transaction.Entity{TransactionDate: date.NewInUTC(2022, time.May, 12), AmountInCents: 543}, nil)
+			mockForEx.On("Convert", ctx, "*country*", forex.RateSelection{Date: date.NewInUTC(2023, time.January, 20)}, 543).
+				Return(forex.ConversionResult{
+					Amount:       1234,
+					ExchangeRate: forex.ExchangeRate{Value: "0.456"},
+					RateDate:     date.NewInUTC(2022, time.December, 31),
+				}, nil)
+
+			response, err := service.Fetch(ctx, transaction.FetchRequest{
+				TransactionID: "*txn-id*",
+				Country:       "*country*",
+				AsOf:          stringPtr("2023-01-20"),
+			})
+
+			assert.Nil(t, err)
+			assert.Equal(t, &transaction.FormattedDate{Time: date.NewInUTC(2023, time.January, 20)}, response.Transaction.Amount.ValuationDate)
+			mockForEx.AssertExpectations(t)
+		})
+	})
+
+	t.Run("voided", func(t *testing.T) {
+		voided := transaction.Entity{
+			ID:              "*txn-id*",
+			Description:     "*description*",
+			TransactionDate: date.NewInUTC(2022, time.May, 12),
+			AmountInCents:   543,
+			Version:         2,
+			Voided: &transaction.Void{
+				Reason: "*reason*",
+				At:     time.Date(2022, time.June, 1, 10, 30, 0, 0, time.UTC),
+			},
+		}
+		t.Run("should return a voided error when voided transactions are not included", func(t *testing.T) {
+			setUp()
+			mockRepo.On("FindByID", "*txn-id*").Return(voided, nil)
+
+			response, err := service.Fetch(ctx, transaction.FetchRequest{TransactionID: "*txn-id*", Country: "*country*"})
+
+			assert.Equal(t, &business.Error{Message: "TRANSACTION_VOIDED"}, err)
+			assert.Equal(t, transaction.FetchResponse{}, response)
+			mockRepo.AssertExpectations(t)
+			mockForEx.AssertExpectations(t)
+		})
+		t.Run("should return the transaction with its void details when voided transactions are included", func(t *testing.T) {
+			setUp()
+			mockRepo.On("FindByID", "*txn-id*").Return(voided, nil)
+			mockForEx.On("Convert", ctx, "*country*", mock.Anything, 543).
+				Return(forex.ConversionResult{Amount: 1234, ExchangeRate: forex.ExchangeRate{Value: "0.456"}}, nil)
+
+			response, err := service.Fetch(ctx, transaction.FetchRequest{
+				TransactionID: "*txn-id*",
+				Country:       "*country*",
+				IncludeVoided: true,
+			})
+
+			assert.Nil(t, err)
+			wantVoided := &transaction.VoidDetails{
+				Reason:   "*reason*",
+				VoidedAt: time.Date(2022, time.June, 1, 10, 30, 0, 0, time.UTC),
+			}
+			assert.Equal(t, wantVoided, response.Transaction.Voided)
+			mockRepo.AssertExpectations(t)
 			mockForEx.AssertExpectations(t)
 		})
 	})
@@ -153,7 +863,7 @@ func TestServiceFetch(t *testing.T) {
 	t.Run("failure", func(t *testing.T) {
 		t.Run("should return a validation error when the input does not satisfy the business rules", func(t *testing.T) {
 			setUp()
-			response, err := service.Fetch(ctx, "*txn-id*", "")
+			response, err := service.Fetch(ctx, transaction.FetchRequest{TransactionID: "*txn-id*", Country: ""})
 
 			expectedErr := &business.Error{
 				Fields: []business.FieldError{
@@ -175,7 +885,7 @@ func TestServiceFetch(t *testing.T) {
 			mockRepo.On("FindByID", "*txn-id*").
 				Return(transaction.Entity{}, nil)
 
-			response, err := service.Fetch(ctx, "*txn-id*", "*country*")
+			response, err := service.Fetch(ctx, transaction.FetchRequest{TransactionID: "*txn-id*", Country: "*country*"})
 
 			expectedErr := &business.Error{
 				Message: "TRANSACTION_NOT_FOUND",
@@ -186,6 +896,19 @@ func TestServiceFetch(t *testing.T) {
 			mockForEx.AssertExpectations(t)
 		})
 
+		t.Run("should return an error when there is a problem with the transaction repository", func(t *testing.T) {
+			setUp()
+			mockRepo.On("FindByID", "*txn-id*").
+				Return(transaction.Entity{}, errors.New("problem"))
+
+			response, err := service.Fetch(ctx, transaction.FetchRequest{TransactionID: "*txn-id*", Country: "*country*"})
+
+			assert.Equal(t, errors.New("problem"), err)
+			assert.Equal(t, transaction.FetchResponse{}, response)
+			mockRepo.AssertExpectations(t)
+			mockForEx.AssertExpectations(t)
+		})
+
 		t.Run("should return an error when there is a problem with the foreign exchange conversion", func(t *testing.T) {
 			setUp()
 			mockRepo.On("FindByID", "*txn-id*").
@@ -198,7 +921,7 @@ func TestServiceFetch(t *testing.T) {
 			mockForEx.On("Convert", ctx, "*country*", mock.Anything, 543).
 				Return(forex.ConversionResult{}, errors.New("problem"))
 
-			response, err := service.Fetch(ctx, "*txn-id*", "*country*")
+			response, err := service.Fetch(ctx, transaction.FetchRequest{TransactionID: "*txn-id*", Country: "*country*"})
 
 			assert.Equal(t, errors.New("problem"), err)
 			assert.Equal(t, transaction.FetchResponse{}, response)
@@ -208,11 +931,321 @@ func TestServiceFetch(t *testing.T) {
 	})
 }
 
+func TestServiceList(t *testing.T) {
+	coffee := transaction.Entity{
+		ID:              "*txn-id-1*",
+		Description:     "Coffee",
+		TransactionDate: date.NewInUTC(2023, time.March, 2),
+		AmountInCents:   450,
+		Version:         1,
+	}
+	refund := transaction.Entity{
+		ID:              "*txn-id-2*",
+		Description:     "Refund",
+		TransactionDate: date.NewInUTC(2023, time.March, 1),
+		AmountInCents:   -450,
+		Version:         1,
+	}
+	groceries := transaction.Entity{
+		ID:              "*txn-id-3*",
+		Description:     "Groceries",
+		TransactionDate: date.NewInUTC(2023, time.February, 27),
+		AmountInCents:   8000,
+		Version:         1,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		t.Run("should list the most recent transactions by default", func(t *testing.T) {
+			setUp()
+			mockRepo.On("Query", transaction.Query{
+				Sort:  transaction.Sort{Field: transaction.SortByTransactionDate, Descending: true},
+				Limit: 51,
+			}).Return([]transaction.Entity{coffee, refund}, nil)
+
+			response, err := service.List(ctx, transaction.ListRequest{})
+
+			assert.Nil(t, err)
+			assert.Equal(t, transaction.ListResponse{
+				Transactions: []transaction.Summary{
+					{
+						ID:              "*txn-id-1*",
+						Description:     "Coffee",
+						TransactionDate: &transaction.FormattedDate{Time: date.NewInUTC(2023, time.March, 2)},
+						AmountInCents:   450,
+					},
+					{
+						ID:              "*txn-id-2*",
+						Description:     "Refund",
+						TransactionDate: &transaction.FormattedDate{Time: date.NewInUTC(2023, time.March, 1)},
+						AmountInCents:   -450,
+					},
+				},
+			}, response)
+			mockRepo.AssertExpectations(t)
+		})
+		t.Run("should query the repository with the requested filters and sort", func(t *testing.T) {
+			setUp()
+			fromDate := date.NewInUTC(2023, time.January, 1)
+			toDate := date.NewInUTC(2023, time.January, 31)
+			mockRepo.On("Query", transaction.Query{
+				FromDate:         &fromDate,
+				ToDate:           &toDate,
+				MinAmountInCents: intPtr(-100),
+				MaxAmountInCents: intPtr(100),
+				Description:      "*description*",
+				IncludeVoided:    true,
+				Sort:             transaction.Sort{Field: transaction.SortByAmountInCents},
+				Limit:            11,
+			}).Return([]transaction.Entity{}, nil)
+
+			response, err := service.List(ctx, transaction.ListRequest{
+				FromDate:         stringPtr("2023-01-01"),
+				ToDate:           stringPtr("2023-01-31"),
+				MinAmountInCents: stringPtr("-100"),
+				MaxAmountInCents: stringPtr("100"),
+				Description:      stringPtr("*description*"),
+				IncludeVoided:    true,
+				Sort:             stringPtr("amountInCents"),
+				Limit:            stringPtr("10"),
+			})
+
+			assert.Nil(t, err)
+			assert.Equal(t, transaction.ListResponse{Transactions: []transaction.Summary{}}, response)
+			mockRepo.AssertExpectations(t)
+		})
+		t.Run("should return a cursor for the next page which continues after the last listed transaction", func(t *testing.T) {
+			setUp()
+			mockRepo.On("Query", mock.MatchedBy(func(query transaction.Query) bool {
+				return query.After == nil
+			})).Return([]transaction.Entity{coffee, refund, groceries}, nil)
+			mockRepo.On("Query", mock.MatchedBy(func(query transaction.Query) bool {
+				return query.After != nil
+			})).Return([]transaction.Entity{groceries}, nil)
+
+			firstPage, err := service.List(ctx, transaction.ListRequest{Limit: stringPtr("2")})
+			assert.Nil(t, err)
+			assert.Len(t, firstPage.Transactions, 2)
+			assert.NotEmpty(t, firstPage.NextCursor)
+
+			secondPage, err := service.List(ctx, transaction.ListRequest{Limit: stringPtr("2"), Cursor: &firstPage.NextCursor})
+			assert.Nil(t, err)
+			assert.Len(t, secondPage.Transactions, 1)
+			assert.Empty(t, secondPage.NextCursor)
+			wantAfter := &transaction.Position{
+				ID:              "*txn-id-2*",
+				TransactionDate: date.NewInUTC(2023, time.March, 1),
+				AmountInCents:   -450,
+			}
+			mockRepo.AssertCalled(t, "Query", transaction.Query{
+				Sort:  transaction.Sort{Field: transaction.SortByTransactionDate, Descending: true},
+				After: wantAfter,
+				Limit: 3,
+			})
+		})
+		t.Run("should convert each amount when a country is requested, explaining any that cannot be converted", func(t *testing.T) {
+			setUp()
+			mockRepo.On("Query", mock.Anything).Return([]transaction.Entity{coffee, refund}, nil)
+			mockForEx.On("Convert", ctx, "*country*", forex.RateSelection{Date: date.NewInUTC(2023, time.March, 2)}, 450).
+				Return(forex.ConversionResult{Amount: 900, ExchangeRate: forex.ExchangeRate{Value: "2"}, RateDate: date.NewInUTC(2022, time.December, 31)}, nil)
+			mockForEx.On("Convert", ctx, "*country*", forex.RateSelection{Date: date.NewInUTC(2023, time.March, 1)}, -450).
+				Return(forex.ConversionResult{}, &business.Error{Message: "UNABLE_TO_CONVERT_TO_TARGET_CURRENCY"})
+
+			response, err := service.List(ctx, transaction.ListRequest{Country: stringPtr("*country*")})
+
+			assert.Nil(t, err)
+			wantAmount := &transaction.Amount{
+				USDAmountInCents:       450,
+				ConvertedAmountInCents: 900,
+				ExchangeRate:           "2",
+				RateDate:               &transaction.FormattedDate{Time: date.NewInUTC(2022, time.December, 31)},
+				ValuationDate:          &transaction.FormattedDate{Time: date.NewInUTC(2023, time.March, 2)},
+			}
+			assert.Equal(t, wantAmount, response.Transactions[0].Amount)
+			assert.Empty(t, response.Transactions[0].ConversionError)
+			assert.Nil(t, response.Transactions[1].Amount)
+			assert.Equal(t, "UNABLE_TO_CONVERT_TO_TARGET_CURRENCY", response.Transactions[1].ConversionError)
+			mockRepo.AssertExpectations(t)
+			mockForEx.AssertExpectations(t)
+		})
+		t.Run("should convert every amount as at the supplied valuation date", func(t *testing.T) {
+			setUp()
+			mockRepo.On("Query", mock.Anything).Return([]transaction.Entity{coffee, refund}, nil)
+			asOf := forex.RateSelection{Date: date.NewInUTC(2023, time.June, 30)}
+			mockForEx.On("Convert", ctx, "*country*", asOf, 450).
+				Return(forex.ConversionResult{Amount: 900, ExchangeRate: forex.ExchangeRate{Value: "2"}, RateDate: date.NewInUTC(2023, time.June, 30)}, nil)
+			mockForEx.On("Convert", ctx, "*country*", asOf, -450).
+				Return(forex.ConversionResult{Amount: -900, ExchangeRate: forex.ExchangeRate{Value: "2"}, RateDate: date.NewInUTC(2023, time.June, 30)}, nil)
+
+			response, err := service.List(ctx, transaction.ListRequest{Country: stringPtr("*country*"), AsOf: stringPtr("2023-06-30")})
+
+			assert.Nil(t, err)
+			for _, summary := range response.Transactions {
+				assert.Equal(t, &transaction.FormattedDate{Time: date.NewInUTC(2023, time.June, 30)}, summary.Amount.ValuationDate)
+			}
+			mockForEx.AssertExpectations(t)
+		})
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		t.Run("should return a validation error when the input does not satisfy the business rules", func(t *testing.T) {
+			setUp()
+
+			response, err := service.List(ctx, transaction.ListRequest{Limit: stringPtr("0")})
+
+			expectedErr := &business.Error{
+				Fields: []business.FieldError{
+					{
+						FieldName: "limit",
+						Reason:    "MIN_VALUE",
+					},
+				},
+				Message: "VALIDATION_ERROR",
+			}
+			assert.Equal(t, expectedErr, err)
+			assert.Equal(t, transaction.ListResponse{}, response)
+			mockRepo.AssertExpectations(t)
+		})
+		t.Run("should return an error when a problem occurs querying the repository", func(t *testing.T) {
+			setUp()
+			mockRepo.On("Query", mock.Anything).Return([]transaction.Entity(nil), errors.New("*query-error*"))
+
+			response, err := service.List(ctx, transaction.ListRequest{})
+
+			assert.EqualError(t, err, "*query-error*")
+			assert.Equal(t, transaction.ListResponse{}, response)
+			mockRepo.AssertExpectations(t)
+		})
+		t.Run("should return an error when a problem other than a business error occurs converting an amount", func(t *testing.T) {
+			setUp()
+			mockRepo.On("Query", mock.Anything).Return([]transaction.Entity{coffee}, nil)
+			mockForEx.On("Convert", ctx, "*country*", mock.Anything, 450).
+				Return(forex.ConversionResult{}, errors.New("*forex-error*"))
+
+			response, err := service.List(ctx, transaction.ListRequest{Country: stringPtr("*country*")})
+
+			assert.EqualError(t, err, "*forex-error*")
+			assert.Equal(t, transaction.ListResponse{}, response)
+			mockRepo.AssertExpectations(t)
+			mockForEx.AssertExpectations(t)
+		})
+	})
+}
+
+func TestServiceExport(t *testing.T) {
+	newEntity := func(id string, amountInCents int) transaction.Entity {
+		return transaction.Entity{
+			ID:              id,
+			Description:     "*description*",
+			TransactionDate: date.NewInUTC(2023, time.March, 2),
+			AmountInCents:   amountInCents,
+			Version:         1,
+		}
+	}
+	collect := func(summaries *[]transaction.Summary) func(transaction.Summary) error {
+		return func(summary transaction.Summary) error {
+			*summaries = append(*summaries, summary)
+			return nil
+		}
+	}
+
+	t.Run("success - should write every matching transaction a page at a time, converting each amount", func(t *testing.T) {
+		setUp()
+		firstPage := make([]transaction.Entity, 100)
+		for i := range firstPage {
+			firstPage[i] = newEntity(fmt.Sprintf("*txn-id-%d*", i), 100)
+		}
+		mockRepo.On("Query", transaction.Query{
+			Description: "*description*",
+			Sort:        transaction.Sort{Field: transaction.SortByTransactionDate, Descending: true},
+			Limit:       100,
+		}).Return(firstPage, nil)
+		mockRepo.On("Query", transaction.Query{
+			Description: "*description*",
+			Sort:        transaction.Sort{Field: transaction.SortByTransactionDate, Descending: true},
+			After:       &transaction.Position{ID: "*txn-id-99*", TransactionDate: date.NewInUTC(2023, time.March, 2), AmountInCents: 100},
+			Limit:       100,
+		}).Return([]transaction.Entity{newEntity("*txn-id-100*", -100)}, nil)
+		mockForEx.On("Convert", ctx, "*country*", mock.Anything, 100).
+			Return(forex.ConversionResult{Amount: 150, ExchangeRate: forex.ExchangeRate{Value: "1.5"}, RateDate: date.NewInUTC(2023, time.March, 1)}, nil)
+		mockForEx.On("Convert", ctx, "*country*", mock.Anything, -100).
+			Return(forex.ConversionResult{}, &business.Error{Message: "UNABLE_TO_CONVERT_TO_TARGET_CURRENCY"})
+
+		var summaries []transaction.Summary
+		err := service.Export(ctx, transaction.ExportRequest{
+			Description: stringPtr("*description*"),
+			Country:     stringPtr("*country*"),
+		}, collect(&summaries))
+
+		assert.Nil(t, err)
+		assert.Len(t, summaries, 101)
+		assert.Equal(t, 150, summaries[0].Amount.ConvertedAmountInCents)
+		assert.Equal(t, "*txn-id-100*", summaries[100].ID)
+		assert.Nil(t, summaries[100].Amount)
+		assert.Equal(t, "UNABLE_TO_CONVERT_TO_TARGET_CURRENCY", summaries[100].ConversionError)
+		mockRepo.AssertExpectations(t)
+		mockForEx.AssertExpectations(t)
+	})
+
+	t.Run("success - should write a transaction whose exchange rate is unavailable, explaining why it was not converted", func(t *testing.T) {
+		setUp()
+		mockRepo.On("Query", mock.Anything).Return([]transaction.Entity{newEntity("*txn-id-1*", 100)}, nil)
+		mockForEx.On("Convert", ctx, "*country*", mock.Anything, 100).
+			Return(forex.ConversionResult{}, errors.New("*forex-error*"))
+
+		var summaries []transaction.Summary
+		err := service.Export(ctx, transaction.ExportRequest{Country: stringPtr("*country*")}, collect(&summaries))
+
+		assert.Nil(t, err)
+		assert.Len(t, summaries, 1)
+		assert.Nil(t, summaries[0].Amount)
+		assert.Equal(t, "EXCHANGE_RATE_UNAVAILABLE", summaries[0].ConversionError)
+		mockRepo.AssertExpectations(t)
+		mockForEx.AssertExpectations(t)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		t.Run("should return a validation error without writing anything when no country is supplied", func(t *testing.T) {
+			setUp()
+
+			var summaries []transaction.Summary
+			err := service.Export(ctx, transaction.ExportRequest{}, collect(&summaries))
+
+			expectedErr := &business.Error{
+				Fields: []business.FieldError{
+					{
+						FieldName: "country",
+						Reason:    "REQUIRED",
+					},
+				},
+				Message: "VALIDATION_ERROR",
+			}
+			assert.Equal(t, expectedErr, err)
+			assert.Empty(t, summaries)
+			mockRepo.AssertExpectations(t)
+		})
+		t.Run("should stop and return the error when a transaction cannot be written", func(t *testing.T) {
+			setUp()
+			mockRepo.On("Query", mock.Anything).
+				Return([]transaction.Entity{newEntity("*txn-id-1*", 100), newEntity("*txn-id-2*", 100)}, nil)
+			mockForEx.On("Convert", ctx, "*country*", mock.Anything, 100).
+				Return(forex.ConversionResult{Amount: 150, ExchangeRate: forex.ExchangeRate{Value: "1.5"}}, nil).Once()
+
+			err := service.Export(ctx, transaction.ExportRequest{Country: stringPtr("*country*")}, func(transaction.Summary) error {
+				return errors.New("*write-error*")
+			})
+
+			assert.EqualError(t, err, "*write-error*")
+			mockRepo.AssertExpectations(t)
+			mockForEx.AssertExpectations(t)
+		})
+	})
+}
+
 func setUp() {
 	ctx = context.Background()
 	mockForEx = MockForEx{}
 	mockRepo = MockRepository{}
-	service = transaction.NewRepositoryService(&mockRepo, &mockForEx)
+	service = transaction.NewRepositoryService(&mockRepo, transaction.NewInMemoryIdempotencyStore(time.Hour), &mockForEx)
 }
 
 type MockRepository struct {
@@ -224,17 +1257,56 @@ func (m *MockRepository) Save(txn transaction.Entity) (transaction.Entity, error
 	return args.Get(0).(transaction.Entity), args.Error(1)
 }
 
-func (m *MockRepository) FindByID(id string) transaction.Entity {
+func (m *MockRepository) SaveAll(txns []transaction.Entity) ([]transaction.Entity, error) {
+	args := m.Called(txns)
+	return args.Get(0).([]transaction.Entity), args.Error(1)
+}
+
+func (m *MockRepository) Update(txn transaction.Entity) (transaction.Entity, error) {
+	args := m.Called(txn)
+	return args.Get(0).(transaction.Entity), args.Error(1)
+}
+
+func (m *MockRepository) Query(query transaction.Query) ([]transaction.Entity, error) {
+	args := m.Called(query)
+	return args.Get(0).([]transaction.Entity), args.Error(1)
+}
+
+func (m *MockRepository) FindByID(id string) (transaction.Entity, error) {
 	args := m.Called(id)
-	return args.Get(0).(transaction.Entity)
+	return args.Get(0).(transaction.Entity), args.Error(1)
+}
+
+type MockTransactionalIdempotencyStore struct {
+	mock.Mock
+}
+
+func (m *MockTransactionalIdempotencyStore) Save(key string, record transaction.IdempotencyRecord) error {
+	args := m.Called(key, record)
+	return args.Error(0)
+}
+
+func (m *MockTransactionalIdempotencyStore) FindByKey(key string) (transaction.IdempotencyRecord, error) {
+	args := m.Called(key)
+	return args.Get(0).(transaction.IdempotencyRecord), args.Error(1)
+}
+
+func (m *MockTransactionalIdempotencyStore) SaveTransaction(key string, fingerprint string, txn transaction.Entity) (transaction.Entity, error) {
+	args := m.Called(key, fingerprint, txn)
+	return args.Get(0).(transaction.Entity), args.Error(1)
 }
 
 type MockForEx struct {
 	mock.Mock
 }
 
-func (m *MockForEx) Convert(ctx context.Context, country string, dateOfOldestExchangeRate time.Time, amountInCents int) (forex.ConversionResult, error) {
-	args := m.Called(ctx, country, dateOfOldestExchangeRate, amountInCents)
+func (m *MockForEx) Convert(ctx context.Context, country string, selection forex.RateSelection, amountInCents int) (forex.ConversionResult, error) {
+	args := m.Called(ctx, country, selection, amountInCents)
+	return args.Get(0).(forex.ConversionResult), args.Error(1)
+}
+
+func (m *MockForEx) ConvertToCurrency(ctx context.Context, code string, selection forex.RateSelection, amountInCents int) (forex.ConversionResult, error) {
+	args := m.Called(ctx, code, selection, amountInCents)
 	return args.Get(0).(forex.ConversionResult), args.Error(1)
 }
 
@@ -0,0 +1,88 @@
+package transaction
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// NewSQLIdempotencyStore creates a SQLIdempotencyStore that stores idempotency records in the database of the supplied
+// SQLRepository and remembers each key for the supplied window.
+func NewSQLIdempotencyStore(repository *SQLRepository, window time.Duration) *SQLIdempotencyStore {
+	return &SQLIdempotencyStore{
+		repository: repository,
+		window:     window,
+		now:        time.Now,
+	}
+}
+
+// SQLIdempotencyStore stores idempotency records in the idempotency_keys table of a relational database, beside the
+// transactions stored by a SQLRepository, so that keys are remembered across restarts and by every instance of the
+// service sharing the database.  A transaction is stored together with the record of its key in a single database
+// transaction, and a key can only be stored once, so two instances cannot both store a transaction with the same key.
+// Records whose window has passed are ignored, and are deleted as new ones are saved.
+type SQLIdempotencyStore struct {
+	repository *SQLRepository
+	window     time.Duration
+	now        func() time.Time
+}
+
+// Save stores the record under the supplied key and returns an error if one occurred.  ErrIdempotencyKeyExists is
+// returned if a record whose window has not passed is already stored with the key.
+func (s *SQLIdempotencyStore) Save(key string, record IdempotencyRecord) error {
+	if err := s.insert(s.repository.db, key, record); err != nil {
+		return s.conflict(key, err)
+	}
+	return nil
+}
+
+// SaveTransaction generates a new id for the transaction and stores it in the SQLRepository together with a record of
+// the supplied key and fingerprint, within a single database transaction, and returns it.  ErrIdempotencyKeyExists is
+// returned, and nothing is stored, if a record whose window has not passed is already stored with the key.
+func (s *SQLIdempotencyStore) SaveTransaction(key string, fingerprint string, txn Entity) (Entity, error) {
+	saved, err := s.repository.saveWith(txn, func(tx execer, saved Entity) error {
+		return s.insert(tx, key, IdempotencyRecord{Fingerprint: fingerprint, Response: StoreResponse{ID: saved.ID}})
+	})
+	if err != nil {
+		return Entity{}, s.conflict(key, err)
+	}
+	return saved, nil
+}
+
+// insert deletes every record whose window has passed, then inserts the record under the supplied key.  The insert
+// fails on the primary key of the table should a record still be stored with the key.
+func (s *SQLIdempotencyStore) insert(db execer, key string, record IdempotencyRecord) error {
+	now := s.now()
+	if _, err := db.Exec(`DELETE FROM idempotency_keys WHERE expires_at <= ?`, now.UnixNano()); err != nil {
+		return err
+	}
+	_, err := db.Exec(`INSERT INTO idempotency_keys (idempotency_key, fingerprint, transaction_id, expires_at)
+		VALUES (?, ?, ?, ?)`,
+		key, record.Fingerprint, record.Response.ID, now.Add(s.window).UnixNano())
+	return err
+}
+
+// conflict returns ErrIdempotencyKeyExists in place of the supplied error if a record whose window has not passed is
+// stored with the key, as that is what caused the insert to fail, otherwise it returns the supplied error.
+func (s *SQLIdempotencyStore) conflict(key string, err error) error {
+	if record, findErr := s.FindByKey(key); findErr == nil && record != (IdempotencyRecord{}) {
+		return ErrIdempotencyKeyExists
+	}
+	return err
+}
+
+// FindByKey fetches the record stored under the supplied key.  An empty IdempotencyRecord will be returned if nothing
+// has been stored with the key within the configured window.
+func (s *SQLIdempotencyStore) FindByKey(key string) (IdempotencyRecord, error) {
+	var record IdempotencyRecord
+	err := s.repository.db.QueryRow(`SELECT fingerprint, transaction_id FROM idempotency_keys
+		WHERE idempotency_key = ? AND expires_at > ?`, key, s.now().UnixNano()).
+		Scan(&record.Fingerprint, &record.Response.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return IdempotencyRecord{}, nil
+	}
+	if err != nil {
+		return IdempotencyRecord{}, err
+	}
+	return record, nil
+}
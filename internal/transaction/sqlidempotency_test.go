@@ -0,0 +1,121 @@
+package transaction
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	_ "modernc.org/sqlite"
+
+	"transaction-service/internal/id"
+)
+
+func TestSQLIdempotencyStore(t *testing.T) {
+	now := time.Date(2023, time.May, 1, 12, 0, 0, 0, time.UTC)
+	record := IdempotencyRecord{
+		Fingerprint: "*fingerprint*",
+		Response:    StoreResponse{ID: "*txn-id*"},
+	}
+	open := func(t *testing.T) (*SQLIdempotencyStore, *sql.DB) {
+		db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "transactions.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+		repository, err := NewSQLRepository(db, id.NewSequentialGenerator())
+		if err != nil {
+			t.Fatal(err)
+		}
+		store := NewSQLIdempotencyStore(repository, time.Hour)
+		store.now = func() time.Time { return now }
+		return store, db
+	}
+
+	t.Run("should return the record saved with the key while within the window", func(t *testing.T) {
+		store, _ := open(t)
+		assert.Nil(t, store.Save("*key*", record))
+		store.now = func() time.Time { return now.Add(59 * time.Minute) }
+
+		found, err := store.FindByKey("*key*")
+		assert.Nil(t, err)
+		assert.Equal(t, record, found)
+	})
+	t.Run("should return an empty record once the window has passed", func(t *testing.T) {
+		store, _ := open(t)
+		store.Save("*key*", record)
+		store.now = func() time.Time { return now.Add(time.Hour) }
+
+		found, err := store.FindByKey("*key*")
+		assert.Nil(t, err)
+		assert.Equal(t, IdempotencyRecord{}, found)
+	})
+	t.Run("should return an empty record when nothing has been saved with the key", func(t *testing.T) {
+		store, _ := open(t)
+		store.Save("*key*", record)
+
+		found, err := store.FindByKey("*another-key*")
+		assert.Nil(t, err)
+		assert.Equal(t, IdempotencyRecord{}, found)
+	})
+	t.Run("should not replace the record saved with the key while within the window", func(t *testing.T) {
+		store, _ := open(t)
+		store.Save("*key*", record)
+		another := IdempotencyRecord{Fingerprint: "*another-fingerprint*", Response: StoreResponse{ID: "*another-id*"}}
+
+		assert.Equal(t, ErrIdempotencyKeyExists, store.Save("*key*", another))
+		found, err := store.FindByKey("*key*")
+		assert.Nil(t, err)
+		assert.Equal(t, record, found)
+	})
+	t.Run("should save the key again once the window has passed, deleting expired records", func(t *testing.T) {
+		store, db := open(t)
+		store.Save("*key*", record)
+		store.Save("*expired-key*", record)
+		store.now = func() time.Time { return now.Add(time.Hour) }
+		another := IdempotencyRecord{Fingerprint: "*another-fingerprint*", Response: StoreResponse{ID: "*another-id*"}}
+		assert.Nil(t, store.Save("*key*", another))
+
+		found, err := store.FindByKey("*key*")
+		assert.Nil(t, err)
+		assert.Equal(t, another, found)
+		var count int
+		assert.Nil(t, db.QueryRow(`SELECT COUNT(*) FROM idempotency_keys`).Scan(&count))
+		assert.Equal(t, 1, count)
+	})
+	t.Run("should store a transaction together with the record of its key", func(t *testing.T) {
+		store, _ := open(t)
+
+		saved, err := store.SaveTransaction("*key*", "*fingerprint*", Entity{Description: "*description*"})
+		assert.Nil(t, err)
+		assert.Equal(t, Entity{ID: "sequentialID-1", Description: "*description*", Version: 1}, saved)
+		found, err := store.FindByKey("*key*")
+		assert.Nil(t, err)
+		assert.Equal(t, IdempotencyRecord{Fingerprint: "*fingerprint*", Response: StoreResponse{ID: "sequentialID-1"}}, found)
+		stored, err := store.repository.FindByID("sequentialID-1")
+		assert.Nil(t, err)
+		assert.Equal(t, saved, stored)
+	})
+	t.Run("should store neither the transaction nor the key when the key has already been saved", func(t *testing.T) {
+		store, db := open(t)
+		store.Save("*key*", record)
+
+		_, err := store.SaveTransaction("*key*", "*another-fingerprint*", Entity{Description: "*description*"})
+		assert.Equal(t, ErrIdempotencyKeyExists, err)
+		found, err := store.FindByKey("*key*")
+		assert.Nil(t, err)
+		assert.Equal(t, record, found)
+		var count int
+		assert.Nil(t, db.QueryRow(`SELECT COUNT(*) FROM transactions`).Scan(&count))
+		assert.Equal(t, 0, count)
+	})
+	t.Run("should return an error when the database is unavailable", func(t *testing.T) {
+		store, db := open(t)
+		db.Close()
+
+		_, err := store.FindByKey("*key*")
+		assert.NotNil(t, err)
+		assert.NotNil(t, store.Save("*key*", record))
+	})
+}
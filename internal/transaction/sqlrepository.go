@@ -0,0 +1,399 @@
+package transaction
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"transaction-service/internal/validation"
+)
+
+// migration is a versioned change to the database schema.  Migrations are applied in order of version and a migration
+// must never be changed once released; further changes to the schema are made by appending a new migration.
+type migration struct {
+	version    int
+	statements []string
+}
+
+// migrations holds every change made to the database schema.
+var migrations = []migration{
+	{
+		version: 1,
+		statements: []string{
+			`CREATE TABLE transactions (
+				id               TEXT    NOT NULL PRIMARY KEY,
+				description      TEXT    NOT NULL,
+				transaction_date TEXT    NOT NULL,
+				amount_in_cents  INTEGER NOT NULL
+			)`,
+		},
+	},
+	{
+		version: 2,
+		statements: []string{
+			`ALTER TABLE transactions ADD COLUMN version INTEGER NOT NULL DEFAULT 1`,
+		},
+	},
+	{
+		version: 3,
+		statements: []string{
+			`ALTER TABLE transactions ADD COLUMN void_reason TEXT`,
+			`ALTER TABLE transactions ADD COLUMN voided_at TEXT`,
+		},
+	},
+	{
+		version: 4,
+		statements: []string{
+			`CREATE INDEX transactions_by_transaction_date ON transactions (transaction_date, id)`,
+			`CREATE INDEX transactions_by_amount_in_cents ON transactions (amount_in_cents, id)`,
+		},
+	},
+	{
+		version: 5,
+		statements: []string{
+			`CREATE TABLE idempotency_keys (
+				idempotency_key TEXT    NOT NULL PRIMARY KEY,
+				fingerprint     TEXT    NOT NULL,
+				transaction_id  TEXT    NOT NULL,
+				expires_at      INTEGER NOT NULL
+			)`,
+			`CREATE INDEX idempotency_keys_by_expires_at ON idempotency_keys (expires_at)`,
+		},
+	},
+}
+
+// sortColumns maps each SortField to the column holding it.
+var sortColumns = map[SortField]string{
+	SortByTransactionDate: "transaction_date",
+	SortByAmountInCents:   "amount_in_cents",
+	SortByID:              "id",
+}
+
+// selectTransaction is the query used to read transactions, for scanning with scanTransaction.
+const selectTransaction = `SELECT id, description, transaction_date, amount_in_cents, version, void_reason, voided_at
+	FROM transactions`
+
+// NewSQLRepository creates a SQLRepository that stores transactions in the supplied database, generating ids with the
+// supplied id generator.  Any migrations that have not yet been applied to the database are applied before it
+// returns.
+func NewSQLRepository(db *sql.DB, idGenerator IDGenerator) (*SQLRepository, error) {
+	r := &SQLRepository{
+		db:          db,
+		idGenerator: idGenerator,
+	}
+	if err := migrate(db); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// SQLRepository stores transactions in a relational database through database/sql.  The queries it issues use '?'
+// placeholders, as understood by drivers such as SQLite and MySQL.
+type SQLRepository struct {
+	db          *sql.DB
+	idGenerator IDGenerator
+}
+
+// Save generates a new id for the transaction, inserts it into the database and returns it, or returns an error if one
+// occurred.
+func (r *SQLRepository) Save(txn Entity) (Entity, error) {
+	id, err := r.idGenerator.NewID()
+	if err != nil {
+		return Entity{}, err
+	}
+	txn.ID = id
+	txn.Version = initialVersion
+	if err := insert(r.db, txn); err != nil {
+		return Entity{}, err
+	}
+	return txn, nil
+}
+
+// saveWith generates a new id for the transaction and inserts it into the database, then passes it to the supplied
+// function to write whatever is stored alongside it, all within a single database transaction.  It returns the
+// transaction, or returns an error if one occurred, in which case nothing is stored.
+func (r *SQLRepository) saveWith(txn Entity, write func(tx execer, saved Entity) error) (Entity, error) {
+	id, err := r.idGenerator.NewID()
+	if err != nil {
+		return Entity{}, err
+	}
+	txn.ID = id
+	txn.Version = initialVersion
+	tx, err := r.db.Begin()
+	if err != nil {
+		return Entity{}, err
+	}
+	defer tx.Rollback()
+	if err := insert(tx, txn); err != nil {
+		return Entity{}, err
+	}
+	if err := write(tx, txn); err != nil {
+		return Entity{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return Entity{}, err
+	}
+	return txn, nil
+}
+
+// SaveAll generates a new id for each of the transactions, inserts them into the database and returns them, or
+// returns an error if one occurred.  The transactions are inserted within a single database transaction, so either all
+// of them are stored or, should an error occur, none of them are.
+func (r *SQLRepository) SaveAll(txns []Entity) ([]Entity, error) {
+	saved, err := withNewIDs(r.idGenerator, txns)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	for _, txn := range saved {
+		if err := insert(tx, txn); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+// execer is implemented by both sql.DB and sql.Tx.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// insert inserts the supplied transaction into the database.
+func insert(db execer, txn Entity) error {
+	voidReason, voidedAt := voidColumns(txn.Voided)
+	_, err := db.Exec(
+		`INSERT INTO transactions (id, description, transaction_date, amount_in_cents, version, void_reason, voided_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		txn.ID, txn.Description, txn.TransactionDate.Format(validation.DateFormat), txn.AmountInCents, txn.Version,
+		voidReason, voidedAt)
+	return err
+}
+
+// Update changes the stored transaction having the same id as the supplied transaction, provided the stored version
+// matches the supplied version, and returns it with its version incremented.  The version is checked by the update
+// statement itself, so concurrent updates cannot both succeed.  ErrNotFound is returned if no such transaction is
+// stored and ErrVersionConflict is returned if the versions do not match.
+func (r *SQLRepository) Update(txn Entity) (Entity, error) {
+	voidReason, voidedAt := voidColumns(txn.Voided)
+	result, err := r.db.Exec(
+		`UPDATE transactions SET description = ?, transaction_date = ?, amount_in_cents = ?, void_reason = ?,
+		voided_at = ?, version = version + 1
+		WHERE id = ? AND version = ?`,
+		txn.Description, txn.TransactionDate.Format(validation.DateFormat), txn.AmountInCents, voidReason, voidedAt,
+		txn.ID, txn.Version)
+	if err != nil {
+		return Entity{}, err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return Entity{}, err
+	}
+	if updated == 0 {
+		current, err := r.FindByID(txn.ID)
+		if err != nil {
+			return Entity{}, err
+		}
+		if current == (Entity{}) {
+			return Entity{}, ErrNotFound
+		}
+		return Entity{}, ErrVersionConflict
+	}
+	txn.Version++
+	return txn, nil
+}
+
+// FindByID fetches the transaction with the provided id from the database.  An empty Entity will be returned if a
+// transaction with the supplied id is not found.
+func (r *SQLRepository) FindByID(id string) (Entity, error) {
+	txn, err := scanTransaction(r.db.QueryRow(selectTransaction+` WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Entity{}, nil
+	}
+	if err != nil {
+		return Entity{}, err
+	}
+	return txn, nil
+}
+
+// Query lists the transactions satisfying the supplied Query, in the order it specifies.  Pages are found by comparing
+// against the position of the previous page (keyset pagination) rather than by offset, so the indexes on the sort
+// columns are used and later pages cost no more than earlier ones.
+func (r *SQLRepository) Query(query Query) ([]Entity, error) {
+	var conditions []string
+	var args []any
+	if query.FromDate != nil {
+		conditions = append(conditions, `transaction_date >= ?`)
+		args = append(args, query.FromDate.Format(validation.DateFormat))
+	}
+	if query.ToDate != nil {
+		conditions = append(conditions, `transaction_date <= ?`)
+		args = append(args, query.ToDate.Format(validation.DateFormat))
+	}
+	if query.MinAmountInCents != nil {
+		conditions = append(conditions, `amount_in_cents >= ?`)
+		args = append(args, *query.MinAmountInCents)
+	}
+	if query.MaxAmountInCents != nil {
+		conditions = append(conditions, `amount_in_cents <= ?`)
+		args = append(args, *query.MaxAmountInCents)
+	}
+	if query.Description != "" {
+		conditions = append(conditions, `LOWER(description) LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(strings.ToLower(query.Description))+"%")
+	}
+	if !query.IncludeVoided {
+		conditions = append(conditions, `voided_at IS NULL`)
+	}
+	column := sortColumns[query.Sort.field()]
+	comparison, direction := ">", "ASC"
+	if query.Sort.Descending {
+		comparison, direction = "<", "DESC"
+	}
+	if query.After != nil {
+		if query.Sort.field() == SortByID {
+			conditions = append(conditions, fmt.Sprintf(`id %s ?`, comparison))
+			args = append(args, query.After.ID)
+		} else {
+			value := sortValue(query.Sort.field(), *query.After)
+			conditions = append(conditions, fmt.Sprintf(`(%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?))`, column, comparison))
+			args = append(args, value, value, query.After.ID)
+		}
+	}
+	statement := selectTransaction
+	if len(conditions) > 0 {
+		statement += ` WHERE ` + strings.Join(conditions, ` AND `)
+	}
+	if query.Sort.field() == SortByID {
+		statement += fmt.Sprintf(` ORDER BY id %s`, direction)
+	} else {
+		statement += fmt.Sprintf(` ORDER BY %[1]s %[2]s, id %[2]s`, column, direction)
+	}
+	if query.Limit > 0 {
+		statement += ` LIMIT ?`
+		args = append(args, query.Limit)
+	}
+	rows, err := r.db.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var txns []Entity
+	for rows.Next() {
+		txn, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		txns = append(txns, txn)
+	}
+	return txns, rows.Err()
+}
+
+// sortValue returns the value held in the column of the supplied field at the supplied Position.
+func sortValue(field SortField, position Position) any {
+	switch field {
+	case SortByAmountInCents:
+		return position.AmountInCents
+	default:
+		return position.TransactionDate.Format(validation.DateFormat)
+	}
+}
+
+// escapeLike escapes the characters having special meaning in a LIKE pattern, using '\' as the escape character.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+// scanner is implemented by both sql.Row and sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanTransaction reads a transaction from the columns returned by selectTransaction.
+func scanTransaction(row scanner) (Entity, error) {
+	var txn Entity
+	var txnDate string
+	var voidReason, voidedAt sql.NullString
+	err := row.Scan(&txn.ID, &txn.Description, &txnDate, &txn.AmountInCents, &txn.Version, &voidReason, &voidedAt)
+	if err != nil {
+		return Entity{}, err
+	}
+	if txn.TransactionDate, err = time.Parse(validation.DateFormat, txnDate); err != nil {
+		return Entity{}, err
+	}
+	if voidedAt.Valid {
+		at, err := time.Parse(time.RFC3339Nano, voidedAt.String)
+		if err != nil {
+			return Entity{}, err
+		}
+		txn.Voided = &Void{
+			Reason: voidReason.String,
+			At:     at,
+		}
+	}
+	return txn, nil
+}
+
+// voidColumns returns the values of the void_reason and voided_at columns representing the supplied Void, which are
+// null if the transaction has not been voided.
+func voidColumns(void *Void) (sql.NullString, sql.NullString) {
+	if void == nil {
+		return sql.NullString{}, sql.NullString{}
+	}
+	return sql.NullString{String: void.Reason, Valid: true},
+		sql.NullString{String: void.At.UTC().Format(time.RFC3339Nano), Valid: true}
+}
+
+// migrate applies, in order, each migration that has not yet been applied to the database.  Each migration is applied
+// in its own database transaction along with the record of its version, so a failed migration leaves the schema at
+// the previous version.
+func migrate(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER NOT NULL PRIMARY KEY,
+		applied_at TEXT    NOT NULL
+	)`)
+	if err != nil {
+		return err
+	}
+	var current int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := apply(db, m); err != nil {
+			return fmt.Errorf("applying migration %d: %w", m.version, err)
+		}
+	}
+	return nil
+}
+
+// apply runs the statements of a single migration and records its version.
+func apply(db *sql.DB, m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, statement := range m.statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+	_, err = tx.Exec(`INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`,
+		m.version, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,174 @@
+package transaction_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	_ "modernc.org/sqlite"
+
+	"transaction-service/internal/date"
+	"transaction-service/internal/id"
+	"transaction-service/internal/transaction"
+)
+
+func TestSQLRepository(t *testing.T) {
+	t.Run("store", func(t *testing.T) {
+		t.Run("success - should return entity with new id", func(t *testing.T) {
+			repo := openSQLRepository(t, openDatabase(t))
+
+			tcs := []struct {
+				name       string
+				entity     transaction.Entity
+				wantEntity transaction.Entity
+			}{
+				{
+					name:   "empty entity",
+					entity: transaction.Entity{},
+					wantEntity: transaction.Entity{
+						ID:      "sequentialID-1",
+						Version: 1,
+					},
+				},
+				{
+					name: "complete entity (less ID)",
+					entity: transaction.Entity{
+						Description:     "*description*",
+						TransactionDate: date.NewInUTC(2023, time.January, 23),
+						AmountInCents:   5432,
+					},
+					wantEntity: transaction.Entity{
+						ID:              "sequentialID-2",
+						Description:     "*description*",
+						TransactionDate: date.NewInUTC(2023, time.January, 23),
+						AmountInCents:   5432,
+						Version:         1,
+					},
+				},
+			}
+			for _, tc := range tcs {
+				t.Run(tc.name, func(t *testing.T) {
+					entity, err := repo.Save(tc.entity)
+					assert.Nil(t, err)
+					assert.Equal(t, tc.wantEntity, entity)
+				})
+			}
+		})
+		t.Run("failure - should return empty transaction and the error details", func(t *testing.T) {
+			repo, err := transaction.NewSQLRepository(openDatabase(t), &alwaysErrorIDGenerator{})
+			assert.Nil(t, err)
+
+			entity, err := repo.Save(transaction.Entity{Description: "*description*"})
+			assert.EqualError(t, err, "problem")
+			assert.Equal(t, transaction.Entity{}, entity)
+		})
+	})
+
+	t.Run("find by id", func(t *testing.T) {
+		t.Run("should return previously stored entity with id supplied", func(t *testing.T) {
+			repo := openSQLRepository(t, openDatabase(t))
+			repo.Save(transaction.Entity{
+				Description:     "*description*",
+				TransactionDate: date.NewInUTC(2023, time.January, 23),
+				AmountInCents:   -5432,
+			})
+
+			entity, err := repo.FindByID("sequentialID-1")
+			assert.Nil(t, err)
+			wantEntity := transaction.Entity{
+				ID:              "sequentialID-1",
+				Description:     "*description*",
+				TransactionDate: date.NewInUTC(2023, time.January, 23),
+				AmountInCents:   -5432,
+				Version:         1,
+			}
+			assert.Equal(t, wantEntity, entity)
+		})
+		t.Run("should return empty entity when nothing has been stored with the provided id", func(t *testing.T) {
+			repo := openSQLRepository(t, openDatabase(t))
+
+			entity, err := repo.FindByID("sequentialID-1")
+			assert.Nil(t, err)
+			assert.Equal(t, transaction.Entity{}, entity)
+		})
+		t.Run("should return an error when the database is unavailable", func(t *testing.T) {
+			db := openDatabase(t)
+			repo := openSQLRepository(t, db)
+			db.Close()
+
+			entity, err := repo.FindByID("sequentialID-1")
+			assert.NotNil(t, err)
+			assert.Equal(t, transaction.Entity{}, entity)
+		})
+	})
+
+	t.Run("update", func(t *testing.T) {
+		testUpdate(t, func(t *testing.T) transaction.Repository {
+			return openSQLRepository(t, openDatabase(t))
+		})
+	})
+
+	t.Run("store all", func(t *testing.T) {
+		testSaveAll(t, func(t *testing.T) transaction.Repository {
+			return openSQLRepository(t, openDatabase(t))
+		})
+		t.Run("failure - should store nothing when any insert fails", func(t *testing.T) {
+			db := openDatabase(t)
+			repo, err := transaction.NewSQLRepository(db, constantIDGenerator("*txn-id*"))
+			assert.Nil(t, err)
+
+			saved, err := repo.SaveAll([]transaction.Entity{{Description: "one"}, {Description: "two"}})
+			assert.NotNil(t, err)
+			assert.Nil(t, saved)
+			var count int
+			assert.Nil(t, db.QueryRow(`SELECT COUNT(*) FROM transactions`).Scan(&count))
+			assert.Zero(t, count)
+		})
+	})
+
+	t.Run("query", func(t *testing.T) {
+		testQuery(t, func(t *testing.T) transaction.Repository {
+			return openSQLRepository(t, openDatabase(t))
+		})
+	})
+
+	t.Run("migrations", func(t *testing.T) {
+		t.Run("should record each applied migration and not re-apply them when reopened", func(t *testing.T) {
+			db := openDatabase(t)
+			repo := openSQLRepository(t, db)
+			repo.Save(transaction.Entity{Description: "*description*"})
+
+			reopened := openSQLRepository(t, db)
+			assert.Equal(t, "*description*", findByID(t, reopened, "sequentialID-1").Description)
+			var applied int
+			assert.Nil(t, db.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&applied))
+			assert.Equal(t, 5, applied)
+		})
+	})
+}
+
+func openDatabase(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "transactions.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func openSQLRepository(t *testing.T, db *sql.DB) *transaction.SQLRepository {
+	repo, err := transaction.NewSQLRepository(db, id.NewSequentialGenerator())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+// constantIDGenerator generates the same id every time, so that storing more than one transaction fails.
+type constantIDGenerator string
+
+func (g constantIDGenerator) NewID() (string, error) {
+	return string(g), nil
+}
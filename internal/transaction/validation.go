@@ -2,6 +2,7 @@ package transaction
 
 import (
 	"transaction-service/internal/business"
+	"transaction-service/internal/forex"
 	"transaction-service/internal/validation"
 )
 
@@ -10,6 +11,10 @@ const (
 
 	countryFieldName = "country"
 	countryMinLength = 2
+	maxCountries     = 10
+
+	currencyFieldName                    = "currency"
+	conflictsWithCountry business.Reason = "CONFLICTS_WITH_COUNTRY"
 
 	descriptionFieldName = "description"
 	descriptionMinLength = 1
@@ -18,6 +23,30 @@ const (
 	transactionDateFieldName = "transactionDate"
 
 	amountInCentsFieldName = "amountInCents"
+
+	fromDateFieldName         = "fromDate"
+	toDateFieldName           = "toDate"
+	minAmountInCentsFieldName = "minAmountInCents"
+	maxAmountInCentsFieldName = "maxAmountInCents"
+	sortFieldName             = "sort"
+	limitFieldName            = "limit"
+	minLimit                  = 1
+	maxLimit                  = 200
+	cursorFieldName           = "cursor"
+
+	invalidCursor business.Reason = "INVALID_CURSOR"
+
+	transactionsFieldName = "transactions"
+	minBatchSize          = 1
+	maxBatchSize          = 1000
+
+	asOfFieldName             = "asOf"
+	ratePolicyFieldName       = "ratePolicy"
+	rateWindowMonthsFieldName = "rateWindowMonths"
+
+	reasonFieldName = "reason"
+	reasonMinLength = 1
+	reasonMaxLength = 255
 )
 
 // storeValidator is responsible for validating input of the 'store transaction' operation.
@@ -84,16 +113,164 @@ func checkForErrors(fieldErrors []business.FieldError) error {
 	return nil
 }
 
+// batchValidator is responsible for validating the size of the batch in the 'store transactions in batch' operation.
+// Each transaction in the batch is validated by the storeValidator.
+type batchValidator struct{}
+
+// validate performs business validation on the supplied BatchRequest.
+func (v batchValidator) validate(request BatchRequest) error {
+	var fieldErrors []business.FieldError
+	if len(request.Transactions) < minBatchSize {
+		fieldErrors = append(fieldErrors, *business.NewFieldError(transactionsFieldName, validation.MinLength))
+	}
+	if len(request.Transactions) > maxBatchSize {
+		fieldErrors = append(fieldErrors, *business.NewFieldError(transactionsFieldName, validation.MaxLength))
+	}
+	return checkForErrors(fieldErrors)
+}
+
 // fetchValidator is responsible for validating input of the 'fetch transaction' operation.
 type fetchValidator struct{}
 
-// validate performs business validation on the supplied country field value.
-func (v *fetchValidator) validate(country string) error {
+// validate performs business validation on the supplied FetchRequest.  Either a country, up to maxCountries countries
+// or a supported currency code may be supplied, but not a currency code along with countries.
+func (v *fetchValidator) validate(request FetchRequest) error {
+	fieldErrors := valuationErrors(request.AsOf, request.RatePolicy, request.RateWindowMonths)
+	if request.Currency != "" {
+		if request.Country != "" || len(request.Countries) > 0 {
+			fieldErrors = append(fieldErrors, *business.NewFieldError(currencyFieldName, conflictsWithCountry))
+		}
+		if err := validation.IsOneOf(currencyFieldName, &request.Currency, forex.CurrencyCodes()); err != nil {
+			fieldErrors = append(fieldErrors, *err)
+		}
+		return checkForErrors(fieldErrors)
+	}
+	if len(request.Countries) > 0 {
+		if len(request.Countries) > maxCountries {
+			fieldErrors = append(fieldErrors, *business.NewFieldError(countryFieldName, validation.MaxLength))
+		}
+		for i := range request.Countries {
+			if err := validation.IsMinLength(countryFieldName, &request.Countries[i], countryMinLength); err != nil {
+				fieldErrors = append(fieldErrors, *err)
+				break
+			}
+		}
+		return checkForErrors(fieldErrors)
+	}
+	if err := validation.IsRequiredString(countryFieldName, &request.Country); err != nil {
+		fieldErrors = append(fieldErrors, *err)
+	}
+	if err := validation.IsMinLength(countryFieldName, &request.Country, countryMinLength); err != nil {
+		fieldErrors = append(fieldErrors, *err)
+	}
+	return checkForErrors(fieldErrors)
+}
+
+// voidValidator is responsible for validating input of the 'void transaction' operation.
+type voidValidator struct{}
+
+// validate performs business validation on the supplied VoidRequest.
+func (v voidValidator) validate(request VoidRequest) error {
 	var fieldErrors []business.FieldError
-	if err := validation.IsRequiredString(countryFieldName, &country); err != nil {
+	if err := validation.IsRequiredString(reasonFieldName, request.Reason); err != nil {
+		fieldErrors = append(fieldErrors, *err)
+	}
+	if err := validation.IsMinLength(reasonFieldName, request.Reason, reasonMinLength); err != nil {
+		fieldErrors = append(fieldErrors, *err)
+	}
+	if err := validation.IsMaxLength(reasonFieldName, request.Reason, reasonMaxLength); err != nil {
 		fieldErrors = append(fieldErrors, *err)
 	}
-	if err := validation.IsMinLength(countryFieldName, &country, countryMinLength); err != nil {
+	return checkForErrors(fieldErrors)
+}
+
+// listValidator is responsible for validating input of the 'list transactions' operation.
+type listValidator struct{}
+
+// validate performs business validation on the supplied ListRequest.
+func (v listValidator) validate(request ListRequest) error {
+	return checkForErrors(v.fieldErrors(request))
+}
+
+// fieldErrors returns the errors of each invalid field of the supplied ListRequest.  The cursor is only checked once
+// the sort it must match is known to be valid.
+func (v listValidator) fieldErrors(request ListRequest) []business.FieldError {
+	var fieldErrors []business.FieldError
+	if _, err := validation.IsDate(fromDateFieldName, request.FromDate); err != nil {
+		fieldErrors = append(fieldErrors, *err)
+	}
+	if _, err := validation.IsDate(toDateFieldName, request.ToDate); err != nil {
+		fieldErrors = append(fieldErrors, *err)
+	}
+	if _, err := validation.IsInt(minAmountInCentsFieldName, request.MinAmountInCents); err != nil {
+		fieldErrors = append(fieldErrors, *err)
+	}
+	if _, err := validation.IsInt(maxAmountInCentsFieldName, request.MaxAmountInCents); err != nil {
+		fieldErrors = append(fieldErrors, *err)
+	}
+	if err := validation.IsMaxLength(descriptionFieldName, request.Description, descriptionMaxLength); err != nil {
+		fieldErrors = append(fieldErrors, *err)
+	}
+	sortErr := validation.IsOneOf(sortFieldName, request.Sort, supportedSorts())
+	if sortErr != nil {
+		fieldErrors = append(fieldErrors, *sortErr)
+	}
+	if limit, err := validation.IsInt(limitFieldName, request.Limit); err != nil {
+		fieldErrors = append(fieldErrors, *err)
+	} else if request.Limit != nil {
+		if err := validation.IsMinValue(limitFieldName, &limit, minLimit); err != nil {
+			fieldErrors = append(fieldErrors, *err)
+		}
+		if err := validation.IsMaxValue(limitFieldName, &limit, maxLimit); err != nil {
+			fieldErrors = append(fieldErrors, *err)
+		}
+	}
+	if request.Cursor != nil && sortErr == nil {
+		if _, err := decodeCursor(mapSort(request.Sort), *request.Cursor); err != nil {
+			fieldErrors = append(fieldErrors, *business.NewFieldError(cursorFieldName, invalidCursor))
+		}
+	}
+	if err := validation.IsMinLength(countryFieldName, request.Country, countryMinLength); err != nil {
+		fieldErrors = append(fieldErrors, *err)
+	}
+	return append(fieldErrors, valuationErrors(request.AsOf, request.RatePolicy, request.RateWindowMonths)...)
+}
+
+// valuationErrors returns the errors of the supplied valuation date and overrides of the rules by which exchange rates
+// are selected, each of which is optional.  The valuation date may not be in the future.
+func valuationErrors(asOf, policy, windowMonths *string) []business.FieldError {
+	var fieldErrors []business.FieldError
+	if date, err := validation.IsDate(asOfFieldName, asOf); err != nil {
+		fieldErrors = append(fieldErrors, *err)
+	} else if err := validation.IsDateNowOrEarlier(asOfFieldName, date); err != nil {
+		fieldErrors = append(fieldErrors, *err)
+	}
+	if err := validation.IsOneOf(ratePolicyFieldName, policy, forex.RatePolicies()); err != nil {
+		fieldErrors = append(fieldErrors, *err)
+	}
+	if months, err := validation.IsInt(rateWindowMonthsFieldName, windowMonths); err != nil {
+		fieldErrors = append(fieldErrors, *err)
+	} else if windowMonths != nil {
+		if err := validation.IsMinValue(rateWindowMonthsFieldName, &months, forex.MinRateWindowMonths); err != nil {
+			fieldErrors = append(fieldErrors, *err)
+		}
+		if err := validation.IsMaxValue(rateWindowMonthsFieldName, &months, forex.MaxRateWindowMonths); err != nil {
+			fieldErrors = append(fieldErrors, *err)
+		}
+	}
+	return fieldErrors
+}
+
+// exportValidator is responsible for validating input of the 'export transactions' operation.
+type exportValidator struct {
+	listValidator listValidator
+}
+
+// validate performs business validation on the supplied ExportRequest.  The country is required, since the purpose of
+// an export is to convert the transactions.
+func (v exportValidator) validate(request ExportRequest) error {
+	fieldErrors := v.listValidator.fieldErrors(request.listRequest())
+	if err := validation.IsRequiredString(countryFieldName, request.Country); err != nil {
 		fieldErrors = append(fieldErrors, *err)
 	}
 	return checkForErrors(fieldErrors)
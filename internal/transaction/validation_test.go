@@ -256,7 +256,7 @@ func TestFetchValidation(t *testing.T) {
 		}
 		for _, tc := range tcs {
 			t.Run(tc.name, func(t *testing.T) {
-				err := validator.validate("*country*")
+				err := validator.validate(FetchRequest{Country: "*country*"})
 				assert.Nil(t, err)
 			})
 		}
@@ -283,7 +283,7 @@ func TestFetchValidation(t *testing.T) {
 		}
 		for _, tc := range tcs {
 			t.Run(tc.name, func(t *testing.T) {
-				err := validator.validate("a")
+				err := validator.validate(FetchRequest{Country: "a"})
 				wantErr := &business.Error{
 					Message: "VALIDATION_ERROR",
 					Fields: []business.FieldError{
@@ -299,6 +299,319 @@ func TestFetchValidation(t *testing.T) {
 	})
 }
 
+func TestFetchValidationOfCurrency(t *testing.T) {
+	validator := fetchValidator{}
+	tcs := []struct {
+		name    string
+		request FetchRequest
+		wantErr error
+	}{
+		{
+			name:    "should accept a supported currency code",
+			request: FetchRequest{Currency: "EUR"},
+			wantErr: nil,
+		},
+		{
+			name:    "should reject an unsupported currency code",
+			request: FetchRequest{Currency: "eur"},
+			wantErr: &business.Error{
+				Message: "VALIDATION_ERROR",
+				Fields:  []business.FieldError{{FieldName: "currency", Reason: "UNSUPPORTED_VALUE"}},
+			},
+		},
+		{
+			name:    "should reject a currency code supplied along with a country",
+			request: FetchRequest{Country: "United Kingdom", Currency: "GBP"},
+			wantErr: &business.Error{
+				Message: "VALIDATION_ERROR",
+				Fields:  []business.FieldError{{FieldName: "currency", Reason: "CONFLICTS_WITH_COUNTRY"}},
+			},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantErr, validator.validate(tc.request))
+		})
+	}
+}
+
+func TestFetchValidationOfCountries(t *testing.T) {
+	validator := fetchValidator{}
+	tcs := []struct {
+		name    string
+		request FetchRequest
+		wantErr error
+	}{
+		{
+			name:    "should accept several countries",
+			request: FetchRequest{Countries: []string{"United Kingdom", "Australia"}},
+			wantErr: nil,
+		},
+		{
+			name:    "should reject a country that is too short",
+			request: FetchRequest{Countries: []string{"United Kingdom", "a", "b"}},
+			wantErr: &business.Error{
+				Message: "VALIDATION_ERROR",
+				Fields:  []business.FieldError{{FieldName: "country", Reason: "MIN_LENGTH"}},
+			},
+		},
+		{
+			name:    "should reject too many countries",
+			request: FetchRequest{Countries: []string{"c1", "c2", "c3", "c4", "c5", "c6", "c7", "c8", "c9", "c10", "c11"}},
+			wantErr: &business.Error{
+				Message: "VALIDATION_ERROR",
+				Fields:  []business.FieldError{{FieldName: "country", Reason: "MAX_LENGTH"}},
+			},
+		},
+		{
+			name:    "should reject a currency code supplied along with several countries",
+			request: FetchRequest{Countries: []string{"United Kingdom", "Australia"}, Currency: "GBP"},
+			wantErr: &business.Error{
+				Message: "VALIDATION_ERROR",
+				Fields:  []business.FieldError{{FieldName: "currency", Reason: "CONFLICTS_WITH_COUNTRY"}},
+			},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantErr, validator.validate(tc.request))
+		})
+	}
+}
+
+func TestFetchValidationOfRateRules(t *testing.T) {
+	validator := fetchValidator{}
+	tcs := []struct {
+		name    string
+		request FetchRequest
+		wantErr error
+	}{
+		{
+			name:    "should accept a supported policy and window",
+			request: FetchRequest{Country: "United Kingdom", RatePolicy: stringPtr("NEAREST"), RateWindowMonths: stringPtr("3")},
+			wantErr: nil,
+		},
+		{
+			name:    "should reject an unsupported policy",
+			request: FetchRequest{Country: "United Kingdom", RatePolicy: stringPtr("nearest")},
+			wantErr: &business.Error{
+				Message: "VALIDATION_ERROR",
+				Fields:  []business.FieldError{{FieldName: "ratePolicy", Reason: "UNSUPPORTED_VALUE"}},
+			},
+		},
+		{
+			name:    "should reject a window that is not a whole number of months",
+			request: FetchRequest{Country: "United Kingdom", RateWindowMonths: stringPtr("1.5")},
+			wantErr: &business.Error{
+				Message: "VALIDATION_ERROR",
+				Fields:  []business.FieldError{{FieldName: "rateWindowMonths", Reason: "INTEGER_BAD_FORMAT"}},
+			},
+		},
+		{
+			name:    "should reject a window that is too short",
+			request: FetchRequest{Country: "United Kingdom", RateWindowMonths: stringPtr("0")},
+			wantErr: &business.Error{
+				Message: "VALIDATION_ERROR",
+				Fields:  []business.FieldError{{FieldName: "rateWindowMonths", Reason: "MIN_VALUE"}},
+			},
+		},
+		{
+			name:    "should reject a window that is too long",
+			request: FetchRequest{Country: "United Kingdom", RateWindowMonths: stringPtr("25")},
+			wantErr: &business.Error{
+				Message: "VALIDATION_ERROR",
+				Fields:  []business.FieldError{{FieldName: "rateWindowMonths", Reason: "MAX_VALUE"}},
+			},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantErr, validator.validate(tc.request))
+		})
+	}
+}
+
+func TestFetchValidationOfValuationDate(t *testing.T) {
+	validator := fetchValidator{}
+	tcs := []struct {
+		name    string
+		asOf    string
+		wantErr error
+	}{
+		{
+			name:    "should accept a valuation date in the past",
+			asOf:    "2023-01-20",
+			wantErr: nil,
+		},
+		{
+			name: "should reject a badly formatted valuation date",
+			asOf: "20/01/2023",
+			wantErr: &business.Error{
+				Message: "VALIDATION_ERROR",
+				Fields:  []business.FieldError{{FieldName: "asOf", Reason: "DATE_BAD_FORMAT"}},
+			},
+		},
+		{
+			name: "should reject a valuation date in the future",
+			asOf: time.Now().AddDate(0, 0, 1).Format("2006-01-02"),
+			wantErr: &business.Error{
+				Message: "VALIDATION_ERROR",
+				Fields:  []business.FieldError{{FieldName: "asOf", Reason: "DATE_IN_FUTURE"}},
+			},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			request := FetchRequest{Country: "United Kingdom", AsOf: stringPtr(tc.asOf)}
+			assert.Equal(t, tc.wantErr, validator.validate(request))
+		})
+	}
+}
+
+func TestListValidation(t *testing.T) {
+	validator := listValidator{}
+	cursor, _ := encodeCursor(Sort{Field: SortByAmountInCents}, Position{ID: "*txn-id*"})
+
+	t.Run("valid", func(t *testing.T) {
+		tcs := []struct {
+			name    string
+			request ListRequest
+		}{
+			{
+				name:    "nothing is supplied",
+				request: ListRequest{},
+			},
+			{
+				name: "everything is supplied",
+				request: ListRequest{
+					FromDate:         stringPtr("2023-01-01"),
+					ToDate:           stringPtr("2023-01-31"),
+					MinAmountInCents: stringPtr("-100"),
+					MaxAmountInCents: stringPtr("100"),
+					Description:      stringPtr("*description*"),
+					Sort:             stringPtr("amountInCents"),
+					Limit:            stringPtr("200"),
+					Cursor:           stringPtr(cursor),
+					Country:          stringPtr("ab"),
+					AsOf:             stringPtr("2023-01-31"),
+				},
+			},
+		}
+		for _, tc := range tcs {
+			t.Run(tc.name, func(t *testing.T) {
+				err := validator.validate(tc.request)
+				assert.Nil(t, err)
+			})
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		tcs := []struct {
+			name    string
+			request ListRequest
+			wantErr []business.FieldError
+		}{
+			{
+				name: "should return errors when the ranges are badly formatted",
+				request: ListRequest{
+					FromDate:         stringPtr("01/01/2023"),
+					ToDate:           stringPtr("2023-02-30"),
+					MinAmountInCents: stringPtr("1.5"),
+					MaxAmountInCents: stringPtr("ten"),
+				},
+				wantErr: []business.FieldError{
+					{
+						FieldName: "fromDate",
+						Reason:    "DATE_BAD_FORMAT",
+					},
+					{
+						FieldName: "toDate",
+						Reason:    "DATE_BAD_FORMAT",
+					},
+					{
+						FieldName: "minAmountInCents",
+						Reason:    "INTEGER_BAD_FORMAT",
+					},
+					{
+						FieldName: "maxAmountInCents",
+						Reason:    "INTEGER_BAD_FORMAT",
+					},
+				},
+			},
+			{
+				name:    "should return an error when the sort is not supported",
+				request: ListRequest{Sort: stringPtr("description")},
+				wantErr: []business.FieldError{
+					{
+						FieldName: "sort",
+						Reason:    "UNSUPPORTED_VALUE",
+					},
+				},
+			},
+			{
+				name:    "should return an error when the limit is one below the minimum",
+				request: ListRequest{Limit: stringPtr("0")},
+				wantErr: []business.FieldError{
+					{
+						FieldName: "limit",
+						Reason:    "MIN_VALUE",
+					},
+				},
+			},
+			{
+				name:    "should return an error when the limit is one above the maximum",
+				request: ListRequest{Limit: stringPtr("201")},
+				wantErr: []business.FieldError{
+					{
+						FieldName: "limit",
+						Reason:    "MAX_VALUE",
+					},
+				},
+			},
+			{
+				name:    "should return an error when the cursor cannot be decoded",
+				request: ListRequest{Cursor: stringPtr("*cursor*")},
+				wantErr: []business.FieldError{
+					{
+						FieldName: "cursor",
+						Reason:    "INVALID_CURSOR",
+					},
+				},
+			},
+			{
+				name:    "should return an error when the cursor was returned for a different sort",
+				request: ListRequest{Sort: stringPtr("-amountInCents"), Cursor: stringPtr(cursor)},
+				wantErr: []business.FieldError{
+					{
+						FieldName: "cursor",
+						Reason:    "INVALID_CURSOR",
+					},
+				},
+			},
+			{
+				name:    "should return an error when the country is one below minimum length",
+				request: ListRequest{Country: stringPtr("a")},
+				wantErr: []business.FieldError{
+					{
+						FieldName: "country",
+						Reason:    "MIN_LENGTH",
+					},
+				},
+			},
+		}
+		for _, tc := range tcs {
+			t.Run(tc.name, func(t *testing.T) {
+				err := validator.validate(tc.request)
+				wantErr := &business.Error{
+					Message: "VALIDATION_ERROR",
+					Fields:  tc.wantErr,
+				}
+				assert.Equal(t, wantErr, err)
+			})
+		}
+	})
+}
+
 func stringPtr(s string) *string {
 	return &s
 }
@@ -1,6 +1,7 @@
 package validation
 
 import (
+	"strconv"
 	"time"
 
 	"transaction-service/internal/business"
@@ -12,7 +13,12 @@ const (
 	MaxLength     business.Reason = "MAX_LENGTH"
 	DateBadFormat business.Reason = "DATE_BAD_FORMAT"
 	DateInFuture  business.Reason = "DATE_IN_FUTURE"
+	DateTooEarly  business.Reason = "DATE_TOO_EARLY"
 	ZeroValue     business.Reason = "ZERO_VALUE"
+	IntBadFormat  business.Reason = "INTEGER_BAD_FORMAT"
+	MinValue      business.Reason = "MIN_VALUE"
+	MaxValue      business.Reason = "MAX_VALUE"
+	Unsupported   business.Reason = "UNSUPPORTED_VALUE"
 
 	DateFormat = "2006-01-02"
 )
@@ -71,6 +77,14 @@ func IsDateNowOrEarlier(fieldName string, value time.Time) *business.FieldError
 	return nil
 }
 
+// IsDateNotBefore returns a business.FieldError if the supplied date is earlier than the supplied earliest date.
+func IsDateNotBefore(fieldName string, value time.Time, earliest time.Time) *business.FieldError {
+	if value.Before(earliest) {
+		return business.NewFieldError(fieldName, DateTooEarly)
+	}
+	return nil
+}
+
 // IsNotZero returns a business.FieldError if the supplied int value is zero.
 func IsNotZero(fieldName string, value *int) *business.FieldError {
 	if value != nil && *value == 0 {
@@ -79,6 +93,47 @@ func IsNotZero(fieldName string, value *int) *business.FieldError {
 	return nil
 }
 
+// IsInt returns a business.FieldError if the supplied string value is not a whole number.
+func IsInt(fieldName string, value *string) (int, *business.FieldError) {
+	if value == nil {
+		return 0, nil
+	}
+	parsed, err := strconv.Atoi(*value)
+	if err != nil {
+		return 0, business.NewFieldError(fieldName, IntBadFormat)
+	}
+	return parsed, nil
+}
+
+// IsMinValue returns a business.FieldError if the supplied int value is less than the supplied minimum.
+func IsMinValue(fieldName string, value *int, min int) *business.FieldError {
+	if value != nil && *value < min {
+		return business.NewFieldError(fieldName, MinValue)
+	}
+	return nil
+}
+
+// IsMaxValue returns a business.FieldError if the supplied int value is more than the supplied maximum.
+func IsMaxValue(fieldName string, value *int, max int) *business.FieldError {
+	if value != nil && *value > max {
+		return business.NewFieldError(fieldName, MaxValue)
+	}
+	return nil
+}
+
+// IsOneOf returns a business.FieldError if the supplied string value is not one of the supported values.
+func IsOneOf(fieldName string, value *string, supported []string) *business.FieldError {
+	if value == nil {
+		return nil
+	}
+	for _, s := range supported {
+		if *value == s {
+			return nil
+		}
+	}
+	return business.NewFieldError(fieldName, Unsupported)
+}
+
 // parseDate returns a date parsed using the configured date format or a business.FieldError if it does not match the format.
 func parseDate(fieldName string, value string) (time.Time, *business.FieldError) {
 	parsed, err := time.Parse(DateFormat, value)
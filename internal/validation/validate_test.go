@@ -335,6 +335,40 @@ func TestIsDateNowOrEarlier(t *testing.T) {
 	}
 }
 
+func TestIsDateNotBefore(t *testing.T) {
+	earliest := date.NewInUTC(2023, time.March, 31)
+	tcs := []struct {
+		name    string
+		value   time.Time
+		wantErr *business.FieldError
+	}{
+		{
+			name:    "should not return validation error when the supplied value is the earliest date",
+			value:   earliest,
+			wantErr: nil,
+		},
+		{
+			name:    "should not return validation error when the supplied value is after the earliest date",
+			value:   date.NewInUTC(2023, time.April, 1),
+			wantErr: nil,
+		},
+		{
+			name:  "should return validation error when the supplied value is before the earliest date",
+			value: date.NewInUTC(2023, time.March, 30),
+			wantErr: &business.FieldError{
+				FieldName: "*field-name*",
+				Reason:    business.Reason("DATE_TOO_EARLY"),
+			},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validation.IsDateNotBefore("*field-name*", tc.value, earliest)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
 func TestIsNotZero(t *testing.T) {
 	tcs := []struct {
 		name    string
@@ -381,6 +415,156 @@ func TestIsNotZero(t *testing.T) {
 	}
 }
 
+func TestIsInt(t *testing.T) {
+	tcs := []struct {
+		name      string
+		value     *string
+		wantValue int
+		wantErr   *business.FieldError
+	}{
+		{
+			name:      "should not return a validation error when the value is nil",
+			value:     nil,
+			wantValue: 0,
+			wantErr:   nil,
+		},
+		{
+			name:      "should return the parsed value when the value is a positive whole number",
+			value:     stringPtr("123"),
+			wantValue: 123,
+			wantErr:   nil,
+		},
+		{
+			name:      "should return the parsed value when the value is a negative whole number",
+			value:     stringPtr("-45"),
+			wantValue: -45,
+			wantErr:   nil,
+		},
+		{
+			name:  "should return a validation error when the value is empty",
+			value: stringPtr(""),
+			wantErr: &business.FieldError{
+				FieldName: "*field-name*",
+				Reason:    business.Reason("INTEGER_BAD_FORMAT"),
+			},
+		},
+		{
+			name:  "should return a validation error when the value has a fractional part",
+			value: stringPtr("1.5"),
+			wantErr: &business.FieldError{
+				FieldName: "*field-name*",
+				Reason:    business.Reason("INTEGER_BAD_FORMAT"),
+			},
+		},
+		{
+			name:  "should return a validation error when the value is not a number",
+			value: stringPtr("abc"),
+			wantErr: &business.FieldError{
+				FieldName: "*field-name*",
+				Reason:    business.Reason("INTEGER_BAD_FORMAT"),
+			},
+		},
+	}
+	for _, tc := range tcs {
+		value, err := validation.IsInt("*field-name*", tc.value)
+		assert.Equal(t, tc.wantValue, value)
+		assert.Equal(t, tc.wantErr, err)
+	}
+}
+
+func TestIsMinValue(t *testing.T) {
+	tcs := []struct {
+		name    string
+		value   *int
+		wantErr *business.FieldError
+	}{
+		{
+			name:    "should not return a validation error when the value is nil",
+			value:   nil,
+			wantErr: nil,
+		},
+		{
+			name:    "should not return a validation error when the value is the minimum",
+			value:   intPtr(1),
+			wantErr: nil,
+		},
+		{
+			name:  "should return a validation error when the value is just less than the minimum",
+			value: intPtr(0),
+			wantErr: &business.FieldError{
+				FieldName: "*field-name*",
+				Reason:    business.Reason("MIN_VALUE"),
+			},
+		},
+	}
+	for _, tc := range tcs {
+		err := validation.IsMinValue("*field-name*", tc.value, 1)
+		assert.Equal(t, tc.wantErr, err)
+	}
+}
+
+func TestIsMaxValue(t *testing.T) {
+	tcs := []struct {
+		name    string
+		value   *int
+		wantErr *business.FieldError
+	}{
+		{
+			name:    "should not return a validation error when the value is nil",
+			value:   nil,
+			wantErr: nil,
+		},
+		{
+			name:    "should not return a validation error when the value is the maximum",
+			value:   intPtr(10),
+			wantErr: nil,
+		},
+		{
+			name:  "should return a validation error when the value is just more than the maximum",
+			value: intPtr(11),
+			wantErr: &business.FieldError{
+				FieldName: "*field-name*",
+				Reason:    business.Reason("MAX_VALUE"),
+			},
+		},
+	}
+	for _, tc := range tcs {
+		err := validation.IsMaxValue("*field-name*", tc.value, 10)
+		assert.Equal(t, tc.wantErr, err)
+	}
+}
+
+func TestIsOneOf(t *testing.T) {
+	tcs := []struct {
+		name    string
+		value   *string
+		wantErr *business.FieldError
+	}{
+		{
+			name:    "should not return a validation error when the value is nil",
+			value:   nil,
+			wantErr: nil,
+		},
+		{
+			name:    "should not return a validation error when the value is supported",
+			value:   stringPtr("b"),
+			wantErr: nil,
+		},
+		{
+			name:  "should return a validation error when the value is not supported",
+			value: stringPtr("B"),
+			wantErr: &business.FieldError{
+				FieldName: "*field-name*",
+				Reason:    business.Reason("UNSUPPORTED_VALUE"),
+			},
+		},
+	}
+	for _, tc := range tcs {
+		err := validation.IsOneOf("*field-name*", tc.value, []string{"a", "b"})
+		assert.Equal(t, tc.wantErr, err)
+	}
+}
+
 func stringPtr(value string) *string {
 	return &value
 }
@@ -5,15 +5,28 @@ import (
 	"os"
 
 	"transaction-service/internal/app"
-	"transaction-service/internal/transaction"
 )
 
-const port = 8080
-
 func main() {
-	application := app.New(app.NewDependencies(transaction.NewUUIDGenerator(), app.NewHttpClient()))
-	if err := application.Start(port); err != nil {
-		fmt.Printf("An error occured: %v", err)
-		os.Exit(1)
+	config, err := app.LoadConfig()
+	if err != nil {
+		exit(err)
+	}
+	txnIDGenerator, err := app.NewTxnIDGenerator(config)
+	if err != nil {
+		exit(err)
+	}
+	deps, err := app.NewDependencies(config, txnIDGenerator, app.NewHttpClient())
+	if err != nil {
+		exit(err)
 	}
+	application := app.New(deps)
+	if err := application.Start(config.Port); err != nil {
+		exit(err)
+	}
+}
+
+func exit(err error) {
+	fmt.Printf("An error occured: %v", err)
+	os.Exit(1)
 }
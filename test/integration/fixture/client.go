@@ -2,6 +2,8 @@ package fixture
 
 import (
 	"fmt"
+	"net/http"
+	"net/url"
 	"strings"
 	"testing"
 )
@@ -25,9 +27,158 @@ func (c *Client) StoreTransaction(t *testing.T, payload string) (int, string) {
 	return Post(t, url, strings.NewReader(payload))
 }
 
+// StoreTransactionWithIdempotencyKey calls the 'store transaction' operation with the supplied idempotency key and
+// payload, returning the response status and body.  Should an error occur, the current test will be failed.
+func (c *Client) StoreTransactionWithIdempotencyKey(t *testing.T, key, payload string) (int, string) {
+	url := fmt.Sprintf("%s/transaction", c.baseURL)
+	header := http.Header{}
+	header.Set("Idempotency-Key", key)
+	return PostWithHeader(t, url, header, strings.NewReader(payload))
+}
+
+// StoreTransactions calls the 'store transactions in batch' operation with the supplied (encoded) query string and
+// payload, returning the response status and body.  Should an error occur, the current test will be failed.
+func (c *Client) StoreTransactions(t *testing.T, query, payload string) (int, string) {
+	url := fmt.Sprintf("%s/transactions/batch?%s", c.baseURL, query)
+	return Post(t, url, strings.NewReader(payload))
+}
+
 // FetchTransaction calls the 'fetch transaction' operation with the supplied transaction id and country, returning the
 // response status and body.  Should an error occur, the current test will be failed.
 func (c *Client) FetchTransaction(t *testing.T, id, country string) (int, string) {
 	url := fmt.Sprintf("%s/transaction/%s?country=%s", c.baseURL, id, country)
 	return Get(t, url)
 }
+
+// FetchTransactionWithQuery calls the 'fetch transaction' operation with the supplied transaction id and (encoded)
+// query string, returning the response status and body.  Should an error occur, the current test will be failed.
+func (c *Client) FetchTransactionWithQuery(t *testing.T, id, query string) (int, string) {
+	return Get(t, fmt.Sprintf("%s/transaction/%s?%s", c.baseURL, id, query))
+}
+
+// FetchTransactionForCountries calls the 'fetch transaction' operation with the supplied transaction id, converting to
+// the currency of each of the supplied countries, returning the response status and body.  Should an error occur, the
+// current test will be failed.
+func (c *Client) FetchTransactionForCountries(t *testing.T, id string, countries ...string) (int, string) {
+	query := url.Values{"country": countries}
+	return Get(t, fmt.Sprintf("%s/transaction/%s?%s", c.baseURL, id, query.Encode()))
+}
+
+// FetchTransactionInCurrency calls the 'fetch transaction' operation with the supplied transaction id, converting to the
+// currency with the supplied ISO 4217 code, returning the response status and body.  Should an error occur, the current
+// test will be failed.
+func (c *Client) FetchTransactionInCurrency(t *testing.T, id, currency string) (int, string) {
+	url := fmt.Sprintf("%s/transaction/%s?currency=%s", c.baseURL, id, currency)
+	return Get(t, url)
+}
+
+// ListTransactions calls the 'list transactions' operation with the supplied (encoded) query string, returning the
+// response status and body.  Should an error occur, the current test will be failed.
+func (c *Client) ListTransactions(t *testing.T, query string) (int, string) {
+	url := fmt.Sprintf("%s/transactions?%s", c.baseURL, query)
+	return Get(t, url)
+}
+
+// UpdateTransaction calls the 'update transaction' operation using the supplied http method (PUT or PATCH), transaction
+// id, If-Match header and payload, returning the response status, ETag header and body.  Should an error occur, the
+// current test will be failed.
+func (c *Client) UpdateTransaction(t *testing.T, method, id, ifMatch, payload string) (int, string, string) {
+	url := fmt.Sprintf("%s/transaction/%s", c.baseURL, id)
+	req, err := http.NewRequest(method, url, strings.NewReader(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", ifMatch)
+	status, header, body := Send(t, req)
+	return status, header.Get("ETag"), body
+}
+
+// VoidTransaction calls the 'void transaction' operation with the supplied transaction id and reason, returning the
+// response status, ETag header and body.  Should an error occur, the current test will be failed.
+func (c *Client) VoidTransaction(t *testing.T, id, reason string) (int, string, string) {
+	url := fmt.Sprintf("%s/transaction/%s", c.baseURL, id)
+	payload := fmt.Sprintf(`{"reason": %q}`, reason)
+	req, err := http.NewRequest(http.MethodDelete, url, strings.NewReader(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	status, header, body := Send(t, req)
+	return status, header.Get("ETag"), body
+}
+
+// RestoreTransaction calls the 'restore transaction' operation with the supplied transaction id, returning the response
+// status, ETag header and body.  Should an error occur, the current test will be failed.
+func (c *Client) RestoreTransaction(t *testing.T, id string) (int, string, string) {
+	url := fmt.Sprintf("%s/transaction/%s/restore", c.baseURL, id)
+	req, err := http.NewRequest(http.MethodPost, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, header, body := Send(t, req)
+	return status, header.Get("ETag"), body
+}
+
+// FetchTransactionIncludingVoided calls the 'fetch transaction' operation with the supplied transaction id and country,
+// asking for the transaction even if it has been voided, and returns the response status and body.  Should an error
+// occur, the current test will be failed.
+func (c *Client) FetchTransactionIncludingVoided(t *testing.T, id, country string) (int, string) {
+	url := fmt.Sprintf("%s/transaction/%s?country=%s&includeVoided=true", c.baseURL, id, country)
+	return Get(t, url)
+}
+
+// ExportTransactions calls the 'export transactions' operation with the supplied (encoded) query string and Accept
+// header, returning the response status, Content-Type header and body.  Should an error occur, the current test will be
+// failed.
+func (c *Client) ExportTransactions(t *testing.T, query, accept string) (int, string, string) {
+	url := fmt.Sprintf("%s/transactions/export?%s", c.baseURL, query)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", accept)
+	status, header, body := Send(t, req)
+	return status, header.Get("Content-Type"), body
+}
+
+// ExchangeRateHistory calls the 'exchange rate history' operation with the supplied (encoded) query string, returning
+// the response status and body.  Should an error occur, the current test will be failed.
+func (c *Client) ExchangeRateHistory(t *testing.T, query string) (int, string) {
+	return Get(t, fmt.Sprintf("%s/exchange-rates?%s", c.baseURL, query))
+}
+
+// ConvertAmount calls the 'convert amount' operation with the supplied payload, returning the response status and body.
+// Should an error occur, the current test will be failed.
+func (c *Client) ConvertAmount(t *testing.T, payload string) (int, string) {
+	return Post(t, fmt.Sprintf("%s/conversions", c.baseURL), strings.NewReader(payload))
+}
+
+// Countries calls the 'list countries' operation, returning the response status and body.  Should an error occur, the
+// current test will be failed.
+func (c *Client) Countries(t *testing.T) (int, string) {
+	return Get(t, c.baseURL+"/countries")
+}
+
+// ExchangeRateCacheStats calls the 'exchange rate cache statistics' operation, returning the response status and body.
+// Should an error occur, the current test will be failed.
+func (c *Client) ExchangeRateCacheStats(t *testing.T) (int, string) {
+	return Get(t, c.baseURL+"/exchange-rate-cache")
+}
+
+// InvalidateExchangeRateCache calls the 'invalidate exchange rate cache' operation with the supplied (encoded) query
+// string, returning the response status and body.  Should an error occur, the current test will be failed.
+func (c *Client) InvalidateExchangeRateCache(t *testing.T, query string) (int, string) {
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/exchange-rate-cache?%s", c.baseURL, query), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, _, body := Send(t, req)
+	return status, body
+}
+
+// Health calls the 'health' operation, returning the response status and body.  Should an error occur, the current
+// test will be failed.
+func (c *Client) Health(t *testing.T) (int, string) {
+	return Get(t, c.baseURL+"/health")
+}
@@ -27,6 +27,33 @@ func Post(t *testing.T, url string, body io.Reader) (int, string) {
 	return response.StatusCode, string(responseBody)
 }
 
+// PostWithHeader performs a http post operation with the supplied url, header and body, returning that response status
+// and body.
+func PostWithHeader(t *testing.T, url string, header http.Header, body io.Reader) (int, string) {
+	req, err := http.NewRequest(http.MethodPost, url, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header = header
+	req.Header.Set("Content-Type", "application/json")
+	status, _, responseBody := Send(t, req)
+	return status, responseBody
+}
+
+// Send performs the supplied http request, returning that response status, header and body.
+func Send(t *testing.T, req *http.Request) (int, http.Header, string) {
+	response, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return response.StatusCode, response.Header, string(responseBody)
+}
+
 // Get performs a http get operation with the supplied url, returning that response status and body.
 func Get(t *testing.T, url string) (int, string) {
 	response, err := http.Get(url)
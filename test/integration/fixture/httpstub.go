@@ -14,20 +14,59 @@ const (
 
 	treasuryURL  = "https://api.fiscaldata.treasury.gov/services/api/fiscal_service/v1/accounting/od/rates_of_exchange?sort=-record_date&format=json&filter=record_date:gte:2022-11-01,country:eq:United+Kingdom&page[size]=1&page[number]=1"
 	treasuryBody = `{"data": [{"record_date": "2020-08-01", "exchange_rate": "0.345"}]}`
+
+	datasetTreasuryURL  = "https://api.fiscaldata.treasury.gov/services/api/fiscal_service/v1/accounting/od/rates_of_exchange?sort=record_date,country&format=json&fields=country,currency,exchange_rate,record_date&page[size]=5000&page[number]=1"
+	datasetTreasuryBody = `{
+		"data": [
+			{"country": "Australia", "currency": "Dollar", "record_date": "2023-03-31", "exchange_rate": "1.495"},
+			{"country": "United Kingdom", "currency": "Pound", "record_date": "2023-03-31", "exchange_rate": "0.812"}
+		],
+		"meta": {"total-pages": 1}
+	}`
+
+	latestOnOrBeforeTreasuryURL  = "https://api.fiscaldata.treasury.gov/services/api/fiscal_service/v1/accounting/od/rates_of_exchange?sort=record_date&format=json&filter=record_date:gte:2022-11-01,record_date:lte:2023-05-01,country:eq:United+Kingdom&page[size]=5000&page[number]=1"
+	latestOnOrBeforeTreasuryBody = `{
+		"data": [
+			{"country": "United Kingdom", "record_date": "2022-12-31", "exchange_rate": "0.826"},
+			{"country": "United Kingdom", "record_date": "2023-03-31", "exchange_rate": "0.812"}
+		],
+		"meta": {"total-pages": 1}
+	}`
+
+	historyTreasuryURL  = "https://api.fiscaldata.treasury.gov/services/api/fiscal_service/v1/accounting/od/rates_of_exchange?sort=record_date&format=json&filter=record_date:gte:2022-10-01,record_date:lte:2023-04-30,country:eq:United+Kingdom&page[size]=5000&page[number]=1"
+	historyTreasuryBody = `{
+		"data": [
+			{"country": "United Kingdom", "record_date": "2022-12-31", "exchange_rate": "0.826"},
+			{"country": "United Kingdom", "record_date": "2023-03-31", "exchange_rate": "0.812"}
+		],
+		"meta": {"total-pages": 1}
+	}`
 )
 
 // NewStubHttpClient creates a StubHttpClient configured with a stub response.
 func NewStubHttpClient() *StubHttpClient {
 	return &StubHttpClient{
-		requestDetails: map[RequestDetails]*http.Response{
+		requestDetails: map[RequestDetails]cannedResponse{
 			{
 				Method: http.MethodGet,
 				URL:    treasuryURL,
-			}: newResponse(http.StatusOK, treasuryBody),
+			}: {status: http.StatusOK, body: treasuryBody},
 			{
 				Method: http.MethodGet,
 				URL:    noExchangeRecordTreasuryURL,
-			}: newResponse(http.StatusOK, noExchangeRateTreasuryBody),
+			}: {status: http.StatusOK, body: noExchangeRateTreasuryBody},
+			{
+				Method: http.MethodGet,
+				URL:    datasetTreasuryURL,
+			}: {status: http.StatusOK, body: datasetTreasuryBody},
+			{
+				Method: http.MethodGet,
+				URL:    latestOnOrBeforeTreasuryURL,
+			}: {status: http.StatusOK, body: latestOnOrBeforeTreasuryBody},
+			{
+				Method: http.MethodGet,
+				URL:    historyTreasuryURL,
+			}: {status: http.StatusOK, body: historyTreasuryBody},
 		},
 	}
 }
@@ -36,7 +75,14 @@ func NewStubHttpClient() *StubHttpClient {
 // Treasury API when running integration tests.  There are various reasons for doing this, including the fact that we
 // would like these tests to be repeatable and stable.
 type StubHttpClient struct {
-	requestDetails map[RequestDetails]*http.Response
+	requestDetails map[RequestDetails]cannedResponse
+}
+
+// cannedResponse holds the details of a stub response.  A new http.Response is created from it for each request, since
+// the body of a response can only be read once.
+type cannedResponse struct {
+	status int
+	body   string
 }
 
 // Do returns the configured http.Response that matches the provided http.Request.
@@ -49,7 +95,7 @@ func (c *StubHttpClient) Do(req *http.Request) (*http.Response, error) {
 	if !ok {
 		return nil, fmt.Errorf("http client stub missing canned response for: %+v", details)
 	}
-	return response, nil
+	return newResponse(response.status, response.body), nil
 }
 
 // RequestDetails represents the details on which incoming requests will be matched.
@@ -31,7 +31,11 @@ type TestServer struct {
 // results in the next available port being allocated to the test server.  There should never be port conflicts with
 // any running integration tests or standalone server.
 func (s *TestServer) Start(t *testing.T) {
-	s.application = app.New(app.NewDependencies(id.NewSequentialGenerator(), NewStubHttpClient()))
+	deps, err := app.NewDependencies(app.NewConfig(), id.NewSequentialGenerator(), NewStubHttpClient())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.application = app.New(deps)
 	server := httptest.NewUnstartedServer(s.application.Router)
 	listener, err := app.NewListener(nextAvailablePort)
 	if err != nil {
@@ -1,8 +1,10 @@
 package integration_test
 
 import (
+	"encoding/json"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -58,6 +60,85 @@ func TestStoreTransaction(t *testing.T) {
 	})
 }
 
+func TestStoreTransactionIdempotently(t *testing.T) {
+	t.Run("replay with identical request returns original response", func(t *testing.T) {
+		setUp(t)
+		payload := `{
+			"description": "A holiday somewhere nice",
+			"transactionDate": "2023-05-01",
+			"amountInCents": 100
+		}`
+		client.StoreTransactionWithIdempotencyKey(t, "*key*", payload)
+		status, body := client.StoreTransactionWithIdempotencyKey(t, "*key*", payload)
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.JSONEq(t, `{"id":"sequentialID-1"}`, body)
+		tearDown()
+	})
+	t.Run("replay with different request is a conflict", func(t *testing.T) {
+		setUp(t)
+		client.StoreTransactionWithIdempotencyKey(t, "*key*", `{
+			"description": "A holiday somewhere nice",
+			"transactionDate": "2023-05-01",
+			"amountInCents": 100
+		}`)
+		status, body := client.StoreTransactionWithIdempotencyKey(t, "*key*", `{
+			"description": "A holiday somewhere nicer",
+			"transactionDate": "2023-05-01",
+			"amountInCents": 100
+		}`)
+
+		assert.Equal(t, http.StatusConflict, status)
+		assert.JSONEq(t, `{"message": "IDEMPOTENCY_KEY_REUSED"}`, body)
+		tearDown()
+	})
+}
+
+func TestStoreTransactions(t *testing.T) {
+	payload := `[
+		{"description": "Coffee", "transactionDate": "2023-05-01", "amountInCents": 450},
+		{"description": "Nothing", "transactionDate": "2023-05-01", "amountInCents": 0},
+		{"description": "Groceries", "transactionDate": "2023-05-01", "amountInCents": 8000}
+	]`
+	t.Run("success - should store the valid transactions", func(t *testing.T) {
+		setUp(t)
+		status, body := client.StoreTransactions(t, "", payload)
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.JSONEq(t, `{
+			"results": [
+				{"index": 0, "id": "sequentialID-1"},
+				{"index": 1, "error": {"fields":[{"fieldName": "amountInCents", "reason": "ZERO_VALUE"}], "message": "VALIDATION_ERROR"}},
+				{"index": 2, "id": "sequentialID-2"}
+			],
+			"stored": 2,
+			"failed": 1
+		}`, body)
+		status, _ = client.FetchTransaction(t, "sequentialID-2", "United%20Kingdom")
+		assert.Equal(t, http.StatusOK, status)
+		tearDown()
+	})
+	t.Run("atomic - should store nothing when any transaction is invalid", func(t *testing.T) {
+		setUp(t)
+		status, body := client.StoreTransactions(t, "atomic=true", payload)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+		assert.JSONEq(t, `{
+			"results": [
+				{"index": 0},
+				{"index": 1, "error": {"fields":[{"fieldName": "amountInCents", "reason": "ZERO_VALUE"}], "message": "VALIDATION_ERROR"}},
+				{"index": 2}
+			],
+			"stored": 0,
+			"failed": 1
+		}`, body)
+		status, body = client.ListTransactions(t, "")
+		assert.Equal(t, http.StatusOK, status)
+		assert.JSONEq(t, `{"transactions": []}`, body)
+		tearDown()
+	})
+}
+
 func TestFetchTransaction(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		setUp(t)
@@ -79,7 +160,116 @@ func TestFetchTransaction(t *testing.T) {
 				"amount": {
 					"convertedAmountInCents": 35,
 					"exchangeRate": 0.345,
-					"usdAmountInCents": 100
+					"rateDate": "2020-08-01",
+					"usdAmountInCents": 100,
+					"valuationDate": "2023-05-01",
+					"roundingMode": "HALF_AWAY_FROM_ZERO"
+				}
+			}
+		}`, body)
+		tearDown()
+	})
+	t.Run("success - should match the country case-insensitively or by alias", func(t *testing.T) {
+		setUp(t)
+		waitForCountries(t)
+		client.StoreTransaction(t, `{
+			"description": "A holiday somewhere nice",
+			"transactionDate": "2023-05-01",
+			"amountInCents": 100
+		}`)
+		for _, country := range []string{"united%20kingdom", "UK"} {
+			status, body := client.FetchTransaction(t, "sequentialID-1", country)
+			assert.Equal(t, http.StatusOK, status)
+			assert.Contains(t, body, `"convertedAmountInCents":35`)
+		}
+		tearDown()
+	})
+	t.Run("success - should convert to the currency of each requested country, reporting failures alongside", func(t *testing.T) {
+		setUp(t)
+		waitForCountries(t)
+		client.StoreTransaction(t, `{
+			"description": "A holiday somewhere nice",
+			"transactionDate": "2023-05-01",
+			"amountInCents": 100
+		}`)
+		status, body := client.FetchTransactionForCountries(t, "sequentialID-1", "United Kingdom", "Atlantis")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.JSONEq(t, `{
+			"transaction": {
+				"id": "sequentialID-1",
+				"description": "A holiday somewhere nice",
+				"transactionDate": "2023-05-01",
+				"amounts": [
+					{
+						"country": "United Kingdom",
+						"amount": {
+							"convertedAmountInCents": 35,
+							"exchangeRate": 0.345,
+							"rateDate": "2020-08-01",
+							"usdAmountInCents": 100,
+							"valuationDate": "2023-05-01",
+							"roundingMode": "HALF_AWAY_FROM_ZERO"
+						}
+					},
+					{
+						"country": "Atlantis",
+						"error": {"message": "UNKNOWN_COUNTRY"}
+					}
+				]
+			}
+		}`, body)
+		tearDown()
+	})
+	t.Run("success - should select the exchange rate by the policy supplied with the request", func(t *testing.T) {
+		setUp(t)
+		client.StoreTransaction(t, `{
+			"description": "A holiday somewhere nice",
+			"transactionDate": "2023-05-01",
+			"amountInCents": 100
+		}`)
+		status, body := client.FetchTransactionWithQuery(t, "sequentialID-1", "country=United%20Kingdom&ratePolicy=LATEST_ON_OR_BEFORE")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Contains(t, body, `"exchangeRate":0.812,"rateDate":"2023-03-31"`)
+		tearDown()
+	})
+	t.Run("success - should convert as at the supplied valuation date rather than the transaction date", func(t *testing.T) {
+		setUp(t)
+		client.StoreTransaction(t, `{
+			"description": "A holiday somewhere nice",
+			"transactionDate": "2021-01-10",
+			"amountInCents": 100
+		}`)
+		status, body := client.FetchTransactionWithQuery(t, "sequentialID-1", "country=United%20Kingdom&asOf=2023-05-01")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Contains(t, body, `"rateDate":"2020-08-01","valuationDate":"2023-05-01"`)
+		tearDown()
+	})
+	t.Run("success - should convert to the currency with the supplied code", func(t *testing.T) {
+		setUp(t)
+		client.StoreTransaction(t, `{
+			"description": "A holiday somewhere nice",
+			"transactionDate": "2023-05-01",
+			"amountInCents": 100
+		}`)
+		status, body := client.FetchTransactionInCurrency(t, "sequentialID-1", "GBP")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.JSONEq(t, `{
+			"transaction": {
+				"id": "sequentialID-1",
+				"description": "A holiday somewhere nice",
+				"transactionDate": "2023-05-01",
+				"amount": {
+					"convertedAmountInCents": 35,
+					"exchangeRate": 0.345,
+					"rateDate": "2020-08-01",
+					"usdAmountInCents": 100,
+					"valuationDate": "2023-05-01",
+					"currencyCode": "GBP",
+					"roundingMode": "HALF_AWAY_FROM_ZERO"
 				}
 			}
 		}`, body)
@@ -115,5 +305,328 @@ func TestFetchTransaction(t *testing.T) {
 			assert.JSONEq(t, `{"message": "UNABLE_TO_CONVERT_TO_TARGET_CURRENCY"}`, body)
 			tearDown()
 		})
+		t.Run("unknown country error", func(t *testing.T) {
+			setUp(t)
+			waitForCountries(t)
+			client.StoreTransaction(t, `{
+			"description": "A holiday somewhere made up",
+			"transactionDate": "2023-05-01",
+			"amountInCents": 100
+		}`)
+			status, body := client.FetchTransaction(t, "sequentialID-1", "Atlantis")
+			assert.Equal(t, http.StatusUnprocessableEntity, status)
+			assert.JSONEq(t, `{"message": "UNKNOWN_COUNTRY"}`, body)
+			tearDown()
+		})
+		t.Run("unknown country error suggesting the closest known countries", func(t *testing.T) {
+			setUp(t)
+			waitForCountries(t)
+			client.StoreTransaction(t, `{
+			"description": "A holiday somewhere misspelt",
+			"transactionDate": "2023-05-01",
+			"amountInCents": 100
+		}`)
+			status, body := client.FetchTransaction(t, "sequentialID-1", "Untied%20Kingdom")
+			assert.Equal(t, http.StatusUnprocessableEntity, status)
+			assert.JSONEq(t, `{"message": "UNKNOWN_COUNTRY", "suggestions": ["United Kingdom"]}`, body)
+			tearDown()
+		})
+	})
+}
+
+func TestCountries(t *testing.T) {
+	t.Run("success - should list every country and currency pair known to the Treasury dataset", func(t *testing.T) {
+		setUp(t)
+		waitForCountries(t)
+		status, body := client.Countries(t)
+		assert.Equal(t, http.StatusOK, status)
+		var response struct {
+			Countries json.RawMessage `json:"countries"`
+		}
+		assert.Nil(t, json.Unmarshal([]byte(body), &response))
+		assert.JSONEq(t, `[
+			{"country": "Australia", "currency": "Dollar", "latestRateDate": "2023-03-31"},
+			{"country": "United Kingdom", "currency": "Pound", "latestRateDate": "2023-03-31"}
+		]`, string(response.Countries))
+		tearDown()
+	})
+}
+
+// waitForCountries waits for the countries known to the Treasury dataset to be loaded in the background.
+func waitForCountries(t *testing.T) {
+	assert.Eventually(t, func() bool {
+		status, _ := client.Countries(t)
+		return status == http.StatusOK
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestExchangeRateCache(t *testing.T) {
+	t.Run("success - should answer repeated lookups from the cache until invalidated", func(t *testing.T) {
+		setUp(t)
+		client.StoreTransaction(t, `{"description": "Coffee", "transactionDate": "2023-05-01", "amountInCents": 450}`)
+		client.FetchTransaction(t, "sequentialID-1", "United%20Kingdom")
+		client.FetchTransaction(t, "sequentialID-1", "United%20Kingdom")
+
+		status, body := client.ExchangeRateCacheStats(t)
+		assert.Equal(t, http.StatusOK, status)
+		assert.JSONEq(t, `{"hits": 1, "negativeHits": 0, "misses": 1, "evictions": 0, "entries": 1}`, body)
+
+		status, body = client.InvalidateExchangeRateCache(t, "country=United%20Kingdom")
+		assert.Equal(t, http.StatusOK, status)
+		assert.JSONEq(t, `{"invalidated": 1}`, body)
+		tearDown()
+	})
+}
+
+func TestExchangeRateHistory(t *testing.T) {
+	t.Run("success - should return every exchange rate of the country in the period", func(t *testing.T) {
+		setUp(t)
+		status, body := client.ExchangeRateHistory(t, "country=United%20Kingdom&from=2022-10-01&to=2023-04-30")
+		assert.Equal(t, http.StatusOK, status)
+		assert.JSONEq(t, `{
+			"country": "United Kingdom",
+			"from": "2022-10-01",
+			"to": "2023-04-30",
+			"rates": [
+				{"recordDate": "2022-12-31", "exchangeRate": 0.826},
+				{"recordDate": "2023-03-31", "exchangeRate": 0.812}
+			]
+		}`, body)
+		tearDown()
+	})
+	t.Run("failure - should return a validation error when the period ends before it starts", func(t *testing.T) {
+		setUp(t)
+		status, body := client.ExchangeRateHistory(t, "country=United%20Kingdom&from=2023-04-30&to=2022-10-01")
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+		assert.JSONEq(t, `{"message": "VALIDATION_ERROR", "fields": [{"fieldName": "to", "reason": "DATE_TOO_EARLY"}]}`, body)
+		tearDown()
+	})
+}
+
+func TestConvertAmount(t *testing.T) {
+	t.Run("success - should convert the amount without storing a transaction", func(t *testing.T) {
+		setUp(t)
+		status, body := client.ConvertAmount(t, `{"amountInCents": 100, "date": "2023-05-01", "country": "United Kingdom"}`)
+		assert.Equal(t, http.StatusOK, status)
+		assert.JSONEq(t, `{
+			"amountInCents": 100,
+			"convertedAmountInCents": 35,
+			"exchangeRate": 0.345,
+			"rateDate": "2020-08-01",
+			"roundingMode": "HALF_AWAY_FROM_ZERO"
+		}`, body)
+		tearDown()
+	})
+	t.Run("business validation error", func(t *testing.T) {
+		setUp(t)
+		status, body := client.ConvertAmount(t, `{"amountInCents": 100, "date": "2023-05-01"}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+		assert.JSONEq(t, `{"fields":[{"fieldName": "country", "reason": "REQUIRED"}], "message": "VALIDATION_ERROR"}`, body)
+		tearDown()
+	})
+}
+
+func TestHealth(t *testing.T) {
+	t.Run("success - should report the service and the treasury api as up", func(t *testing.T) {
+		setUp(t)
+		status, body := client.Health(t)
+		assert.Equal(t, http.StatusOK, status)
+		assert.JSONEq(t, `{
+			"status": "UP",
+			"components": {"treasuryApi": {"status": "UP", "details": {"state": "closed", "consecutiveFailures": 0}}}
+		}`, body)
+		tearDown()
+	})
+}
+
+func TestListTransactions(t *testing.T) {
+	t.Run("success - should list a page at a time, converting each amount", func(t *testing.T) {
+		setUp(t)
+		for _, payload := range []string{
+			`{"description": "Coffee", "transactionDate": "2023-05-01", "amountInCents": 450}`,
+			`{"description": "Refund: coffee", "transactionDate": "2023-05-01", "amountInCents": -450}`,
+			`{"description": "Groceries", "transactionDate": "2023-05-01", "amountInCents": 8000}`,
+		} {
+			client.StoreTransaction(t, payload)
+		}
+
+		status, body := client.ListTransactions(t, "sort=amountInCents&limit=2&country=United%20Kingdom")
+		assert.Equal(t, http.StatusOK, status)
+		var page struct {
+			Transactions []struct {
+				ID     string `json:"id"`
+				Amount struct {
+					ConvertedAmountInCents int `json:"convertedAmountInCents"`
+				} `json:"amount"`
+			} `json:"transactions"`
+			NextCursor string `json:"nextCursor"`
+		}
+		assert.Nil(t, json.Unmarshal([]byte(body), &page))
+		assert.Len(t, page.Transactions, 2)
+		assert.Equal(t, "sequentialID-2", page.Transactions[0].ID)
+		assert.Equal(t, -155, page.Transactions[0].Amount.ConvertedAmountInCents)
+		assert.Equal(t, "sequentialID-1", page.Transactions[1].ID)
+		assert.NotEmpty(t, page.NextCursor)
+
+		status, body = client.ListTransactions(t, "sort=amountInCents&limit=2&cursor="+page.NextCursor)
+		assert.Equal(t, http.StatusOK, status)
+		assert.JSONEq(t, `{
+			"transactions": [
+				{
+					"id": "sequentialID-3",
+					"description": "Groceries",
+					"transactionDate": "2023-05-01",
+					"amountInCents": 8000
+				}
+			]
+		}`, body)
+		tearDown()
+	})
+	t.Run("business validation error", func(t *testing.T) {
+		setUp(t)
+		status, body := client.ListTransactions(t, "sort=description&limit=1000")
+
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+		assert.JSONEq(t, `{"fields":[{"fieldName": "sort", "reason": "UNSUPPORTED_VALUE"}, {"fieldName": "limit", "reason": "MAX_VALUE"}], "message": "VALIDATION_ERROR"}`, body)
+		tearDown()
+	})
+}
+
+func TestExportTransactions(t *testing.T) {
+	t.Run("success - should stream every matching transaction as CSV, converting each amount", func(t *testing.T) {
+		setUp(t)
+		client.StoreTransaction(t, `{"description": "Coffee", "transactionDate": "2023-05-01", "amountInCents": 450}`)
+		client.StoreTransaction(t, `{"description": "Groceries", "transactionDate": "2023-05-01", "amountInCents": 8000}`)
+
+		status, contentType, body := client.ExportTransactions(t, "sort=amountInCents&country=United%20Kingdom", "text/csv")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "text/csv", contentType)
+		assert.Equal(t, "id,description,transactionDate,usdAmountInCents,convertedAmountInCents,exchangeRate,rateDate,valuationDate,currencyCode,roundingMode,fallbackFetchedAt,fallbackAgeSeconds,conversionError,voidReason,voidedAt\n"+
+			"sequentialID-1,Coffee,2023-05-01,450,155,0.345,2020-08-01,2023-05-01,,HALF_AWAY_FROM_ZERO,,,,,\n"+
+			"sequentialID-2,Groceries,2023-05-01,8000,2760,0.345,2020-08-01,2023-05-01,,HALF_AWAY_FROM_ZERO,,,,,\n", body)
+		tearDown()
+	})
+	t.Run("success - should stream the transactions as newline delimited JSON", func(t *testing.T) {
+		setUp(t)
+		client.StoreTransaction(t, `{"description": "Coffee", "transactionDate": "2023-05-01", "amountInCents": 450}`)
+
+		status, contentType, body := client.ExportTransactions(t, "country=United%20Kingdom", "application/x-ndjson")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "application/x-ndjson", contentType)
+		assert.JSONEq(t, `{
+			"id": "sequentialID-1",
+			"description": "Coffee",
+			"transactionDate": "2023-05-01",
+			"amountInCents": 450,
+			"amount": {"usdAmountInCents": 450, "convertedAmountInCents": 155, "exchangeRate": 0.345, "rateDate": "2020-08-01", "valuationDate": "2023-05-01",
+				"roundingMode": "HALF_AWAY_FROM_ZERO"}
+		}`, body)
+		tearDown()
+	})
+	t.Run("business validation error", func(t *testing.T) {
+		setUp(t)
+		status, _, body := client.ExportTransactions(t, "sort=description", "text/csv")
+
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+		assert.JSONEq(t, `{"fields":[{"fieldName": "sort", "reason": "UNSUPPORTED_VALUE"}, {"fieldName": "country", "reason": "REQUIRED"}], "message": "VALIDATION_ERROR"}`, body)
+		tearDown()
+	})
+}
+
+func TestUpdateTransaction(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		setUp(t)
+		client.StoreTransaction(t, `{
+			"description": "A holiday somewhere nice",
+			"transactionDate": "2023-05-01",
+			"amountInCents": 100
+		}`)
+		status, etag, body := client.UpdateTransaction(t, http.MethodPut, "sequentialID-1", `"1"`, `{
+			"description": "A holiday somewhere nicer",
+			"transactionDate": "2023-05-01",
+			"amountInCents": 200
+		}`)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, `"2"`, etag)
+		assert.JSONEq(t, `{"id":"sequentialID-1"}`, body)
+
+		status, etag, _ = client.UpdateTransaction(t, http.MethodPatch, "sequentialID-1", etag, `{"amountInCents": 300}`)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, `"3"`, etag)
+		tearDown()
+	})
+	t.Run("precondition failed when the transaction has been changed by someone else", func(t *testing.T) {
+		setUp(t)
+		client.StoreTransaction(t, `{
+			"description": "A holiday somewhere nice",
+			"transactionDate": "2023-05-01",
+			"amountInCents": 100
+		}`)
+		client.UpdateTransaction(t, http.MethodPatch, "sequentialID-1", `"1"`, `{"amountInCents": 200}`)
+		status, _, body := client.UpdateTransaction(t, http.MethodPatch, "sequentialID-1", `"1"`, `{"amountInCents": 300}`)
+
+		assert.Equal(t, http.StatusPreconditionFailed, status)
+		assert.JSONEq(t, `{"message": "TRANSACTION_VERSION_MISMATCH"}`, body)
+		tearDown()
+	})
+	t.Run("business validation error", func(t *testing.T) {
+		setUp(t)
+		client.StoreTransaction(t, `{
+			"description": "A holiday somewhere nice",
+			"transactionDate": "2023-05-01",
+			"amountInCents": 100
+		}`)
+		status, _, body := client.UpdateTransaction(t, http.MethodPatch, "sequentialID-1", `"1"`, `{"amountInCents": 0}`)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+		assert.JSONEq(t, `{"fields":[{"fieldName": "amountInCents", "reason": "ZERO_VALUE"}], "message": "VALIDATION_ERROR"}`, body)
+		tearDown()
+	})
+}
+
+func TestVoidTransaction(t *testing.T) {
+	t.Run("success - should hide the voided transaction until it is restored", func(t *testing.T) {
+		setUp(t)
+		client.StoreTransaction(t, `{
+			"description": "A holiday somewhere nice",
+			"transactionDate": "2023-05-01",
+			"amountInCents": 100
+		}`)
+		status, etag, body := client.VoidTransaction(t, "sequentialID-1", "Entered in error")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, `"2"`, etag)
+		assert.JSONEq(t, `{"id":"sequentialID-1"}`, body)
+
+		status, body = client.FetchTransaction(t, "sequentialID-1", "United%20Kingdom")
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+		assert.JSONEq(t, `{"message": "TRANSACTION_VOIDED"}`, body)
+
+		status, body = client.FetchTransactionIncludingVoided(t, "sequentialID-1", "United%20Kingdom")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Contains(t, body, `"reason":"Entered in error"`)
+
+		status, etag, _ = client.RestoreTransaction(t, "sequentialID-1")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, `"3"`, etag)
+
+		status, _ = client.FetchTransaction(t, "sequentialID-1", "United%20Kingdom")
+		assert.Equal(t, http.StatusOK, status)
+		tearDown()
+	})
+	t.Run("conflict when the transaction is already voided", func(t *testing.T) {
+		setUp(t)
+		client.StoreTransaction(t, `{
+			"description": "A holiday somewhere nice",
+			"transactionDate": "2023-05-01",
+			"amountInCents": 100
+		}`)
+		client.VoidTransaction(t, "sequentialID-1", "Entered in error")
+		status, _, body := client.VoidTransaction(t, "sequentialID-1", "Entered in error")
+
+		assert.Equal(t, http.StatusConflict, status)
+		assert.JSONEq(t, `{"message": "TRANSACTION_VOIDED"}`, body)
+		tearDown()
 	})
 }